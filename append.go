@@ -0,0 +1,58 @@
+package model_fields_prefixer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Append writes columns of the result built independently (e.g. by Build in another package) after the built columns,
+// so fragments are combined in the order of the calls. Bind parameters of the result follow the prefixer's ones and
+// numbered placeholders of its columns are renumbered, so the result must be built with the same dialect. Only columns,
+// aliases, bind parameters and errors are appended, joins, ORDER BY and WHERE clauses of the result are ignored:
+//
+//	m.Columns(User{}, "u").Append(billing.Columns()).CustomColumns("now() AS fetched_at")
+func (mp *ModelFieldsPrefixer) Append(other Result) *ModelFieldsPrefixer {
+	offset := len(mp.args)
+
+	for _, column := range other.slice {
+		mp.CustomColumns(mp.renumberPlaceholders(column, offset))
+	}
+
+	for _, alias := range other.aliases {
+		mp.addAlias(alias)
+	}
+
+	mp.args = append(mp.args, other.args...)
+
+	if other.err != nil {
+		mp.errs = append(mp.errs, other.err)
+	}
+
+	return mp
+}
+
+// renumberPlaceholders shifts numbered placeholders of the expression by offset, e.g. '$1' becomes '$3' for offset 2.
+// Dialects with unnumbered placeholders ('?') keep the expression as is
+func (mp *ModelFieldsPrefixer) renumberPlaceholders(expr string, offset int) string {
+	first := mp.dialect.Placeholder(1)
+	if offset == 0 || first == mp.dialect.Placeholder(2) {
+		return expr
+	}
+
+	prefix, ok := strings.CutSuffix(first, "1")
+	if !ok || !strings.Contains(expr, prefix) {
+		return expr
+	}
+
+	placeholder := regexp.MustCompile(regexp.QuoteMeta(prefix) + `(\d+)`)
+
+	return placeholder.ReplaceAllStringFunc(expr, func(match string) string {
+		n, err := strconv.Atoi(match[len(prefix):])
+		if err != nil {
+			return match
+		}
+
+		return mp.dialect.Placeholder(n + offset)
+	})
+}
@@ -0,0 +1,48 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+)
+
+// Arg is a value bound to a query as a parameter instead of being written to it, see Bind
+type Arg struct {
+	Value any
+}
+
+// Bind marks the value passed to CustomColumnsf as a bind parameter
+func Bind(value any) Arg {
+	return Arg{Value: value}
+}
+
+// CustomColumnsf works as CustomColumns but formats the column with fmt.Sprintf. Arguments wrapped with Bind are not
+// written to the column, they are replaced with placeholders of the dialect ('$1' in PostgreSQL, '?' in MySQL)
+// and captured, so they can be passed to the query with Args:
+//
+//	m.Columns(User{}, "u").CustomColumnsf("CASE WHEN u.role = %s THEN 1 ELSE 0 END AS is_admin", mfp.Bind(role))
+//
+// Placeholders are numbered from 1 in the order of capture, so numbered placeholders of the query must follow them
+func (mp *ModelFieldsPrefixer) CustomColumnsf(format string, args ...any) *ModelFieldsPrefixer {
+	formatArgs := make([]any, len(args))
+
+	for i, arg := range args {
+		bindArg, ok := arg.(Arg)
+		if !ok {
+			formatArgs[i] = arg
+
+			continue
+		}
+
+		mp.args = append(mp.args, bindArg.Value)
+		formatArgs[i] = mp.dialect.Placeholder(len(mp.args))
+	}
+
+	return mp.CustomColumns(fmt.Sprintf(format, formatArgs...))
+}
+
+// Args returns the bind parameters captured by CustomColumnsf followed by queryArgs, so they can be passed to db.Query
+func (mp *ModelFieldsPrefixer) Args(queryArgs ...any) []any {
+	args := make([]any, 0, len(mp.args)+len(queryArgs))
+	args = append(args, mp.args...)
+
+	return append(args, queryArgs...)
+}
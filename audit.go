@@ -0,0 +1,36 @@
+package model_fields_prefixer
+
+// defaultAuditColumns are the audit columns of WithAuditColumns called without columns
+var defaultAuditColumns = []string{"created_at", "updated_at", "deleted_at"}
+
+// WithAuditColumns handles the columns as if they were marked with 'audit' tag option in every model which declares them:
+// they are selected after the model's other columns and stripped from Values and WritableColumns, because the database
+// sets them. Without columns created_at, updated_at and deleted_at are the audit columns
+func WithAuditColumns(columns ...string) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if len(columns) == 0 {
+			columns = defaultAuditColumns
+		}
+
+		mp.auditColumns = make(map[string]struct{}, len(columns))
+		for _, column := range columns {
+			mp.auditColumns[column] = struct{}{}
+		}
+	}
+}
+
+// isAudit reports whether the field is an audit column, i.e. it is marked with 'audit' tag option or its column is one
+// of the audit columns of the prefixer
+func (mp *ModelFieldsPrefixer) isAudit(field *FieldInfo) bool {
+	if field.IsAudit {
+		return true
+	}
+
+	if field.IsStruct || field.IsJSON || field.Expr != "" {
+		return false
+	}
+
+	_, ok := mp.auditColumns[field.DBTag]
+
+	return ok
+}
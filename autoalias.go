@@ -0,0 +1,156 @@
+package model_fields_prefixer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AliasStrategy derives the alias of a table from its name, e.g. 'um' from 'users_meta'. Tables of models are
+// declared by their TableName methods, otherwise they are snake cased names of the models
+type AliasStrategy func(table string) string
+
+// WithAutoAlias makes Columns generate aliases with the strategy for the root model passed without an alias
+// and for relations which have no aliases of join models and dbalias tags, e.g. WithAutoAlias(AliasInitials)
+// selects 'u.id, um.city AS "meta.city"' for User model with Meta relation of UserMeta model. Generated aliases
+// colliding with other aliases of the call are suffixed with numbers - 'u2', see AutoAliases
+func WithAutoAlias(strategy AliasStrategy) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.aliasStrategy = strategy
+	}
+}
+
+// AliasInitials returns the first letters of the words of the snake cased table, e.g. 'um' of 'users_meta',
+// the schema of qualified tables is skipped
+func AliasInitials(table string) string {
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		table = table[i+1:]
+	}
+
+	var sb strings.Builder
+
+	for _, word := range strings.Split(table, "_") {
+		if r, _ := utf8.DecodeRuneInString(word); word != "" {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return sb.String()
+}
+
+// autoAlias is the alias generated for the relation at the path of fields, e.g. 'Author.Profile',
+// the path of the root model is its name
+type autoAlias struct {
+	path  string
+	alias string
+}
+
+// AutoAliases returns the aliases generated by the strategy of WithAutoAlias for the last Columns call keyed by
+// the paths of the relations' fields, e.g. 'Meta' or 'Author.Profile', and the name of the root model,
+// so the joins of the query are written with them
+func (mp *ModelFieldsPrefixer) AutoAliases() map[string]string {
+	aliases := make(map[string]string, len(mp.autoAliases))
+
+	for _, generated := range mp.autoAliases {
+		aliases[generated.path] = generated.alias
+	}
+
+	return aliases
+}
+
+// defaultAlias returns the alias of the root model passed without an alias: the one generated by the strategy
+// of WithAutoAlias or the table of the model
+func (mp *ModelFieldsPrefixer) defaultAlias(modelName, table string, joinModels []M) string {
+	if mp.aliasStrategy == nil {
+		return table
+	}
+
+	mp.useAliases("", joinModels)
+
+	return mp.generateAlias(modelName, modelName, table)
+}
+
+// relationAlias returns the alias of the relation's table without an alias of a join model: the one generated
+// by the strategy of WithAutoAlias or the default alias of the relation
+func (mp *ModelFieldsPrefixer) relationAlias(path string, field *FieldInfo) string {
+	// the default alias of relations without dbalias tags is their db tag
+	if mp.aliasStrategy == nil || field.ModelInfo.DBAlias != field.DBTag {
+		return field.ModelInfo.DBAlias
+	}
+
+	return mp.generateAlias(path, field.ModelInfo.Name, field.ModelInfo.Table)
+}
+
+func (mp *ModelFieldsPrefixer) generateAlias(path, modelName, table string) string {
+	for _, generated := range mp.autoAliases {
+		if generated.path == path {
+			return generated.alias
+		}
+	}
+
+	if table == "" {
+		table = SnakeCase(modelName)
+	}
+
+	base := mp.aliasStrategy(table)
+	if base == "" {
+		base = table
+	}
+
+	alias := base
+	for i := 2; mp.isAliasUsed(alias); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+
+	mp.setAutoAlias(path, alias)
+
+	return alias
+}
+
+// useAliases reserves the alias of the root model and the aliases of the join models, so generated aliases
+// never collide with them
+func (mp *ModelFieldsPrefixer) useAliases(rootAlias string, joinModels []M) {
+	if mp.aliasStrategy == nil {
+		return
+	}
+
+	if mp.usedAliases == nil {
+		mp.usedAliases = make(map[string]struct{})
+	}
+
+	if rootAlias != "" {
+		mp.usedAliases[rootAlias] = struct{}{}
+	}
+
+	for _, joinModel := range joinModels {
+		if joinModel.A != "" {
+			mp.usedAliases[joinModel.A] = struct{}{}
+		}
+	}
+}
+
+func (mp *ModelFieldsPrefixer) isAliasUsed(alias string) bool {
+	_, ok := mp.usedAliases[alias]
+
+	return ok
+}
+
+// setAutoAlias keeps the alias generated for the path, e.g. restored from the rendered columns cache
+func (mp *ModelFieldsPrefixer) setAutoAlias(path, alias string) {
+	if mp.usedAliases == nil {
+		mp.usedAliases = make(map[string]struct{})
+	}
+
+	mp.usedAliases[alias] = struct{}{}
+
+	for i, generated := range mp.autoAliases {
+		if generated.path == path {
+			mp.autoAliases[i].alias = alias
+
+			return
+		}
+	}
+
+	mp.autoAliases = append(mp.autoAliases, autoAlias{path: path, alias: alias})
+}
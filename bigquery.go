@@ -0,0 +1,61 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// DialectBigQuery quotes identifiers with backticks and tables of {table} placeholders as whole paths -
+// '`project.dataset.users`'. BigQuery doesn't allow dots in column names of results, so use it along with
+// WithDefaultAliasSeparator, e.g. "__". Fields of STRUCT columns are selected by their paths, see 'struct' tag option
+var DialectBigQuery Dialect = bigQueryDialect{newQuoteDialect("bigquery", "`", "`", "?", bigQueryReservedWords).withMaxIdentifierLength(300)}
+
+// TableDialect is implemented by dialects quoting tables of {table} and {table:Name} placeholders,
+// dialects which don't implement it write the tables as they are
+type TableDialect interface {
+	// QuoteTable quotes the table which may be qualified, e.g. 'project.dataset.users'
+	QuoteTable(table string) string
+}
+
+// bigQueryDialect differs from the quote dialects by escaping of backticks and quoting of qualified tables
+type bigQueryDialect struct {
+	quoteDialect
+}
+
+func (d bigQueryDialect) QuoteIdent(ident string) string {
+	// backticks inside the identifier are escaped with backslashes
+	return "`" + strings.ReplaceAll(ident, "`", "\\`") + "`"
+}
+
+func (d bigQueryDialect) QuoteTable(table string) string {
+	// the whole path is quoted at once, since project IDs may contain dashes - '`my-project.analytics.users`'
+	return d.QuoteIdent(table)
+}
+
+// WithTableQualifier qualifies unqualified tables of {table} and {table:Name} placeholders, e.g. with the schema
+// 'analytics' or BigQuery's 'project.dataset', so tables declared by TableName or resolved by the table resolver
+// don't repeat it
+func WithTableQualifier(qualifier string) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.tableQualifier = qualifier
+	}
+}
+
+// renderTable returns the table of the placeholder qualified with WithTableQualifier and quoted by the dialect
+func (mp *ModelFieldsPrefixer) renderTable(table string) string {
+	if mp.tableQualifier != "" && !strings.Contains(table, ".") {
+		table = mp.tableQualifier + "." + table
+	}
+
+	if d, ok := mp.dialect.(TableDialect); ok {
+		return d.QuoteTable(table)
+	}
+
+	return table
+}
+
+const bigQueryReservedWords = `all and any array as asc assert_rows_modified at between by case cast collate contains create
+cross cube current default define desc distinct else end enum escape except exclude exists extract false fetch
+following for from full group grouping groups hash having if ignore in inner intersect interval into is join lateral
+left like limit lookup merge natural new no not null nulls of on or order outer over partition preceding proto qualify
+range recursive respect right rollup rows select set some struct tablesample then to treat true unbounded union unnest
+using when where window with within`
@@ -0,0 +1,113 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Event struct {
+	ID      int64         `db:"id"`
+	Range   string        `db:"range"`
+	Device  *EventDevice  `db:"device,struct"`
+	Session *EventSession `db:"session" dbalias:"s"`
+}
+
+func (Event) TableName() string {
+	return "events"
+}
+
+type EventDevice struct {
+	OS       string          `db:"os"`
+	Location *DeviceLocation `db:"location,struct"`
+}
+
+type DeviceLocation struct {
+	Country string `db:"country"`
+}
+
+type EventSession struct {
+	ID int64 `db:"id"`
+}
+
+func (EventSession) TableName() string {
+	return "reporting.sessions"
+}
+
+func TestDialectBigQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		render func(m *mfp.ModelFieldsPrefixer) string
+		want   string
+		err    error
+	}{
+		{
+			name: "struct columns",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.M{N: "Device"}).String()
+			},
+			want: "e.id, e.`range`, e.device.os AS `device__os`, e.device.location.country AS `device__location__country`",
+		},
+		{
+			name: "qualified tables",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.M{N: "Session", A: "s"}).
+					InQuery("SELECT {columns} FROM {table} e JOIN {table:EventSession} s ON s.id = e.id")
+			},
+			want: "SELECT e.id, e.`range`, e.device.os AS `device__os`, e.device.location.country AS `device__location__country`, s.id AS `session__id` FROM `my-project.analytics.events` e JOIN `reporting.sessions` s ON s.id = e.id",
+		},
+		{
+			name: "quoted backticks",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.WithDepth(0), mfp.WithAliasSeparator("`")).String()
+			},
+			want: "e.id, e.`range`, e.device.os AS `device\\`os`, e.device.location.country AS `device\\`location\\`country`",
+		},
+		{
+			name: "placeholders",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.WithDepth(0)).Where(struct {
+					IDs []int64 `db:"id"`
+				}{[]int64{1, 2}}).InQuery("SELECT {columns} FROM events e {where}")
+			},
+			want: "SELECT e.id, e.`range`, e.device.os AS `device__os`, e.device.location.country AS `device__location__country` FROM events e WHERE e.id IN (?, ?)",
+		},
+		{
+			name: "lateral join",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.M{N: "Session", A: "s", F: "sessions(e.id)"}).Joins()
+			},
+			want: "",
+			err:  prefixererr.ErrUnsupportedJoin,
+		},
+		{
+			name: "locking",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Event{}, "e", mfp.WithDepth(0)).Locking().InQuery("SELECT {columns} FROM events e {locking}")
+			},
+			want: "SELECT e.id, e.`range`, e.device.os AS `device__os`, e.device.location.country AS `device__location__country` FROM events e ",
+			err:  prefixererr.ErrUnsupportedLocking,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(
+				mfp.WithDialect(mfp.DialectBigQuery),
+				mfp.WithTableQualifier("my-project.analytics"),
+				mfp.WithDefaultAliasSeparator("__"),
+				mfp.Strict(),
+			)
+
+			if got := test.render(m); got != test.want {
+				t.Errorf("InQuery() = %q, want %q", got, test.want)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
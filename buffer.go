@@ -0,0 +1,108 @@
+package model_fields_prefixer
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// columnsBuffer is the buffer the columns are written to. In benchmarks of the backends (BenchmarkColumnsBuffer) the
+// preallocated byte slice and bytes.Buffer are on par, the slice allocates slightly less memory for new prefixers
+type columnsBuffer interface {
+	WriteString(s string) (int, error)
+	Len() int
+	Cap() int
+	Grow(n int)
+	Reset()
+	// Bytes returns the written bytes without copying, the slice must not be modified
+	Bytes() []byte
+	String() string
+}
+
+type bufferBackend int
+
+const (
+	sliceBackend bufferBackend = iota
+	bytesBufferBackend
+)
+
+// defaultBufferBackend is the backend of new prefixers, it is a variable so benchmarks can compare the backends
+var defaultBufferBackend = sliceBackend
+
+func newColumnsBuffer(backend bufferBackend, size int) columnsBuffer {
+	var buf columnsBuffer
+
+	switch backend {
+	case bytesBufferBackend:
+		buf = &bytes.Buffer{}
+	default:
+		buf = &sliceBuffer{}
+	}
+
+	buf.Grow(size)
+
+	return buf
+}
+
+// sliceBuffer is a preallocated byte slice, Reset keeps its memory for the next call
+type sliceBuffer struct {
+	buf []byte
+}
+
+func (b *sliceBuffer) WriteString(s string) (int, error) {
+	b.buf = append(b.buf, s...)
+
+	return len(s), nil
+}
+
+func (b *sliceBuffer) Len() int {
+	return len(b.buf)
+}
+
+func (b *sliceBuffer) Cap() int {
+	return cap(b.buf)
+}
+
+func (b *sliceBuffer) Grow(n int) {
+	if n > cap(b.buf)-len(b.buf) {
+		buf := make([]byte, len(b.buf), len(b.buf)+n)
+		copy(buf, b.buf)
+		b.buf = buf
+	}
+}
+
+func (b *sliceBuffer) Reset() {
+	b.buf = b.buf[:0]
+}
+
+func (b *sliceBuffer) Bytes() []byte {
+	return b.buf
+}
+
+func (b *sliceBuffer) String() string {
+	return string(b.buf)
+}
+
+// bufferSizeHint keeps the max length of the rendered columns, so buffers of new prefixers are allocated once with
+// the size the application actually needs
+type bufferSizeHint struct {
+	size atomic.Int64
+}
+
+func (h *bufferSizeHint) observe(n int) {
+	for {
+		size := h.size.Load()
+		if int64(n) <= size || h.size.CompareAndSwap(size, int64(n)) {
+			return
+		}
+	}
+}
+
+// initialBufferSize returns the size of the buffer of a new prefixer: the size set with WithBufferSize or the
+// measured length of the rendered columns if it's larger than the default, it never exceeds the pooled buffer limit
+func (mp *ModelFieldsPrefixer) initialBufferSize() int {
+	if mp.bufferSizeSet || mp.cache == nil {
+		return mp.bufferSize
+	}
+
+	return min(max(mp.bufferSize, int(mp.cache.bufferSize.size.Load())), maxPooledBufferSize)
+}
@@ -0,0 +1,54 @@
+package model_fields_prefixer
+
+import (
+	"testing"
+)
+
+type benchmarkUser struct {
+	ID   int64              `db:"id"`
+	Name string             `db:"name"`
+	Meta *benchmarkUserMeta `db:"meta" dbalias:"um"`
+}
+
+type benchmarkUserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+func BenchmarkColumnsBuffer(b *testing.B) {
+	backends := []struct {
+		name    string
+		backend bufferBackend
+	}{
+		{"slice", sliceBackend},
+		{"bytes.Buffer", bytesBufferBackend},
+	}
+
+	for _, bb := range backends {
+		defaultBufferBackend = bb.backend
+
+		b.Run(bb.name+"/pooled", func(b *testing.B) {
+			mp := New()
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p := mp.Acquire()
+				p.Columns(benchmarkUser{}, "u")
+				p.Release()
+			}
+		})
+
+		b.Run(bb.name+"/new", func(b *testing.B) {
+			mp := New()
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				mp.AllocPrefixer().Columns(benchmarkUser{}, "u")
+			}
+		})
+	}
+
+	defaultBufferBackend = sliceBackend
+}
@@ -1,40 +1,278 @@
 package model_fields_prefixer
 
 import (
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 )
 
 type ModelsInfoCache struct {
-	modelsCache map[string]*ModelInfo
-	mu          *sync.RWMutex
+	// hits and misses are accessed atomically
+	hits   int64
+	misses int64
+
+	// models are keyed by model types, so same-named models from different packages never share metadata
+	models CacheBackend
+	// mu guards the fields below and serializes writes of models, so they are consistent with the rendered cache
+	mu *sync.RWMutex
+
+	// rendered keeps columns built by Columns calls, so repeated calls with the same arguments skip rendering
+	rendered *renderedCache
+
+	// templates are query templates of InQuery split around their placeholders
+	templates *queryTemplates
+
+	// bufferSize is the max length of the rendered columns, buffers of new prefixers are preallocated with it
+	bufferSize bufferSizeHint
+
+	// warnings are reported when models were collected, they are keyed by types of the root models
+	warnings map[reflect.Type][]Warning
+
+	// renderedLens are the max byte lengths of the columns rendered for the root models, so the buffer is grown once
+	// before rendering them
+	renderedLens map[*ModelInfo]int
+
+	// excluded are full names of structs which are never scanned as nested models, e.g. the ones without db tags
+	excluded   map[string]struct{}
+	excludedMu sync.RWMutex
+}
+
+// CacheStats describes the state of the models cache
+type CacheStats struct {
+	// Models is the number of cached root models
+	Models int
+	// Fields is the number of cached fields including the fields of nested models
+	Fields int
+	// Hits and Misses are numbers of lookups of models which were and weren't found in the cache
+	Hits   int64
+	Misses int64
 }
 
+// ModelInfo is metadata of a model. Cached model infos are shared between prefixers and must not be modified,
+// aliases of a particular query are passed to Columns as join models instead
 type ModelInfo struct {
 	Name string
-	// DBAlias is an alias for a table which this field (column) belongs to. Used as prefix in queries
+	// DBAlias is the default alias for a table which this field (column) belongs to. Used as prefix in queries
 	DBAlias string
 	// ModelsPrefix is concatenated string of all parent db tags, e.g. 'users.users_meta.'
 	ModelsPrefix string
-	Fields       []*FieldInfo
+	// Table is the table returned by TableName method of the model (GORM convention), empty if it has no such method
+	Table  string
+	Fields []*FieldInfo
 }
 
 type FieldInfo struct {
+	// Name is the name of the field in the struct
+	Name string
 	// DBTag is actual db column name if this field is not struct, if it is a struct then DBTag can be any string name
-	DBTag     string
-	IsStruct  bool
-	ModelInfo *ModelInfo
+	DBTag string
+	// Index is the position of the field in the parent struct, used to access the field's value
+	Index int
+	// Type is the Go type of the field as it is declared in the struct
+	Type reflect.Type
+	// IsPK is true if the field is marked as primary key with 'pk' tag option, e.g. `db:"id,pk"`
+	IsPK bool
+	// Codec is the name of the codec converting the field's values, set with 'codec' tag option, e.g. `db:"prefs,codec=json"`
+	Codec string
+	// Expr is the SQL expression the column is computed with, set with 'expr' tag option which must be the last one,
+	// e.g. `db:"full_name,expr=CONCAT({alias}.first_name, ' ', {alias}.last_name)"`. {alias} is replaced with the table's alias
+	Expr string
+	// IsReadOnly is true for columns which are only selected and never written, e.g. `db:"created_at,readonly"`
+	IsReadOnly bool
+	// IsWriteOnly is true for columns which are only written and never selected, e.g. `db:"password,writeonly"`
+	IsWriteOnly bool
+	// ScanAlias is the name of the column in the result set set with 'as' tag option, e.g. `db:"id,as=author_id"`.
+	// It overrides the default scan alias of nested models' columns ('author.id') to avoid collisions
+	ScanAlias string
+	// IsSoftDelete is true for the column marking deleted rows which must be NULL for rows which are not deleted,
+	// e.g. `db:"deleted_at,softdelete"`
+	IsSoftDelete bool
+	// Order is the position of the column among the model's columns set by 'order' tag option, e.g. `db:"id,order=1"`,
+	// 0 if the column is written in the default order
+	Order int
+	// Groups are the groups of 'groups' tag option, e.g. `db:"email,groups=admin,internal"`. The field is selected only
+	// if one of them is passed to WithGroups, fields without groups are always selected
+	Groups []string
+	// Locales are the locales of 'i18n' tag option, e.g. `db:"name,i18n=en|ru"` is stored in 'name_en' and 'name_ru' columns
+	Locales []string
+	// IsMasked is true for sensitive columns selected as masking expressions, e.g. `db:"ssn,masked"`
+	IsMasked bool
+	// IsEncrypted is true for encrypted columns selected as decrypting expressions of WithEncryption, e.g. `db:"ssn,encrypted"`
+	IsEncrypted bool
+	// IsAudit is true for columns set by the database, e.g. `db:"updated_at,audit"`. They are selected after
+	// the model's other columns and Values never writes them unless they are requested explicitly
+	IsAudit bool
+	// IsJSON is true for the column holding JSON object marked with 'json' tag option, e.g. `db:"meta,json"`, and for
+	// keys of its payload. ModelInfo of JSON column describes its payload, its keys are selected instead of the column
+	IsJSON bool
+	// ForeignKey is the column of the relation's table referencing the model set with 'fk' tag option of the relation,
+	// e.g. `db:"comments,fk=post_id"`
+	ForeignKey string
+	// Count is the relation counted by the column marked with 'count' tag option, e.g. `db:"comments_count,count=Comments"`,
+	// the column is selected as a correlated subquery and is never written
+	Count *RelationCount
+	// IsStructColumn is true for relations stored in STRUCT (record) columns of the model's table marked with 'struct'
+	// tag option, e.g. `db:"address,struct"` in BigQuery. Their fields are selected by paths - 'u.address.city'
+	IsStructColumn bool
+	IsStruct       bool
+	ModelInfo      *ModelInfo
+}
+
+func (c *ModelsInfoCache) getModelCacheValue(t reflect.Type) *ModelInfo {
+	modelInfo, _ := c.models.Load(t)
+
+	return modelInfo
 }
 
-func (c *ModelsInfoCache) getModelCacheValue(modelName string) *ModelInfo {
+func (c *ModelsInfoCache) setModelCacheValue(t reflect.Type, modelInfo *ModelInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.models.Load(t); ok {
+		delete(c.renderedLens, old)
+	}
+
+	c.models.Store(t, modelInfo)
+
+	c.rendered.deleteModel(t)
+}
+
+func (c *ModelsInfoCache) getModelWarnings(t reflect.Type) []Warning {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.modelsCache[modelName]
+	return c.warnings[t]
+}
+
+func (c *ModelsInfoCache) setModelWarnings(t reflect.Type, warnings []Warning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.warnings == nil {
+		c.warnings = make(map[reflect.Type][]Warning)
+	}
+
+	c.warnings[t] = warnings
 }
 
-func (c *ModelsInfoCache) setModelCacheValue(modelName string, modelInfo *ModelInfo) {
+// getRenderedLen returns the max byte length of the columns rendered for the model, 0 if it wasn't rendered yet
+func (c *ModelsInfoCache) getRenderedLen(modelInfo *ModelInfo) int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	c.modelsCache[modelName] = modelInfo
+	return c.renderedLens[modelInfo]
+}
+
+func (c *ModelsInfoCache) setRenderedLen(modelInfo *ModelInfo, n int) {
+	if n <= c.getRenderedLen(modelInfo) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.renderedLens == nil {
+		c.renderedLens = make(map[*ModelInfo]int)
+	}
+
+	c.renderedLens[modelInfo] = max(c.renderedLens[modelInfo], n)
+}
+
+// deleteModelCacheValue removes models with the name, which is either the full name ('github.com/org/models.User')
+// or just the name of the type ('User') which removes same-named models of all packages
+func (c *ModelsInfoCache) deleteModelCacheValue(modelName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.models.Range(func(t reflect.Type, modelInfo *ModelInfo) bool {
+		if t.Name() == modelName || fullTypeName(t) == modelName {
+			c.models.Delete(t)
+			delete(c.renderedLens, modelInfo)
+
+			c.rendered.deleteModel(t)
+		}
+
+		return true
+	})
+}
+
+func (c *ModelsInfoCache) isExcluded(typeName string) bool {
+	c.excludedMu.RLock()
+	defer c.excludedMu.RUnlock()
+
+	_, ok := c.excluded[typeName]
+
+	return ok
+}
+
+func (c *ModelsInfoCache) exclude(typeName string) {
+	c.excludedMu.Lock()
+	defer c.excludedMu.Unlock()
+
+	c.excluded[typeName] = struct{}{}
+}
+
+// hasModelName returns true if a model with the name, either full or just the name of the type, is cached
+func (c *ModelsInfoCache) hasModelName(modelName string) bool {
+	found := false
+
+	c.models.Range(func(t reflect.Type, _ *ModelInfo) bool {
+		found = t.Name() == modelName || fullTypeName(t) == modelName
+
+		return !found
+	})
+
+	return found
+}
+
+func (c *ModelsInfoCache) modelNames() []string {
+	var names []string
+
+	c.models.Range(func(t reflect.Type, _ *ModelInfo) bool {
+		names = append(names, fullTypeName(t))
+
+		return true
+	})
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (c *ModelsInfoCache) stats() CacheStats {
+	stats := CacheStats{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+
+	c.models.Range(func(_ reflect.Type, modelInfo *ModelInfo) bool {
+		stats.Models++
+		stats.Fields += countFields(modelInfo)
+
+		return true
+	})
+
+	return stats
+}
+
+func countFields(modelInfo *ModelInfo) int {
+	count := len(modelInfo.Fields)
+
+	for _, field := range modelInfo.Fields {
+		if field.ModelInfo != nil {
+			count += countFields(field.ModelInfo)
+		}
+	}
+
+	return count
+}
+
+// fullTypeName returns the name of the type with its package path, e.g. 'github.com/org/models.User'
+func fullTypeName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+
+	return t.PkgPath() + "." + t.Name()
 }
@@ -0,0 +1,61 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+	"sync"
+)
+
+// CacheBackend stores infos of the models keyed by their types. It must be safe for concurrent use: it is shared by
+// all prefixers allocated from the same instance. The default backend is backed by sync.Map, other ones (e.g. static
+// data generated by prefixer-gen or fakes in tests) are plugged in with WithCacheBackend
+type CacheBackend interface {
+	Load(t reflect.Type) (*ModelInfo, bool)
+	Store(t reflect.Type, modelInfo *ModelInfo)
+	Delete(t reflect.Type)
+	// Range calls f for each stored model until f returns false
+	Range(f func(t reflect.Type, modelInfo *ModelInfo) bool)
+}
+
+// syncMapBackend is the default CacheBackend, sync.Map suits the cache which is written once per model and read
+// on every call
+type syncMapBackend struct {
+	models sync.Map
+}
+
+// NewSyncMapCacheBackend returns the default CacheBackend
+func NewSyncMapCacheBackend() CacheBackend {
+	return &syncMapBackend{}
+}
+
+func (b *syncMapBackend) Load(t reflect.Type) (*ModelInfo, bool) {
+	value, ok := b.models.Load(t)
+	if !ok {
+		return nil, false
+	}
+
+	return value.(*ModelInfo), true
+}
+
+func (b *syncMapBackend) Store(t reflect.Type, modelInfo *ModelInfo) {
+	b.models.Store(t, modelInfo)
+}
+
+func (b *syncMapBackend) Delete(t reflect.Type) {
+	b.models.Delete(t)
+}
+
+func (b *syncMapBackend) Range(f func(t reflect.Type, modelInfo *ModelInfo) bool) {
+	b.models.Range(func(key, value any) bool {
+		return f(key.(reflect.Type), value.(*ModelInfo))
+	})
+}
+
+// WithCacheBackend sets the backend storing infos of the models, nil keeps the default one. Models registered
+// in the backend before are never scanned
+func WithCacheBackend(backend CacheBackend) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if backend != nil {
+			mp.cache.models = backend
+		}
+	}
+}
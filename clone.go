@@ -0,0 +1,74 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// configIDs numbers configurations of the prefixers, columns rendered with different configurations sharing
+// the cache (see Clone) are cached apart
+var configIDs atomic.Uint64
+
+// Clone returns a copy of the prefixer with the options applied, e.g. another dialect. The copy shares the models cache
+// and the registered queries with mp, so one service can render queries of several databases (e.g. Postgres and
+// ClickHouse) with shared model metadata. Models are scanned with the tag name, the naming strategy and the cache
+// backend, so the copy gets its own models cache if the options change them or the size of the rendered cache, the
+// options never change the cache of mp. Columns rendered by the copy are cached apart from mp's ones, and prefixers
+// acquired from the copy are pooled apart as well:
+//
+//	clickhouse := mp.Clone(mfp.WithDialect(clickHouseDialect), mfp.WithDefaultAliasSeparator("__"))
+func (mp *ModelFieldsPrefixer) Clone(opts ...Option) *ModelFieldsPrefixer {
+	clone := mp.AllocPrefixer()
+
+	clone.codecs = mp.codecs.clone()
+
+	clone.pool = &sync.Pool{}
+	clone.configID = configIDs.Add(1)
+
+	// options are applied to the copy's own cache, so they never change the cache shared with mp
+	renderedCacheSize := mp.cache.rendered.stats().MaxEntries
+	clone.cache = newModelsInfoCache(renderedCacheSize)
+
+	models := clone.cache.models
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	ownCache := clone.cache.models != models || clone.cache.rendered.stats().MaxEntries != renderedCacheSize
+	if !ownCache && clone.tagName == mp.tagName && sameNamingStrategy(clone.namingStrategy, mp.namingStrategy) {
+		clone.cache = mp.cache
+	}
+
+	clone.bytesBuffer = newColumnsBuffer(defaultBufferBackend, clone.initialBufferSize())
+
+	return clone
+}
+
+// WithDefaultAliasSeparator sets the separator of scan aliases of nested models' columns for all Columns calls,
+// WithAliasSeparator overrides it for a call
+func WithDefaultAliasSeparator(separator string) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.defaultAliasSeparator = separator
+		mp.aliasSeparator = separator
+	}
+}
+
+func sameNamingStrategy(a, b NamingStrategy) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+func newModelsInfoCache(renderedCacheSize int) *ModelsInfoCache {
+	return &ModelsInfoCache{
+		models:    NewSyncMapCacheBackend(),
+		mu:        &sync.RWMutex{},
+		rendered:  newRenderedCache(renderedCacheSize),
+		templates: newQueryTemplates(),
+		excluded:  make(map[string]struct{}),
+	}
+}
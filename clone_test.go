@@ -0,0 +1,80 @@
+package model_fields_prefixer_test
+
+import (
+	"sync"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []mfp.Option
+		wantShared bool
+	}{
+		{
+			name:       "dialect",
+			opts:       []mfp.Option{mfp.WithDialect(mfp.DialectMySQL)},
+			wantShared: true,
+		},
+		{
+			name: "rendered cache size",
+			opts: []mfp.Option{mfp.WithRenderedCacheSize(10)},
+		},
+		{
+			name: "cache backend",
+			opts: []mfp.Option{mfp.WithCacheBackend(mfp.NewSyncMapCacheBackend())},
+		},
+		{
+			name: "tag name",
+			opts: []mfp.Option{mfp.WithTagName("sql")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := mfp.New()
+			mp.Columns(User{}, "u")
+
+			parentStats := mp.RenderedCacheStats()
+
+			clone := mp.Clone(tt.opts...)
+
+			if got := mp.RenderedCacheStats().MaxEntries; got != parentStats.MaxEntries {
+				t.Errorf("parent's rendered cache size = %d, want %d", got, parentStats.MaxEntries)
+			}
+
+			if shared := clone.CacheStats().Models == mp.CacheStats().Models; shared != tt.wantShared {
+				t.Errorf("models cache is shared = %t, want %t", shared, tt.wantShared)
+			}
+		})
+	}
+}
+
+func TestCloneConcurrent(t *testing.T) {
+	mp := mfp.New()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			mp.Clone(mfp.WithCacheBackend(mfp.NewSyncMapCacheBackend()))
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			p := mp.Acquire()
+			defer p.Release()
+
+			p.Columns(User{}, "u").InQuery("SELECT {columns} FROM users u")
+		}()
+	}
+
+	wg.Wait()
+}
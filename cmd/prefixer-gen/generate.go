@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+const prefixerImportPath = "github.com/ivnku/model-fields-prefixer"
+
+func generate(pkg *modelPackage, constsOnly bool) ([]byte, error) {
+	imports := make(map[string]string)
+	body := &bytes.Buffer{}
+
+	if !constsOnly && len(pkg.models) > 0 {
+		for _, model := range pkg.models {
+			modelInfo, _ := pkg.modelInfo(model, "", "", imports, make(map[string]bool))
+
+			fmt.Fprintf(body, "// %s is prebuilt model info of %s\n", modelInfoVar(model), model)
+			fmt.Fprintf(body, "var %s = ", modelInfoVar(model))
+			writeModelInfo(body, modelInfo)
+			body.WriteString("\n\n")
+		}
+
+		body.WriteString("// RegisterPrefixerModels puts prebuilt model infos of the package to the prefixer's cache\n")
+		body.WriteString("func RegisterPrefixerModels(mp *mfp.ModelFieldsPrefixer) {\n")
+
+		for _, model := range pkg.models {
+			fmt.Fprintf(body, "mp.SetModelInfo(%s{}, %s)\n", model, modelInfoVar(model))
+		}
+
+		body.WriteString("}\n\n")
+
+		body.WriteString("// PrefixerModelInfos returns prebuilt model infos of the package keyed by the models' types\n")
+		body.WriteString("func PrefixerModelInfos() map[reflect.Type]*mfp.ModelInfo {\n")
+		body.WriteString("return map[reflect.Type]*mfp.ModelInfo{\n")
+
+		for _, model := range pkg.models {
+			fmt.Fprintf(body, "reflect.TypeOf(%s{}): %s,\n", model, modelInfoVar(model))
+		}
+
+		body.WriteString("}\n}\n\n")
+
+		body.WriteString("// PrefixerCacheBackend returns the cache backend serving prebuilt model infos of the package, pass it to\n")
+		body.WriteString("// mfp.WithCacheBackend, e.g. to run binaries built with prefixer_static tag which never scan models\n")
+		body.WriteString("func PrefixerCacheBackend() mfp.CacheBackend {\n")
+		body.WriteString("return mfp.NewStaticCacheBackend(PrefixerModelInfos())\n")
+		body.WriteString("}\n")
+
+		imports["reflect"] = "reflect"
+		imports["mfp"] = prefixerImportPath
+	}
+
+	if len(pkg.columns) > 0 {
+		body.WriteString("\nconst (\n")
+
+		for _, decl := range pkg.columns {
+			columns, err := pkg.renderColumns(decl)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render columns list %s: %w", decl.name, err)
+			}
+
+			fmt.Fprintf(body, "// %s is the columns list of %s with alias %s%s\n", decl.name, decl.model, decl.alias, joinModelsComment(decl.joinModels))
+			fmt.Fprintf(body, "%s = %s\n", decl.name, strconv.Quote(columns))
+		}
+
+		body.WriteString(")\n")
+	}
+
+	src := &bytes.Buffer{}
+	src.WriteString("// Code generated by prefixer-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(src, "package %s\n\n", pkg.name)
+	writeImports(src, imports)
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// generateTest generates the tests verifying that the generated model infos and columns lists are equal to the ones
+// built with reflection at runtime, so the tests fail if a struct was changed but the file wasn't regenerated
+func generateTest(pkg *modelPackage, constsOnly bool) ([]byte, error) {
+	imports := map[string]string{"testing": "testing", "mfp": prefixerImportPath}
+	body := &bytes.Buffer{}
+
+	if !constsOnly && len(pkg.models) > 0 {
+		options := append([]string{"mfp.WithCacheBackend(backend)"}, pkg.options()...)
+
+		body.WriteString("func TestPrefixerModelInfos(t *testing.T) {\n")
+		body.WriteString("backend := mfp.NewSyncMapCacheBackend()\n")
+		fmt.Fprintf(body, "mp := mfp.New(%s)\n\n", strings.Join(options, ", "))
+		body.WriteString("for typ, expected := range PrefixerModelInfos() {\n")
+		body.WriteString("if err := mp.Register(reflect.Zero(typ).Interface()); err != nil {\n")
+		body.WriteString("t.Fatalf(\"failed to scan model (%s): %v\", typ, err)\n")
+		body.WriteString("}\n\n")
+		body.WriteString("if actual, _ := backend.Load(typ); !reflect.DeepEqual(actual, expected) {\n")
+		body.WriteString("t.Errorf(\"model info of %s is out of date, run go generate\", typ)\n")
+		body.WriteString("}\n}\n}\n\n")
+
+		imports["reflect"] = "reflect"
+	}
+
+	if len(pkg.columns) > 0 {
+		body.WriteString("func TestPrefixerColumns(t *testing.T) {\n")
+		body.WriteString("tests := []struct {\nname string\nexpected string\nactual func() string\n}{\n")
+
+		for _, decl := range pkg.columns {
+			fmt.Fprintf(body, "{%q, %s, func() string {\n", decl.name, decl.name)
+			fmt.Fprintf(body, "return mfp.New(%s).Columns(%s{}, %q", strings.Join(pkg.options(), ", "), decl.model, decl.alias)
+
+			for _, joinModel := range decl.joinModels {
+				if joinModel.A == "" {
+					fmt.Fprintf(body, ", mfp.M{N: %q}", joinModel.N)
+				} else {
+					fmt.Fprintf(body, ", mfp.M{N: %q, A: %q}", joinModel.N, joinModel.A)
+				}
+			}
+
+			body.WriteString(").String()\n}},\n")
+		}
+
+		body.WriteString("}\n\n")
+		body.WriteString("for _, tt := range tests {\n")
+		body.WriteString("if actual := tt.actual(); actual != tt.expected {\n")
+		body.WriteString("t.Errorf(\"%s is out of date, run go generate\\nexpected: %s\\nactual:   %s\", tt.name, tt.expected, actual)\n")
+		body.WriteString("}\n}\n}\n")
+	}
+
+	src := &bytes.Buffer{}
+	src.WriteString("// Code generated by prefixer-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(src, "package %s\n\n", pkg.name)
+
+	writeImports(src, imports)
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+func writeImports(src *bytes.Buffer, imports map[string]string) {
+	if len(imports) == 0 {
+		return
+	}
+
+	// standard library imports go first, then the others
+	var std, other []string
+
+	for _, name := range sortedKeys(imports) {
+		path := imports[name]
+
+		spec := strconv.Quote(path)
+		if path[strings.LastIndex(path, "/")+1:] != name {
+			spec = name + " " + spec
+		}
+
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+
+	src.WriteString("import (\n")
+	src.WriteString(strings.Join(std, "\n"))
+	src.WriteString("\n\n")
+	src.WriteString(strings.Join(other, "\n"))
+	src.WriteString("\n)\n\n")
+}
+
+// renderColumns renders the columns list with the prefixer itself, so generated lists never differ from runtime ones.
+// Join models which don't match any relation are reported as errors
+func (pkg *modelPackage) renderColumns(decl columnsDecl) (string, error) {
+	modelInfo, _ := pkg.modelInfo(decl.model, "", "", make(map[string]string), make(map[string]bool))
+
+	joinModels := make([]any, 0, len(decl.joinModels))
+	for _, joinModel := range decl.joinModels {
+		joinModels = append(joinModels, joinModel)
+	}
+
+	mp := mfp.New(mfp.Strict()).ColumnsOf(modelInfo.toModelInfo(), decl.alias, joinModels...)
+
+	return mp.String(), mp.Err()
+}
+
+func writeModelInfo(buf *bytes.Buffer, modelInfo *genModelInfo) {
+	buf.WriteString("&mfp.ModelInfo{\n")
+	fmt.Fprintf(buf, "Name: %q,\n", modelInfo.Name)
+
+	if modelInfo.DBAlias != "" {
+		fmt.Fprintf(buf, "DBAlias: %q,\n", modelInfo.DBAlias)
+	}
+
+	if modelInfo.ModelsPrefix != "" {
+		fmt.Fprintf(buf, "ModelsPrefix: %q,\n", modelInfo.ModelsPrefix)
+	}
+
+	if modelInfo.Table != "" {
+		fmt.Fprintf(buf, "Table: %q,\n", modelInfo.Table)
+	}
+
+	buf.WriteString("Fields: []*mfp.FieldInfo{\n")
+
+	for _, field := range modelInfo.fields {
+		buf.WriteString("{\n")
+		fmt.Fprintf(buf, "Name: %q,\n", field.Name)
+		fmt.Fprintf(buf, "DBTag: %q,\n", field.DBTag)
+		fmt.Fprintf(buf, "Index: %d,\n", field.Index)
+		fmt.Fprintf(buf, "Type: reflect.TypeOf((*%s)(nil)).Elem(),\n", field.typeExpr)
+
+		if field.IsPK {
+			buf.WriteString("IsPK: true,\n")
+		}
+
+		if field.Codec != "" {
+			fmt.Fprintf(buf, "Codec: %q,\n", field.Codec)
+		}
+
+		if field.Expr != "" {
+			fmt.Fprintf(buf, "Expr: %q,\n", field.Expr)
+		}
+
+		if field.IsReadOnly {
+			buf.WriteString("IsReadOnly: true,\n")
+		}
+
+		if field.IsWriteOnly {
+			buf.WriteString("IsWriteOnly: true,\n")
+		}
+
+		if len(field.Groups) > 0 {
+			fmt.Fprintf(buf, "Groups: %#v,\n", field.Groups)
+		}
+
+		if len(field.Locales) > 0 {
+			fmt.Fprintf(buf, "Locales: %#v,\n", field.Locales)
+		}
+
+		if field.Order != 0 {
+			fmt.Fprintf(buf, "Order: %d,\n", field.Order)
+		}
+
+		if field.IsMasked {
+			buf.WriteString("IsMasked: true,\n")
+		}
+
+		if field.IsEncrypted {
+			buf.WriteString("IsEncrypted: true,\n")
+		}
+
+		if field.IsAudit {
+			buf.WriteString("IsAudit: true,\n")
+		}
+
+		if field.ScanAlias != "" {
+			fmt.Fprintf(buf, "ScanAlias: %q,\n", field.ScanAlias)
+		}
+
+		if field.IsSoftDelete {
+			buf.WriteString("IsSoftDelete: true,\n")
+		}
+
+		if field.IsJSON {
+			buf.WriteString("IsJSON: true,\n")
+		}
+
+		if field.ForeignKey != "" {
+			fmt.Fprintf(buf, "ForeignKey: %q,\n", field.ForeignKey)
+		}
+
+		if field.Count != nil {
+			fmt.Fprintf(buf, "Count: &mfp.RelationCount{Relation: %q, Table: %q, ForeignKey: %q, Key: %q},\n",
+				field.Count.Relation, field.Count.Table, field.Count.ForeignKey, field.Count.Key)
+		}
+
+		if field.IsStructColumn {
+			buf.WriteString("IsStructColumn: true,\n")
+		}
+
+		if field.IsStruct {
+			buf.WriteString("IsStruct: true,\n")
+		}
+
+		if field.modelInfo != nil {
+			buf.WriteString("ModelInfo: ")
+			writeModelInfo(buf, field.modelInfo)
+			buf.WriteString(",\n")
+		}
+
+		buf.WriteString("},\n")
+	}
+
+	buf.WriteString("},\n}")
+}
+
+func modelInfoVar(model string) string {
+	return "prefixer" + model + "ModelInfo"
+}
+
+func joinModelsComment(joinModels []mfp.M) string {
+	if len(joinModels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(joinModels))
+	for _, joinModel := range joinModels {
+		name := joinModel.N
+		if joinModel.A != "" {
+			name += " (" + joinModel.A + ")"
+		}
+
+		names = append(names, name)
+	}
+
+	return " joining " + strings.Join(names, ", ")
+}
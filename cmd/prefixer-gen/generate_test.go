@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerateTest runs the generated tests of the models of testModels, the package is created in the module,
+// so it imports the prefixer of the tree
+func TestGenerateTest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	tests := []struct {
+		name    string
+		tagName string
+		naming  string
+		// change is applied to the models after generation, the generated tests must fail if it's not empty
+		change [2]string
+	}{
+		{"db tag", "db", "", [2]string{}},
+		{"custom tag with naming", "col", "snake", [2]string{}},
+		{"changed model info", "db", "", [2]string{`db:"id" col:"post_id"`, `db:"id,readonly" col:"post_id"`}},
+		{"changed columns", "db", "", [2]string{`db:"full_name"`, `db:"name"`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// directories starting with '_' are ignored by ./... patterns of go commands
+			dir, err := os.MkdirTemp(".", "_models")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() { os.RemoveAll(dir) })
+
+			models := filepath.Join(dir, "models.go")
+			if err = os.WriteFile(models, []byte(testModels), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = run(dir, "prefixer_gen.go", test.tagName, test.naming, false, true); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.change[0] != "" {
+				changed := strings.Replace(testModels, test.change[0], test.change[1], 1)
+				if err = os.WriteFile(models, []byte(changed), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			output, err := exec.Command("go", "test", "./"+dir).CombinedOutput()
+			if failed := err != nil; failed != (test.change[0] != "") {
+				t.Errorf("go test failed = %v:\n%s", failed, output)
+			}
+
+			if test.change[0] != "" && !strings.Contains(string(output), "out of date") {
+				t.Errorf("go test didn't report drift:\n%s", output)
+			}
+		})
+	}
+}
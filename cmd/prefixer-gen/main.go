@@ -0,0 +1,89 @@
+// Command prefixer-gen generates prebuilt model infos and column lists for structs of a package, so hot paths
+// can skip reflection entirely. Structs are annotated with comments:
+//
+//	//prefixer:model
+//	//prefixer:columns UserColumns u
+//	//prefixer:columns UserWithMetaColumns u UserMeta=um
+//	type User struct {...}
+//
+// '//prefixer:model' generates model info of the struct, '//prefixer:columns NAME ALIAS [Model=alias ...]'
+// generates model info as well and a NAME constant with the columns list of the struct with ALIAS and the given join models.
+// '//prefixer:consts ALIAS' generates ModelColumns constant with all the relations and ModelWithFieldColumns constants
+// with every direct relation of the model. Run it with go:generate in the package of the models:
+//
+//	//go:generate go run github.com/ivnku/model-fields-prefixer/cmd/prefixer-gen
+//
+// Along with the generated file a test is generated, it fails if a struct was changed but the model infos or the constants
+// weren't regenerated.
+// Use -consts to generate only the constants without model infos. Models are scanned with the tag name of -tag flag
+// and the naming strategy of -naming flag, so they must match the options of the prefixers using them.
+// PrefixerCacheBackend of the generated file serves the model infos as a static cache backend, e.g. for binaries
+// built with prefixer_static tag which never scan models with reflection.
+//
+// Only structs declared in the package are treated as nested models, structs from other packages are plain columns
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package with models")
+	output := flag.String("output", "prefixer_gen.go", "name of the generated file in the package directory")
+	constsOnly := flag.Bool("consts", false, "generate only columns lists constants without model infos")
+	withTest := flag.Bool("test", true, "generate the test verifying that model infos and columns lists constants are up to date")
+	tagName := flag.String("tag", "db", "name of the tag declaring columns, see mfp.WithTagName")
+	naming := flag.String("naming", "", "naming strategy of fields without column names in their tags: snake, or empty to skip them, see mfp.WithNamingStrategy")
+	flag.Parse()
+
+	if err := run(*dir, *output, *tagName, *naming, *constsOnly, *withTest); err != nil {
+		fmt.Fprintf(os.Stderr, "prefixer-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// namingStrategies are the naming strategies of -naming flag
+var namingStrategies = map[string]scanConfig{
+	"":      {},
+	"snake": {naming: mfp.SnakeCase, namingSource: "mfp.SnakeCase"},
+}
+
+func run(dir, output, tagName, naming string, constsOnly, withTest bool) error {
+	config, ok := namingStrategies[naming]
+	if !ok {
+		return fmt.Errorf("unknown naming strategy %s", naming)
+	}
+
+	config.tagName = tagName
+
+	pkg, err := parsePackage(dir, output, config)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, constsOnly)
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, output), src, 0o644); err != nil {
+		return err
+	}
+
+	if !withTest || len(pkg.columns) == 0 && (constsOnly || len(pkg.models) == 0) {
+		return nil
+	}
+
+	src, err = generateTest(pkg, constsOnly)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, strings.TrimSuffix(output, ".go")+"_test.go"), src, 0o644)
+}
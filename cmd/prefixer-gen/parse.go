@@ -0,0 +1,619 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+const (
+	modelAnnotation   = "//prefixer:model"
+	columnsAnnotation = "//prefixer:columns"
+	constsAnnotation  = "//prefixer:consts"
+)
+
+// scanConfig is the tag name and the naming strategy the models are scanned with (see mfp.WithTagName and
+// mfp.WithNamingStrategy), they must match the options of the prefixers using the generated code
+type scanConfig struct {
+	tagName string
+	naming  mfp.NamingStrategy
+	// namingSource is the naming strategy in the generated code, e.g. 'mfp.SnakeCase'
+	namingSource string
+}
+
+// options returns the options of the prefixer scanning the models like the generator in the generated code
+func (c scanConfig) options() []string {
+	var options []string
+
+	if c.tagName != "db" {
+		options = append(options, fmt.Sprintf("mfp.WithTagName(%q)", c.tagName))
+	}
+
+	if c.namingSource != "" {
+		options = append(options, "mfp.WithNamingStrategy("+c.namingSource+")")
+	}
+
+	return options
+}
+
+type modelPackage struct {
+	scanConfig
+	name    string
+	fset    *token.FileSet
+	structs map[string]*structDecl
+	// underlying are names of underlying types of the package's named types which are not structs, e.g. 'string' of 'type Status string'
+	underlying map[string]string
+	// tables are tables returned by TableName methods of the package's structs, e.g. 'users' of
+	// 'func (User) TableName() string { return "users" }'
+	tables map[string]string
+	// models are names of the annotated structs in order of declaration
+	models  []string
+	columns []columnsDecl
+	// consts are models and aliases of '//prefixer:consts ALIAS' annotations
+	consts []columnsDecl
+}
+
+type structDecl struct {
+	name string
+	typ  *ast.StructType
+	// imports maps names of the file's imports to their paths
+	imports map[string]string
+}
+
+// columnsDecl is '//prefixer:columns NAME ALIAS [Model=alias ...]' annotation
+type columnsDecl struct {
+	name       string
+	model      string
+	alias      string
+	joinModels []mfp.M
+}
+
+func parsePackage(dir, output string, config scanConfig) (*modelPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &modelPackage{
+		scanConfig: config,
+		fset:       token.NewFileSet(),
+		structs:    make(map[string]*structDecl),
+		underlying: make(map[string]string),
+		tables:     make(map[string]string),
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(pkg.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg.name = file.Name.Name
+
+		if err = pkg.addFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// relations of the models are known only when all the files are parsed
+	pkg.expandConsts()
+
+	names := make(map[string]struct{}, len(pkg.columns))
+
+	for _, decl := range pkg.columns {
+		if _, ok := names[decl.name]; ok {
+			return nil, fmt.Errorf("columns list %s is declared more than once", decl.name)
+		}
+
+		names[decl.name] = struct{}{}
+	}
+
+	if len(pkg.models) == 0 && len(pkg.columns) == 0 {
+		return nil, fmt.Errorf("no structs annotated with %s, %s or %s in %s", modelAnnotation, columnsAnnotation, constsAnnotation, dir)
+	}
+
+	return pkg, nil
+}
+
+func (pkg *modelPackage) addFile(file *ast.File) error {
+	imports := make(map[string]string)
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imports[name] = path
+	}
+
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			pkg.addTableName(funcDecl)
+
+			continue
+		}
+
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				if ident, ok := typeSpec.Type.(*ast.Ident); ok {
+					pkg.underlying[typeSpec.Name.Name] = ident.Name
+				}
+
+				continue
+			}
+
+			name := typeSpec.Name.Name
+
+			pkg.structs[name] = &structDecl{
+				name:    name,
+				typ:     structType,
+				imports: imports,
+			}
+
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+
+			if err := pkg.addAnnotations(name, doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addTableName keeps the table of 'TableName() string' method returning a string literal, tables computed
+// at runtime can't be generated and are taken by ModelFieldsPrefixer from the models themselves
+func (pkg *modelPackage) addTableName(decl *ast.FuncDecl) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil || len(decl.Body.List) != 1 {
+		return
+	}
+
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return
+	}
+
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+
+	if table, err := strconv.Unquote(lit.Value); err == nil {
+		pkg.tables[ident.Name] = table
+	}
+}
+
+func (pkg *modelPackage) addAnnotations(model string, doc *ast.CommentGroup) error {
+	if doc == nil {
+		return nil
+	}
+
+	isModel := false
+
+	for _, comment := range doc.List {
+		switch {
+		case comment.Text == modelAnnotation:
+			isModel = true
+
+		case strings.HasPrefix(comment.Text, columnsAnnotation+" "):
+			args := strings.Fields(strings.TrimPrefix(comment.Text, columnsAnnotation))
+			if len(args) < 2 {
+				return fmt.Errorf("%s of %s must have name and alias", columnsAnnotation, model)
+			}
+
+			decl := columnsDecl{
+				name:  args[0],
+				model: model,
+				alias: args[1],
+			}
+
+			for _, arg := range args[2:] {
+				joinModel, alias, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("join model (%s) of %s must look like Model=alias", arg, model)
+				}
+
+				decl.joinModels = append(decl.joinModels, mfp.M{N: joinModel, A: alias})
+			}
+
+			pkg.columns = append(pkg.columns, decl)
+			isModel = true
+
+		case strings.HasPrefix(comment.Text, constsAnnotation+" "):
+			args := strings.Fields(strings.TrimPrefix(comment.Text, constsAnnotation))
+			if len(args) != 1 {
+				return fmt.Errorf("%s of %s must have alias only", constsAnnotation, model)
+			}
+
+			pkg.consts = append(pkg.consts, columnsDecl{model: model, alias: args[0]})
+		}
+	}
+
+	if isModel {
+		pkg.models = append(pkg.models, model)
+	}
+
+	return nil
+}
+
+// genFieldInfo is mfp.FieldInfo with the source of the field's type instead of reflect.Type
+type genFieldInfo struct {
+	mfp.FieldInfo
+	typeExpr  string
+	modelInfo *genModelInfo
+}
+
+type genModelInfo struct {
+	mfp.ModelInfo
+	fields []*genFieldInfo
+}
+
+// modelInfo builds model info of the struct the same way ModelFieldsPrefixer scans models with reflection.
+// imports collects imports used by types of the fields
+func (pkg *modelPackage) modelInfo(name string, dbAlias string, modelsPrefix string, imports map[string]string, visiting map[string]bool) (*genModelInfo, bool) {
+	decl := pkg.structs[name]
+
+	modelInfo := &genModelInfo{
+		ModelInfo: mfp.ModelInfo{
+			Name:         name,
+			DBAlias:      dbAlias,
+			ModelsPrefix: modelsPrefix,
+			Table:        pkg.tables[name],
+		},
+	}
+
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	isAnyDBTag := false
+	index := -1
+
+	// counts are the relations counted by fields marked with 'count' tag option
+	counts := make(map[*mfp.FieldInfo]string)
+
+	for _, field := range decl.typ.Fields.List {
+		names := len(field.Names)
+		if names == 0 {
+			// embedded field
+			names = 1
+		}
+
+		for n := 0; n < names; n++ {
+			index++
+
+			goName := relationName(field.Type)
+			if len(field.Names) > 0 {
+				goName = field.Names[n].Name
+			}
+
+			tag := ""
+			if field.Tag != nil {
+				tag, _ = strconv.Unquote(field.Tag.Value)
+			}
+
+			dbTag, ok := pkg.columnTag(reflect.StructTag(tag), goName, len(field.Names) == 0)
+			if !ok {
+				continue
+			}
+
+			isAnyDBTag = true
+
+			fieldInfo := &genFieldInfo{
+				FieldInfo: dbTag.FieldInfo(),
+				typeExpr:  pkg.typeExpr(field.Type, decl.imports, imports),
+			}
+
+			fieldInfo.Name = goName
+			fieldInfo.Index = index
+
+			if fieldInfo.IsJSON {
+				// keys of JSON objects are selected as columns, other JSON values (e.g. arrays) are selected as is
+				expr := field.Type
+				if star, ok := expr.(*ast.StarExpr); ok {
+					expr = star.X
+				}
+
+				if ident, ok := expr.(*ast.Ident); ok && pkg.structs[ident.Name] != nil {
+					prefix := dbTag.Name
+					if modelsPrefix != "" {
+						prefix = modelsPrefix + "." + dbTag.Name
+					}
+
+					fieldInfo.modelInfo = pkg.jsonModelInfo(ident.Name, prefix, imports, make(map[string]bool))
+				}
+			}
+
+			// fields marked with 'noscan' or 'json' tag options are columns even if they are structs
+			if nested := relationName(field.Type); nested != "" && !visiting[nested] && !dbTag.Has("noscan") && !fieldInfo.IsJSON {
+				if _, ok := pkg.structs[nested]; ok {
+					prefix := dbTag.Name
+					if modelsPrefix != "" {
+						prefix = modelsPrefix + "." + dbTag.Name
+					}
+
+					alias := dbTag.Name
+					if dbAlias := reflect.StructTag(tag).Get("dbalias"); dbAlias != "" {
+						alias = dbAlias
+					}
+
+					if innerModel, ok := pkg.modelInfo(nested, alias, prefix, imports, visiting); ok {
+						fieldInfo.IsStruct = true
+						fieldInfo.modelInfo = innerModel
+					}
+				}
+			}
+
+			if relation := dbTag.Value("count"); relation != "" {
+				counts[&fieldInfo.FieldInfo] = relation
+			}
+
+			modelInfo.fields = append(modelInfo.fields, fieldInfo)
+		}
+	}
+
+	if len(counts) > 0 {
+		modelInfo.resolveCounts(counts)
+	}
+
+	return modelInfo, isAnyDBTag
+}
+
+// columnTag returns the parsed tag of the field the same way ModelFieldsPrefixer parses it: fields without column
+// names in their tags are named by the naming strategy, unexported and untagged embedded fields are skipped
+func (pkg *modelPackage) columnTag(tag reflect.StructTag, fieldName string, embedded bool) (mfp.Tag, bool) {
+	value, isTagged := tag.Lookup(pkg.tagName)
+
+	dbTag := mfp.ParseTag(value)
+	if dbTag.Name == "" && pkg.naming != nil && token.IsExported(fieldName) && (!embedded || isTagged) {
+		dbTag.Name = pkg.naming(fieldName)
+	}
+
+	return dbTag, dbTag.Name != "" && dbTag.Name != "-"
+}
+
+// resolveCounts resolves relations counted by the fields with mfp.ResolveCounts, relations' model infos are set
+// to the fields for it
+func (m *genModelInfo) resolveCounts(counts map[*mfp.FieldInfo]string) {
+	m.Fields = make([]*mfp.FieldInfo, 0, len(m.fields))
+
+	for _, field := range m.fields {
+		if field.modelInfo != nil {
+			field.ModelInfo = &field.modelInfo.ModelInfo
+		}
+
+		m.Fields = append(m.Fields, &field.FieldInfo)
+	}
+
+	mfp.ResolveCounts(&m.ModelInfo, counts)
+}
+
+// jsonModelInfo builds model info of the payload of JSON column from json tags of the struct the same way
+// ModelFieldsPrefixer does with reflection
+func (pkg *modelPackage) jsonModelInfo(name string, modelsPrefix string, imports map[string]string, visiting map[string]bool) *genModelInfo {
+	decl := pkg.structs[name]
+
+	modelInfo := &genModelInfo{
+		ModelInfo: mfp.ModelInfo{
+			Name:         name,
+			ModelsPrefix: modelsPrefix,
+		},
+	}
+
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	index := -1
+
+	for _, field := range decl.typ.Fields.List {
+		if len(field.Names) == 0 {
+			// embedded fields are skipped
+			index++
+
+			continue
+		}
+
+		for _, fieldName := range field.Names {
+			index++
+
+			if !fieldName.IsExported() {
+				continue
+			}
+
+			key := ""
+			if field.Tag != nil {
+				tag, _ := strconv.Unquote(field.Tag.Value)
+				key = mfp.ParseTag(reflect.StructTag(tag).Get("json")).Name
+			}
+
+			if key == "-" {
+				continue
+			}
+
+			if key == "" {
+				key = fieldName.Name
+			}
+
+			fieldInfo := &genFieldInfo{
+				FieldInfo: mfp.FieldInfo{
+					Name:   fieldName.Name,
+					DBTag:  key,
+					Index:  index,
+					IsJSON: true,
+				},
+				typeExpr: pkg.typeExpr(field.Type, decl.imports, imports),
+			}
+
+			expr := field.Type
+			if star, ok := expr.(*ast.StarExpr); ok {
+				expr = star.X
+			}
+
+			ident, _ := expr.(*ast.Ident)
+
+			switch {
+			case ident != nil && pkg.isBasicType(ident.Name):
+			case ident != nil && pkg.structs[ident.Name] != nil && !visiting[ident.Name]:
+				fieldInfo.modelInfo = pkg.jsonModelInfo(ident.Name, modelsPrefix+"."+key, imports, visiting)
+			default:
+				fieldInfo.Codec = "json"
+			}
+
+			modelInfo.fields = append(modelInfo.fields, fieldInfo)
+		}
+	}
+
+	return modelInfo
+}
+
+// isBasicType reports whether the type is a boolean, a number or a string, so its JSON value is extracted as text
+func (pkg *modelPackage) isBasicType(name string) bool {
+	if underlying, ok := pkg.underlying[name]; ok {
+		name = underlying
+	}
+
+	switch name {
+	case "bool", "string",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+
+	return false
+}
+
+// expandConsts turns '//prefixer:consts ALIAS' annotations into columns lists: ModelColumns with all the relations
+// and ModelWithFieldColumns for every direct relation of the model (Field is the name of the relation field)
+func (pkg *modelPackage) expandConsts() {
+	for _, decl := range pkg.consts {
+		pkg.columns = append(pkg.columns, columnsDecl{
+			name:  decl.model + "Columns",
+			model: decl.model,
+			alias: decl.alias,
+		})
+
+		modelInfo, _ := pkg.modelInfo(decl.model, "", "", make(map[string]string), make(map[string]bool))
+
+		for _, field := range modelInfo.fields {
+			if !field.IsStruct {
+				continue
+			}
+
+			pkg.columns = append(pkg.columns, columnsDecl{
+				name:       decl.model + "With" + field.Name + "Columns",
+				model:      decl.model,
+				alias:      decl.alias,
+				joinModels: []mfp.M{{N: field.modelInfo.Name}},
+			})
+		}
+	}
+}
+
+// toModelInfo converts generator's model info to mfp.ModelInfo to render columns lists
+func (m *genModelInfo) toModelInfo() *mfp.ModelInfo {
+	modelInfo := m.ModelInfo
+	modelInfo.Fields = make([]*mfp.FieldInfo, 0, len(m.fields))
+
+	for _, field := range m.fields {
+		fieldInfo := field.FieldInfo
+		if field.modelInfo != nil {
+			fieldInfo.ModelInfo = field.modelInfo.toModelInfo()
+		}
+
+		modelInfo.Fields = append(modelInfo.Fields, &fieldInfo)
+	}
+
+	return &modelInfo
+}
+
+// relationName returns the name of the struct the field refers to via any nesting of pointers, slices and arrays
+// around it (e.g. *[]T or [][]*T), empty string otherwise
+func relationName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ArrayType:
+			// slices and arrays of models are relations as well as pointers to them
+			expr = e.Elt
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// typeExpr returns the source of the type and adds imports it uses to used
+func (pkg *modelPackage) typeExpr(expr ast.Expr, fileImports map[string]string, used map[string]string) string {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := selector.X.(*ast.Ident); ok {
+			if path, ok := fileImports[ident.Name]; ok {
+				used[ident.Name] = path
+			}
+		}
+
+		return false
+	})
+
+	buf := &bytes.Buffer{}
+	_ = printer.Fprint(buf, pkg.fset, expr)
+
+	return buf.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+const testModels = `package models
+
+//prefixer:columns UserColumns u
+type User struct {
+	ID       int    ` + "`db:\"id,pk\" col:\"user_id,pk\"`" + `
+	FullName string ` + "`db:\"full_name\"`" + `
+	Email    string ` + "`db:\"-\"`" + `
+	Posts    int    ` + "`db:\"posts,count=Post\"`" + `
+	Post     []Post
+}
+
+//prefixer:model
+type Post struct {
+	ID int ` + "`db:\"id\" col:\"post_id\"`" + `
+}
+`
+
+func TestParsePackage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(testModels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tagName string
+		naming  string
+		columns string
+	}{
+		{"db tag", "db", "", "u.id, u.full_name, u.posts"},
+		{"custom tag", "col", "", "u.user_id"},
+		{"custom tag with naming", "col", "snake", "u.user_id, u.full_name, u.email, u.posts, post.post_id AS \"post.post_id\""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := namingStrategies[test.naming]
+			config.tagName = test.tagName
+
+			pkg, err := parsePackage(dir, "prefixer_gen.go", config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			columns, err := pkg.renderColumns(pkg.columns[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if columns != test.columns {
+				t.Errorf("columns = %q, want %q", columns, test.columns)
+			}
+		})
+	}
+}
+
+func TestColumnTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		field    string
+		embedded bool
+		naming   mfp.NamingStrategy
+		column   string
+		ok       bool
+	}{
+		{"tagged", `db:"id,pk"`, "ID", false, nil, "id", true},
+		{"skipped", `db:"-"`, "ID", false, mfp.SnakeCase, "-", false},
+		{"untagged", ``, "FullName", false, nil, "", false},
+		{"named", ``, "FullName", false, mfp.SnakeCase, "full_name", true},
+		{"unexported", ``, "fullName", false, mfp.SnakeCase, "", false},
+		{"embedded", ``, "Base", true, mfp.SnakeCase, "", false},
+		{"tagged embedded", `db:""`, "Base", true, mfp.SnakeCase, "base", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pkg := &modelPackage{scanConfig: scanConfig{tagName: "db", naming: test.naming}}
+
+			tag, ok := pkg.columnTag(reflect.StructTag(test.tag), test.field, test.embedded)
+			if tag.Name != test.column || ok != test.ok {
+				t.Errorf("columnTag() = %q, %v, want %q, %v", tag.Name, ok, test.column, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+)
+
+// WithCoalesce wraps columns of all the relations in COALESCE with zero values of their fields' types -
+// 'COALESCE(um.age, 0) AS "um.age"', so non-pointer fields of optional LEFT JOINs are scanned without NULL errors.
+// M.C does the same for a single join model
+func WithCoalesce() ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.coalesce = true
+	}
+}
+
+// coalesceDefault returns the zero value of the field's type the column is coalesced with if the column belongs
+// to a coalesced relation. Fields of pointers, slices and scanner types (e.g. sql.NullString) handle NULL themselves,
+// fields with codecs are decoded by them and other structs (e.g. time.Time) have no zero literal, so they aren't
+// coalesced
+func (mp *ModelFieldsPrefixer) coalesceDefault(field *FieldInfo) (string, bool) {
+	if !mp.coalescing || field.Codec != "" || field.Type == nil || isScannerType(field.Type) {
+		return "", false
+	}
+
+	switch field.Type.Kind() {
+	case reflect.String:
+		return "''", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "0", true
+	case reflect.Bool:
+		// MSSQL and Oracle have no boolean literals, their boolean columns are numbers
+		switch mp.dialect.Name() {
+		case "mssql", "oracle":
+			return "0", true
+		}
+
+		return "FALSE", true
+	}
+
+	return "", false
+}
@@ -0,0 +1,283 @@
+package model_fields_prefixer
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Codec converts values of a field between its Go type and the representation stored in a database.
+// Codecs are declared in db tag options, e.g. `db:"prefs,codec=json"`, and are applied when values are scanned
+// (Scan, Collect, ScanTargets) and bound to queries (Values). Nil pointers and NULL values never reach codecs
+type Codec interface {
+	// Encode converts value of the field to a value which can be passed to a query
+	Encode(value any) (any, error)
+	// Decode converts value read from a database and sets it to dest
+	Decode(src any, dest reflect.Value) error
+}
+
+// defaultCodecs are codecs available to every prefixer
+var defaultCodecs = map[string]Codec{
+	"json":     JSONCodec{},
+	"csv":      CSVCodec{},
+	"unixtime": UnixTimeCodec{},
+}
+
+// codecRegistry keeps codecs registered with SetCodec, it is shared by the prefixers allocated from the same instance,
+// so codecs can be registered while they are used by other goroutines
+type codecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// newCodecRegistry returns the registry of the default codecs
+func newCodecRegistry() *codecRegistry {
+	r := &codecRegistry{codecs: make(map[string]Codec, len(defaultCodecs))}
+
+	for name, codec := range defaultCodecs {
+		r.codecs[name] = codec
+	}
+
+	return r
+}
+
+func (r *codecRegistry) set(name string, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codecs[name] = codec
+}
+
+func (r *codecRegistry) get(name string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codec, ok := r.codecs[name]
+
+	return codec, ok
+}
+
+// clone returns the registry of the same codecs which are registered apart from r
+func (r *codecRegistry) clone() *codecRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &codecRegistry{codecs: maps.Clone(r.codecs)}
+}
+
+// SetCodec registers codec under the name to be used in `codec=name` tag option. Codecs are shared by the prefixers
+// allocated from mp (AllocPrefixer, Acquire), so it is safe to register them while they are in use
+func (mp *ModelFieldsPrefixer) SetCodec(name string, codec Codec) *ModelFieldsPrefixer {
+	mp.codecs.set(name, codec)
+
+	return mp
+}
+
+func (mp *ModelFieldsPrefixer) getCodec(name string) (Codec, error) {
+	codec, ok := mp.codecs.get(name)
+	if !ok {
+		return nil, fmt.Errorf("codec (%s) is not registered", name)
+	}
+
+	return codec, nil
+}
+
+// encodeValue converts value of the field with the field's codec if there is one
+func (mp *ModelFieldsPrefixer) encodeValue(field *FieldInfo, value reflect.Value) (any, error) {
+	if field.Codec == "" {
+		return value.Interface(), nil
+	}
+
+	codec, err := mp.getCodec(field.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	return codec.Encode(value.Interface())
+}
+
+// decodeValue converts src with the field's codec and sets it to dest, allocating dest if it is a pointer
+func (mp *ModelFieldsPrefixer) decodeValue(field *FieldInfo, src any, dest reflect.Value) error {
+	codec, err := mp.getCodec(field.Codec)
+	if err != nil {
+		return err
+	}
+
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+
+		return nil
+	}
+
+	if dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+
+		dest = dest.Elem()
+	}
+
+	if err = codec.Decode(src, dest); err != nil {
+		return fmt.Errorf("failed to decode column (%s): %w", field.DBTag, err)
+	}
+
+	return nil
+}
+
+// codecScanner implements sql.Scanner decoding scanned values into the field with the field's codec
+type codecScanner struct {
+	mp    *ModelFieldsPrefixer
+	field *FieldInfo
+	dest  reflect.Value
+}
+
+var _ sql.Scanner = (*codecScanner)(nil)
+
+func (s *codecScanner) Scan(src any) error {
+	return s.mp.decodeValue(s.field, src, s.dest)
+}
+
+// JSONCodec stores values as JSON documents
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (JSONCodec) Decode(src any, dest reflect.Value) error {
+	b, err := srcBytes(src)
+	if err != nil {
+		// some drivers (e.g. pgx) decode JSON columns themselves, so convert the value back to JSON
+		if b, err = json.Marshal(src); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(b, dest.Addr().Interface())
+}
+
+// CSVCodec stores []string values as a single CSV record, e.g. 'a,b,"c,d"'
+type CSVCodec struct{}
+
+func (CSVCodec) Encode(value any) (any, error) {
+	record, ok := value.([]string)
+	if !ok {
+		return nil, fmt.Errorf("csv codec supports only []string, got %T", value)
+	}
+
+	buf := &bytes.Buffer{}
+
+	w := csv.NewWriter(buf)
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return strings.TrimRight(buf.String(), "\r\n"), nil
+}
+
+func (CSVCodec) Decode(src any, dest reflect.Value) error {
+	if dest.Type() != reflect.TypeOf([]string(nil)) {
+		return fmt.Errorf("csv codec supports only []string, got %s", dest.Type())
+	}
+
+	b, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+
+	if len(b) == 0 {
+		dest.Set(reflect.ValueOf([]string{}))
+
+		return nil
+	}
+
+	record, err := csv.NewReader(bytes.NewReader(b)).Read()
+	if err != nil {
+		return err
+	}
+
+	dest.Set(reflect.ValueOf(record))
+
+	return nil
+}
+
+// UnixTimeCodec stores time.Time values as unix timestamps in seconds
+type UnixTimeCodec struct{}
+
+func (UnixTimeCodec) Encode(value any) (any, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unixtime codec supports only time.Time, got %T", value)
+	}
+
+	return t.Unix(), nil
+}
+
+func (UnixTimeCodec) Decode(src any, dest reflect.Value) error {
+	if dest.Type() != timeType {
+		return fmt.Errorf("unixtime codec supports only time.Time, got %s", dest.Type())
+	}
+
+	var seconds int64
+
+	switch s := src.(type) {
+	case int64:
+		seconds = s
+	case int32:
+		seconds = int64(s)
+	case float64:
+		seconds = int64(s)
+	case []byte, string:
+		b, _ := srcBytes(s)
+
+		parsed, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return err
+		}
+
+		seconds = parsed
+	default:
+		return fmt.Errorf("unixtime codec can't decode %T", src)
+	}
+
+	dest.Set(reflect.ValueOf(time.Unix(seconds, 0)))
+
+	return nil
+}
+
+func srcBytes(src any) ([]byte, error) {
+	switch s := src.(type) {
+	case []byte:
+		return s, nil
+	case string:
+		return []byte(s), nil
+	default:
+		return nil, fmt.Errorf("can't decode %T, expected string or []byte", src)
+	}
+}
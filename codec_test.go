@@ -0,0 +1,97 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+var errEncode = errors.New("value can't be encoded")
+
+// failingCodec fails to encode negative numbers
+type failingCodec struct{}
+
+func (failingCodec) Encode(value any) (any, error) {
+	if n, ok := value.(int); ok && n < 0 {
+		return nil, errEncode
+	}
+
+	return value, nil
+}
+
+func (failingCodec) Decode(src any, dest reflect.Value) error {
+	dest.Set(reflect.ValueOf(src))
+
+	return nil
+}
+
+type Counter struct {
+	ID    int64 `db:"id"`
+	Value int   `db:"value,codec=checked"`
+}
+
+func TestValuesCodec(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   Counter
+		want    []any
+		wantErr error
+	}{
+		{
+			name:  "encoded",
+			model: Counter{ID: 1, Value: 2},
+			want:  []any{int64(1), 2},
+		},
+		{
+			name:    "failed to encode",
+			model:   Counter{ID: 1, Value: -2},
+			wantErr: errEncode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mfp.New(mfp.WithCodec("checked", failingCodec{})).ValuesStrict(tt.model)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValuesStrict() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValuesStrict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCodecConcurrent(t *testing.T) {
+	mp := mfp.New(mfp.WithCodec("checked", failingCodec{}))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			mp.SetCodec("codec"+strconv.Itoa(i), failingCodec{})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			p := mp.Acquire()
+			defer p.Release()
+
+			if _, err := p.ValuesStrict(Counter{ID: 1, Value: 2}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
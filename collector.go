@@ -0,0 +1,362 @@
+package model_fields_prefixer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+var errCollectDest = errors.New("dest must be a non-nil pointer to a slice of structs")
+
+// anyType is the type of the values of collect keys
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// collectNode describes how columns of a row are mapped to a model and its nested models
+type collectNode struct {
+	columns []collectColumn
+	// keyColumns are indexes of row columns identifying the model, pk columns or all the columns if there are no pk ones
+	keyColumns []int
+	// hasPK is true if the key columns are pk ones, the model isn't identified if any of them is NULL
+	hasPK bool
+	// keyType is the array of values of the key columns, [n]any, arrays of comparable values are keys of maps
+	keyType   reflect.Type
+	relations []*collectRelation
+}
+
+// relationKey identifies the model of the slice relation among the models of the relations of all the parents
+type relationKey struct {
+	parent   any
+	relation *FieldInfo
+	key      any
+}
+
+// collectColumn is the field of the model and the index of the row column it is scanned from
+type collectColumn struct {
+	index int
+	field *FieldInfo
+}
+
+type collectRelation struct {
+	field *FieldInfo
+	node  *collectNode
+}
+
+// Collect scans all the rows into dest which must be a pointer to a slice of structs (or pointers to structs),
+// grouping rows of one-to-many joins. Rows are grouped by the columns marked with 'pk' tag option (`db:"id,pk"`),
+// so every root model appears in dest only once and rows of slice relations (e.g. []Comment) are appended to it.
+// Models without pk columns are identified by values of all their columns. Rows with NULL pk columns of the root
+// model fail with prefixererr.ErrNullKey, while relations with NULL pk columns (e.g. unmatched LEFT JOINs) are skipped
+func (mp *ModelFieldsPrefixer) Collect(rows *sql.Rows, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errCollectDest
+	}
+
+	v = v.Elem()
+
+	elemType := v.Type().Elem()
+
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return errCollectDest
+	}
+
+	modelInfo := mp.getModelInfo(elemType)
+	if modelInfo == nil {
+		return fmt.Errorf("model %s has no db tags", elemType.Name())
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	columnIndexes := make(map[string]int, len(columns))
+	for i, column := range columns {
+		columnIndexes[column] = i
+	}
+
+	// names of the columns made unique by Columns are resolved to their fields
+	columnsByName := mp.scanColumns(modelInfo)
+
+	fieldIndexes := make(map[*FieldInfo]int, len(columnsByName))
+	for name, column := range columnsByName {
+		if index, ok := columnIndexes[name]; ok {
+			fieldIndexes[column.field] = index
+		}
+	}
+
+	root := newCollectNode(modelInfo, fieldIndexes)
+
+	// seen maps keys of already collected models to their indexes in the parent slice
+	seen := make(map[any]int)
+
+	for rows.Next() {
+		holders := make([]any, len(columns))
+
+		for i := range holders {
+			holders[i] = new(any)
+		}
+
+		mp.setCollectHolders(root, holders)
+
+		if err = rows.Scan(holders...); err != nil {
+			return err
+		}
+
+		key, ok := root.key(holders)
+		if !ok {
+			return fmt.Errorf("%w: %s", prefixererr.ErrNullKey, modelInfo.Name)
+		}
+
+		index, ok := seen[key]
+		if !ok {
+			if isPtr {
+				v.Set(reflect.Append(v, reflect.New(elemType)))
+			} else {
+				v.Set(reflect.Append(v, reflect.New(elemType).Elem()))
+			}
+
+			index = v.Len() - 1
+			seen[key] = index
+		}
+
+		elem := v.Index(index)
+		if isPtr {
+			elem = elem.Elem()
+		}
+
+		if err = mp.hydrate(root, elem, holders, key, seen, !ok); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func newCollectNode(model *ModelInfo, fieldIndexes map[*FieldInfo]int) *collectNode {
+	node := &collectNode{}
+
+	var allColumns []int
+
+	for _, field := range model.Fields {
+		// keys of JSON columns are collected to the payload like a one-to-one relation
+		if (field.IsStruct || field.IsJSON) && field.ModelInfo != nil {
+			node.relations = append(node.relations, &collectRelation{
+				field: field,
+				node:  newCollectNode(field.ModelInfo, fieldIndexes),
+			})
+
+			continue
+		}
+
+		index, ok := fieldIndexes[field]
+		if !ok {
+			continue
+		}
+
+		node.columns = append(node.columns, collectColumn{index: index, field: field})
+		allColumns = append(allColumns, index)
+
+		if field.IsPK {
+			node.keyColumns = append(node.keyColumns, index)
+		}
+	}
+
+	node.hasPK = len(node.keyColumns) > 0
+	if !node.hasPK {
+		node.keyColumns = allColumns
+	}
+
+	node.keyType = reflect.ArrayOf(len(node.keyColumns), anyType)
+
+	return node
+}
+
+// setCollectHolders replaces placeholders of the model's columns with pointers to pointers of the fields' types,
+// so NULL values can be told apart from zero ones
+func (mp *ModelFieldsPrefixer) setCollectHolders(node *collectNode, holders []any) {
+	for _, column := range node.columns {
+		// values of fields with codecs are scanned as is and decoded later
+		if column.field.Codec != "" {
+			continue
+		}
+
+		holders[column.index] = reflect.New(reflect.PtrTo(column.field.Type)).Interface()
+	}
+
+	for _, relation := range node.relations {
+		mp.setCollectHolders(relation.node, holders)
+	}
+}
+
+// key returns the values of the key columns identifying the model in the row, NULL values are nil. The model isn't
+// identified if any of its pk columns is NULL or, if it has no pk columns, all of its columns are NULL
+func (n *collectNode) key(holders []any) (any, bool) {
+	key := reflect.New(n.keyType).Elem()
+
+	isAnyValue := false
+
+	for i, index := range n.keyColumns {
+		value, ok := keyValue(reflect.ValueOf(holders[index]).Elem())
+		if !ok {
+			if n.hasPK {
+				return nil, false
+			}
+
+			continue
+		}
+
+		isAnyValue = true
+
+		key.Index(i).Set(reflect.ValueOf(value))
+	}
+
+	if !isAnyValue {
+		return nil, false
+	}
+
+	return key.Interface(), true
+}
+
+// keyValue returns the comparable value of the key column scanned to the holder, false if it is NULL. Values of
+// pointer fields are compared by the values they point to and raw []byte values of columns with codecs as strings
+func keyValue(holder reflect.Value) (any, bool) {
+	for holder.Kind() == reflect.Ptr || holder.Kind() == reflect.Interface {
+		if holder.IsNil() {
+			return nil, false
+		}
+
+		holder = holder.Elem()
+	}
+
+	value := holder.Interface()
+
+	if b, ok := value.([]byte); ok {
+		return string(b), true
+	}
+
+	if !holder.Type().Comparable() {
+		return fmt.Sprintf("%T:%v", value, value), true
+	}
+
+	return value, true
+}
+
+// hydrate sets values of the row to the model v. Fields are set only for newly collected models, while slice relations
+// are appended for every row. parentKey is used to tell apart slice relations of different parent models
+func (mp *ModelFieldsPrefixer) hydrate(n *collectNode, v reflect.Value, holders []any, parentKey any, seen map[any]int, isNew bool) error {
+	if isNew {
+		for _, column := range n.columns {
+			value := reflect.ValueOf(holders[column.index]).Elem()
+			if value.IsNil() {
+				continue
+			}
+
+			field := v.Field(column.field.Index)
+			if !field.CanSet() {
+				continue
+			}
+
+			if column.field.Codec != "" {
+				if err := mp.decodeValue(column.field, value.Interface(), field); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			field.Set(value.Elem())
+		}
+	}
+
+	for _, relation := range n.relations {
+		field := v.Field(relation.field.Index)
+		if !field.CanSet() {
+			continue
+		}
+
+		key, ok := relation.node.key(holders)
+		if !ok {
+			continue
+		}
+
+		fieldType := relation.field.Type
+
+		if !isSliceType(fieldType) {
+			if fieldType.Kind() == reflect.Ptr {
+				if field.IsNil() {
+					field.Set(reflect.New(fieldType.Elem()))
+				}
+
+				field = field.Elem()
+			}
+
+			if err := mp.hydrate(relation.node, field, holders, parentKey, seen, isNew); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if fieldType.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(fieldType.Elem()))
+			}
+
+			field = field.Elem()
+		}
+
+		// only []T and []*T are grown by rows, relations nested deeper (e.g. [][]T) or arrays are left as is
+		if !isAppendableSlice(field.Type()) {
+			continue
+		}
+
+		relationKey := relationKey{parent: parentKey, relation: relation.field, key: key}
+
+		index, ok := seen[relationKey]
+		if !ok {
+			elemType := field.Type().Elem()
+			if elemType.Kind() == reflect.Ptr {
+				field.Set(reflect.Append(field, reflect.New(elemType.Elem())))
+			} else {
+				field.Set(reflect.Append(field, reflect.New(elemType).Elem()))
+			}
+
+			index = field.Len() - 1
+			seen[relationKey] = index
+		}
+
+		elem := field.Index(index)
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if err := mp.hydrate(relation.node, elem, holders, relationKey, seen, !ok); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isAppendableSlice reports whether child rows can be appended to the slice, i.e. it is []T or []*T of a struct
+func isAppendableSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	elemType := t.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	return elemType.Kind() == reflect.Struct
+}
@@ -0,0 +1,148 @@
+package model_fields_prefixer_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Post struct {
+	ID       int64     `db:"id,pk"`
+	Title    string    `db:"title"`
+	Comments []Comment `db:"comments"`
+	Labels   []Label   `db:"labels"`
+}
+
+type Comment struct {
+	ID   int64  `db:"id,pk"`
+	Body string `db:"body"`
+}
+
+// Label has no pk columns, so it is identified by all of its columns
+type Label struct {
+	Name  *string `db:"name"`
+	Color *string `db:"color"`
+}
+
+// rowsConnector is the driver returning the same rows for every query
+type rowsConnector struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c rowsConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c rowsConnector) Driver() driver.Driver                        { return nil }
+func (c rowsConnector) Prepare(string) (driver.Stmt, error)          { return c, nil }
+func (c rowsConnector) Close() error                                 { return nil }
+func (c rowsConnector) Begin() (driver.Tx, error)                    { return nil, errors.ErrUnsupported }
+func (c rowsConnector) NumInput() int                                { return -1 }
+
+func (c rowsConnector) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (c rowsConnector) Query([]driver.Value) (driver.Rows, error) {
+	return &driverRows{columns: c.columns, rows: c.rows}, nil
+}
+
+type driverRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *driverRows) Columns() []string { return r.columns }
+func (r *driverRows) Close() error      { return nil }
+
+func (r *driverRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestCollect(t *testing.T) {
+	columns := []string{"id", "title", "comments.id", "comments.body", "labels.name", "labels.color"}
+
+	tests := []struct {
+		name string
+		rows [][]driver.Value
+		want []Post
+		err  error
+	}{
+		{
+			name: "one-to-many",
+			rows: [][]driver.Value{
+				{int64(1), "first", int64(10), "a", nil, nil},
+				{int64(1), "first", int64(11), "b", nil, nil},
+				{int64(2), "second", nil, nil, nil, nil},
+			},
+			want: []Post{
+				{ID: 1, Title: "first", Comments: []Comment{{ID: 10, Body: "a"}, {ID: 11, Body: "b"}}},
+				{ID: 2, Title: "second"},
+			},
+		},
+		{
+			name: "NULL pk of the relation",
+			rows: [][]driver.Value{
+				{int64(1), "first", nil, "orphan", nil, nil},
+			},
+			want: []Post{{ID: 1, Title: "first"}},
+		},
+		{
+			name: "NULL and empty values of the relation without pk",
+			rows: [][]driver.Value{
+				{int64(1), "first", nil, nil, "", nil},
+				{int64(1), "first", nil, nil, nil, ""},
+				{int64(1), "first", nil, nil, "", nil},
+			},
+			want: []Post{{ID: 1, Title: "first", Labels: []Label{{Name: strPtr("")}, {Color: strPtr("")}}}},
+		},
+		{
+			name: "NULL pk of the root",
+			rows: [][]driver.Value{
+				{int64(1), "first", nil, nil, nil, nil},
+				{nil, "second", nil, nil, nil, nil},
+			},
+			err: prefixererr.ErrNullKey,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := sql.OpenDB(rowsConnector{columns: columns, rows: test.rows})
+			defer db.Close()
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+
+			var posts []Post
+
+			err = mfp.New().Collect(rows, &posts)
+			if !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Collect() error = %v, want %v", err, test.err)
+			}
+
+			if test.err == nil && !reflect.DeepEqual(posts, test.want) {
+				t.Errorf("Collect() = %+v, want %+v", posts, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// tableAlias is the alias of the table of the root model or a relation written by the last Columns call,
+// relation is the name of the root model followed by the path of the relation's fields, e.g. 'User.Author.Profile'
+type tableAlias struct {
+	relation string
+	alias    string
+}
+
+// addTableAlias keeps the alias of the model's table written by buildString, namePath is empty for the root model
+func (mp *ModelFieldsPrefixer) addTableAlias(model *ModelInfo, dbAlias string, namePath string) {
+	// fields of STRUCT columns are paths from the table of the column - 'u.address'
+	if strings.Contains(dbAlias, ".") {
+		return
+	}
+
+	relation := model.Name
+	if namePath != "" && mp.rootModel != nil {
+		relation = mp.rootModel.Name + "." + namePath
+	}
+
+	mp.tableAliases = append(mp.tableAliases, tableAlias{relation: relation, alias: dbAlias})
+}
+
+// checkAliasCollisions reports tables written since the index which have the aliases of other tables of the call,
+// e.g. Buyer and Seller relations of the same model both matched by the join model's name, since their columns
+// are ambiguous in the query. Several roots of ColumnsMulti are checked against each other as well
+func (mp *ModelFieldsPrefixer) checkAliasCollisions(from int) {
+	for i := from; i < len(mp.tableAliases); i++ {
+		table := mp.tableAliases[i]
+
+		for _, other := range mp.tableAliases[:i] {
+			if other.alias == table.alias && other.relation != table.relation {
+				mp.failAliasCollision(table.alias, other.relation, table.relation)
+
+				break
+			}
+		}
+	}
+}
+
+func (mp *ModelFieldsPrefixer) failAliasCollision(alias, relation, other string) {
+	err := fmt.Errorf("%w: %s is used by %s and %s", prefixererr.ErrAliasCollision, alias, relation, other)
+
+	mp.warnKind(WarningAliasCollision, err.Error(), "alias", alias, "relations", []string{relation, other})
+
+	if mp.strict {
+		mp.errs = append(mp.errs, err)
+	}
+}
@@ -0,0 +1,63 @@
+package model_fields_prefixer
+
+import (
+	"sort"
+)
+
+// ColumnOrder defines the order of columns of every model built by Columns
+type ColumnOrder int
+
+const (
+	// DeclarationOrder writes columns in order of the struct's fields. It is the default order
+	DeclarationOrder ColumnOrder = iota
+	// AlphabeticalOrder writes columns sorted by their names, so the order doesn't change when fields are moved around
+	AlphabeticalOrder
+)
+
+// WithColumnOrder sets the order of columns of every model. Fields with 'order' tag option (e.g. `db:"id,order=1"`)
+// are written before the others sorted by their positions regardless of the order
+func WithColumnOrder(order ColumnOrder) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.columnOrder = order
+	}
+}
+
+// orderedFields returns fields of the model in the order their columns are written, the model's own fields are
+// returned if the order is the declaration one
+func (mp *ModelFieldsPrefixer) orderedFields(model *ModelInfo) []*FieldInfo {
+	isOrdered := mp.columnOrder == DeclarationOrder
+
+	for _, field := range model.Fields {
+		if field.Order != 0 {
+			isOrdered = false
+
+			break
+		}
+	}
+
+	if isOrdered {
+		return model.Fields
+	}
+
+	fields := make([]*FieldInfo, len(model.Fields))
+	copy(fields, model.Fields)
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		// fields with explicit positions go first
+		if fields[i].Order != fields[j].Order {
+			if fields[i].Order == 0 || fields[j].Order == 0 {
+				return fields[j].Order == 0
+			}
+
+			return fields[i].Order < fields[j].Order
+		}
+
+		if mp.columnOrder == AlphabeticalOrder && fields[i].Order == 0 {
+			return fields[i].DBTag < fields[j].DBTag
+		}
+
+		return false
+	})
+
+	return fields
+}
@@ -0,0 +1,95 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// ColumnsOption tweaks a single Columns call, it is passed to Columns along with join models:
+//
+//	m.Columns(User{}, "u", mfp.WithJoins(mfp.M{N: "UserMeta", A: "um"}), mfp.WithOmit("email"), mfp.WithDepth(1))
+//
+// Options are reset by the next Columns call, so they never leak to other queries
+type ColumnsOption func(mp *ModelFieldsPrefixer)
+
+// WithJoins adds join models to the Columns call, they are matched like the join models passed to Columns directly
+func WithJoins(joinModels ...M) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.callJoins = append(mp.callJoins, joinModels...)
+	}
+}
+
+// WithOmit removes the columns from the built list like OmitIf does, columns are named as they are named
+// in the result set: 'email' for the root model and 'um.city' for nested ones
+func WithOmit(cols ...string) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.omit = append(mp.omit, cols...)
+	}
+}
+
+// WithDepth limits the number of relation levels expanded from the root model: 0 selects only the root model's columns,
+// 1 adds columns of its direct relations and so on
+func WithDepth(depth int) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.maxDepth = depth
+	}
+}
+
+// WithAliasSeparator changes the separator of scan aliases of nested models' columns, e.g. 'um__city' instead of
+// 'um.city' for mappers expecting it. Scan and Collect expect the default separator
+func WithAliasSeparator(separator string) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.aliasSeparator = separator
+	}
+}
+
+// WithRootAlias aliases columns of the root model with the alias of its table as well - 'u.id AS "u.id"', so all the
+// columns have aliases for scanners relying on them exclusively, e.g. generic map-based hydration. Scan and Collect
+// expect root columns without aliases
+func WithRootAlias() ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.rootAliasing = true
+	}
+}
+
+// WithoutAliases writes the columns without AS clauses - 'u.id, um.city', e.g. for subqueries, GROUP BY lists and
+// count queries where aliases are illegal or useless. Expressions (e.g. masked columns) are written without aliases
+// as well, and columns can't be scanned with Scan or Collect
+func WithoutAliases() ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.noAliases = true
+	}
+}
+
+// applyOmit removes the columns passed to WithOmit starting from the from-th column, i.e. the columns of the root
+// the options are passed with
+func (mp *ModelFieldsPrefixer) applyOmit(from int) {
+	if len(mp.omit) > 0 {
+		mp.omitColumns(from, mp.omit)
+	}
+}
+
+// resetColumnsOptions resets the state set by ColumnsOption values, so options of a Columns call (or a root of
+// ColumnsMulti) never apply to the next one
+func (mp *ModelFieldsPrefixer) resetColumnsOptions() {
+	mp.callOptions = callOptions{
+		callJoins:      mp.callJoins[:0],
+		omit:           mp.omit[:0],
+		maxDepth:       -1,
+		aliasSeparator: mp.defaultAliasSeparator,
+		groups:         mp.groups[:0],
+	}
+}
+
+// isDeeperThanMaxDepth returns true if relations of the model at namePath ('Author.Profile') exceed WithDepth limit
+func (mp *ModelFieldsPrefixer) isDeeperThanMaxDepth(namePath string) bool {
+	if mp.maxDepth < 0 {
+		return false
+	}
+
+	depth := 0
+	if namePath != "" {
+		depth = strings.Count(namePath, ".") + 1
+	}
+
+	return depth+1 > mp.maxDepth
+}
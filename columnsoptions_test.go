@@ -0,0 +1,63 @@
+package model_fields_prefixer_test
+
+import (
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestColumnsArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{"alias", []any{User{}, "u"}, `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"`},
+		{"table alias", []any{User{}}, `users.id, users.name, um.id AS "meta.id", um.city AS "meta.city"`},
+		{"table alias with option", []any{User{}, mfp.WithOmit("name")}, `users.id, um.id AS "meta.id", um.city AS "meta.city"`},
+		{"table alias with join model", []any{User{}, mfp.M{N: "Meta", A: "m"}}, `users.id, users.name, m.id AS "meta.id", m.city AS "meta.city"`},
+		{"depth", []any{User{}, "u", mfp.WithDepth(0)}, "u.id, u.name"},
+		{"joins", []any{User{}, "u", mfp.WithJoins(mfp.M{N: "Meta", A: "m"})}, `u.id, u.name, m.id AS "meta.id", m.city AS "meta.city"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := mfp.New().Columns(test.args...).String(); got != test.want {
+				t.Errorf("Columns() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestColumnsMultiOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		roots []mfp.Root
+		want  string
+	}{
+		{
+			name: "options of the first root",
+			roots: []mfp.Root{
+				{Model: User{}, Alias: "u", Options: []mfp.ColumnsOption{mfp.WithDepth(0), mfp.WithOmit("name")}},
+				{Model: UserMeta{}, Alias: "m"},
+			},
+			want: `u.id, m.id AS "id_2", m.city`,
+		},
+		{
+			name: "options of the second root",
+			roots: []mfp.Root{
+				{Model: UserMeta{}, Alias: "m"},
+				{Model: User{}, Alias: "u", Options: []mfp.ColumnsOption{mfp.WithDepth(0), mfp.WithOmit("city", "id_2")}},
+			},
+			want: `m.id, m.city, u.name`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := mfp.New().ColumnsMulti(test.roots...).String(); got != test.want {
+				t.Errorf("ColumnsMulti() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package model_fields_prefixer
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CommentFunc returns the tags of the query comment for the request, e.g. the route and the traceparent taken from
+// the context by a middleware
+type CommentFunc func(ctx context.Context) map[string]string
+
+// WithSQLCommenter makes InQuery append the comment of the tags returned by comment for the context passed to
+// WithContext (context.Background() without it) to the query in sqlcommenter format, e.g.
+// "/*app='svc',route='%2Fusers',traceparent='00-...'*/", so slow queries are attributed to code paths. Tags are sorted
+// by keys and their keys and values are URL encoded. Only the outermost query is commented, bodies of CTEs and
+// subqueries are not, and no tags (e.g. the comment func returns nil) mean no comment
+func WithSQLCommenter(comment CommentFunc) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.commentFunc = comment
+	}
+}
+
+// queryComment returns the sqlcommenter comment of the tags returned by the comment func, empty without it
+func (mp *ModelFieldsPrefixer) queryComment() string {
+	if mp.commentFunc == nil {
+		return ""
+	}
+
+	ctx := mp.queryCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return sqlComment(mp.commentFunc(ctx))
+}
+
+// commentQuery appends the comment to the query, before its terminating semicolon if it has one
+func commentQuery(query, comment string) string {
+	if comment == "" {
+		return query
+	}
+
+	trimmed := strings.TrimRight(query, " \t\n")
+	if statement, ok := strings.CutSuffix(trimmed, ";"); ok {
+		return statement + " " + comment + ";"
+	}
+
+	return trimmed + " " + comment
+}
+
+// sqlComment renders the tags as sqlcommenter comment, e.g. "/*app='svc',route='%2Fusers'*/", tags with empty values
+// are skipped
+func sqlComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, url.PathEscape(key)+"='"+url.PathEscape(tags[key])+"'")
+	}
+
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
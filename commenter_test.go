@@ -0,0 +1,101 @@
+package model_fields_prefixer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+type routeKey struct{}
+
+func TestWithSQLCommenter(t *testing.T) {
+	ctx := context.WithValue(context.Background(), routeKey{}, "/users/:id")
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		render func(m *mfp.ModelFieldsPrefixer) string
+		want   string
+	}{
+		{
+			name:   "query",
+			ctx:    ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string { return m.InQuery("SELECT {columns} FROM users u") },
+			want:   "SELECT u.id, u.name FROM users u /*app='billing',route='%2Fusers%2F:id'*/",
+		},
+		{
+			name:   "terminated query",
+			ctx:    ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string { return m.InQuery("SELECT {columns} FROM users u;\n") },
+			want:   "SELECT u.id, u.name FROM users u /*app='billing',route='%2Fusers%2F:id'*/;",
+		},
+		{
+			name: "query with optimizer hint",
+			ctx:  ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.InQuery("SELECT /*+ INDEX(u users_pkey) */ {columns} FROM users u")
+			},
+			want: "SELECT /*+ INDEX(u users_pkey) */ u.id, u.name FROM users u /*app='billing',route='%2Fusers%2F:id'*/",
+		},
+		{
+			name:   "empty tags",
+			ctx:    context.WithValue(context.Background(), routeKey{}, "-"),
+			render: func(m *mfp.ModelFieldsPrefixer) string { return m.InQuery("SELECT {columns} FROM users u") },
+			want:   "SELECT u.id, u.name FROM users u",
+		},
+		{
+			name: "cte",
+			ctx:  ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.WithCTE("cities", "SELECT {columns} FROM user_meta um", mfp.Root{Model: UserMeta{}, Alias: "um"}).
+					InQuery("SELECT {columns}, {cte:cities} FROM users u JOIN cities ON cities.id = u.id")
+			},
+			want: "WITH cities AS (SELECT um.id, um.city FROM user_meta um) SELECT u.id, u.name, cities.id, cities.city FROM users u JOIN cities ON cities.id = u.id /*app='billing',route='%2Fusers%2F:id'*/",
+		},
+		{
+			name: "subquery",
+			ctx:  ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.AsSubquery("sub").From("SELECT {columns} FROM users u").InQuery("SELECT {columns} FROM {subquery}")
+			},
+			want: "SELECT sub.id, sub.name FROM (SELECT u.id, u.name FROM users u) AS sub /*app='billing',route='%2Fusers%2F:id'*/",
+		},
+		{
+			name: "nested subquery",
+			ctx:  ctx,
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				sub := m.AsSubquery("sub").From("SELECT {columns} FROM users u")
+
+				return sub.AsSubquery("page").From("SELECT {columns} FROM {subquery}").InQuery("SELECT {columns} FROM {subquery}")
+			},
+			want: "SELECT page.id, page.name FROM (SELECT sub.id, sub.name FROM (SELECT u.id, u.name FROM users u) AS sub) AS page /*app='billing',route='%2Fusers%2F:id'*/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithSQLCommenter(func(ctx context.Context) map[string]string {
+				// bodies of CTEs are rendered without the context, so they would get the comment without the route
+				route, _ := ctx.Value(routeKey{}).(string)
+				if route == "-" {
+					return nil
+				}
+
+				return map[string]string{"app": "billing", "route": route, "empty": ""}
+			}))
+
+			m.Columns(User{}, "u", mfp.WithDepth(0)).WithContext(test.ctx)
+
+			query := test.render(m)
+			if query != test.want {
+				t.Errorf("InQuery() = %q, want %q", query, test.want)
+			}
+
+			if count := strings.Count(query, "/*app="); count > 1 {
+				t.Errorf("InQuery() has %d comments, want 1", count)
+			}
+		})
+	}
+}
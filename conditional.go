@@ -0,0 +1,86 @@
+package model_fields_prefixer
+
+// ColumnsIf adds the custom column only if cond is true, e.g. to add columns behind a feature flag
+// without branching around the chain
+func (mp *ModelFieldsPrefixer) ColumnsIf(cond bool, custom string) *ModelFieldsPrefixer {
+	if !cond {
+		return mp
+	}
+
+	return mp.CustomColumns(custom)
+}
+
+// OmitIf removes the built columns if cond is true, e.g. to hide columns the user has no permission for.
+// Columns are named as they are named in the result set: 'email' for the root model and 'um.city' for nested ones
+func (mp *ModelFieldsPrefixer) OmitIf(cond bool, cols ...string) *ModelFieldsPrefixer {
+	if !cond || len(cols) == 0 {
+		return mp
+	}
+
+	mp.omitColumns(0, cols)
+
+	return mp
+}
+
+// omitColumns removes the built columns starting from the from-th one which are named as cols
+func (mp *ModelFieldsPrefixer) omitColumns(from int, cols []string) {
+	omitted := make(map[string]struct{}, len(cols))
+	for _, col := range cols {
+		omitted[col] = struct{}{}
+	}
+
+	mp.removeColumns(func(i int, column columnInfo) bool {
+		if i < from || column.field == nil {
+			return false
+		}
+
+		name := column.scanAlias
+		if name == "" {
+			name = column.field.DBTag
+		}
+
+		_, ok := omitted[name]
+
+		return ok
+	})
+}
+
+// removeColumns rewrites the buffer without the columns remove returns true for, i is the index of the column
+func (mp *ModelFieldsPrefixer) removeColumns(remove func(i int, column columnInfo) bool) {
+	buf := mp.bytesBuffer.Bytes()
+
+	exprs := make([]string, 0, len(mp.columns))
+	columns := mp.columns[:0]
+
+	for i, column := range mp.columns {
+		if remove(i, column) {
+			// placeholders of the following columns are numbered already, so parameters can't be removed
+			if column.args == 0 {
+				continue
+			}
+
+			mp.warn("column with bind parameters can't be removed", "column", column.scanAlias)
+		}
+
+		exprs = append(exprs, string(buf[column.start:column.end]))
+		columns = append(columns, column)
+	}
+
+	mp.bytesBuffer.Reset()
+	mp.rendered = nil
+	mp.scanAliasesReady = false
+	mp.aliases = mp.aliases[:0]
+
+	for i := range columns {
+		columns[i].start = mp.bytesBuffer.Len()
+		mp.bytesBuffer.WriteString(exprs[i])
+		columns[i].end = mp.bytesBuffer.Len()
+		mp.bytesBuffer.WriteString(", ")
+
+		if columns[i].dbAlias != "" {
+			mp.addAlias(columns[i].dbAlias)
+		}
+	}
+
+	mp.columns = columns
+}
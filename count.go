@@ -0,0 +1,86 @@
+package model_fields_prefixer
+
+// RelationCount describes the relation counted by the column marked with 'count' tag option,
+// e.g. `db:"comments_count,count=Comments"`
+type RelationCount struct {
+	// Relation is the name of the relation's field, e.g. 'Comments'
+	Relation string
+	// Table is the table of the relation's model declared by its TableName method or its snake cased name
+	Table string
+	// ForeignKey is the column of the relation's table referencing Key column of the model, e.g. 'post_id'
+	ForeignKey string
+	Key        string
+}
+
+// resolveCounts resolves relations of the model's fields marked with 'count' tag option, fields counting unknown
+// relations are reported with a warning
+func (mp *ModelFieldsPrefixer) resolveCounts(modelInfo *ModelInfo, counts map[*FieldInfo]string) {
+	for _, field := range ResolveCounts(modelInfo, counts) {
+		mp.warn("count tag option refers to unknown relation, the field is a column",
+			"model", modelInfo.Name, "field", field.Name, "relation", counts[field])
+	}
+}
+
+// ResolveCounts resolves relations of the model's fields marked with 'count' tag option (the values of counts are the
+// names of the relations' fields): the relation's table, its foreign key set with 'fk' tag option of the relation
+// (the snake cased model's name followed by '_id' by default) and the model's primary key ('id' by default).
+// Fields counting unknown relations are columns, they are returned
+func ResolveCounts(modelInfo *ModelInfo, counts map[*FieldInfo]string) []*FieldInfo {
+	key := "id"
+	for _, field := range modelInfo.Fields {
+		if field.IsPK {
+			key = field.DBTag
+
+			break
+		}
+	}
+
+	var unknown []*FieldInfo
+
+	for field, relationName := range counts {
+		var relation *FieldInfo
+		for _, f := range modelInfo.Fields {
+			if f.Name == relationName && f.IsStruct && f.ModelInfo != nil {
+				relation = f
+
+				break
+			}
+		}
+
+		if relation == nil {
+			unknown = append(unknown, field)
+
+			continue
+		}
+
+		table := relation.ModelInfo.Table
+		if table == "" {
+			table = SnakeCase(relation.ModelInfo.Name)
+		}
+
+		foreignKey := relation.ForeignKey
+		if foreignKey == "" {
+			foreignKey = SnakeCase(modelInfo.Name) + "_id"
+		}
+
+		field.Count = &RelationCount{Relation: relationName, Table: table, ForeignKey: foreignKey, Key: key}
+		field.IsReadOnly = true
+	}
+
+	return unknown
+}
+
+// countExpr returns the correlated subquery counting rows of the relation of the model's table with the alias -
+// '(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id)'
+func (mp *ModelFieldsPrefixer) countExpr(count *RelationCount, dbAlias string) string {
+	// the alias of the relation's table must not shadow the alias of the model's one
+	alias := AliasInitials(count.Table)
+	if alias == dbAlias || alias == "" {
+		alias += "2"
+	}
+
+	alias = mp.quoteTableAlias(alias)
+
+	return "(SELECT COUNT(*) FROM " + mp.renderTable(count.Table) + " " + alias + " WHERE " +
+		alias + "." + mp.quoteColumn(count.ForeignKey) + " = " + mp.quoteTableAlias(dbAlias) + "." + mp.quoteColumn(count.Key) + ")"
+}
@@ -0,0 +1,78 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// cte is the common table expression declared by WithCTE
+type cte struct {
+	name string
+	// query is the rendered query of the CTE and columns are the columns of its result set prefixed with its name
+	query   string
+	columns string
+}
+
+// WithCTE declares the common table expression of the query: InQuery prepends 'WITH name AS (query)' to the query,
+// where the placeholders of query are replaced as in InQuery with the columns of model, and replaces {cte:name} placeholders with the columns of the
+// CTE's result set prefixed with its name, e.g. 'recent.id, recent."um.city"'. Pass Root as the model to set the
+// alias of its table and its join models, other models are aliased with their tables (see TableName). Models without
+// alias and table fail with prefixererr.ErrNoAlias and the CTE isn't declared. Bind parameters of the CTE follow the
+// ones captured before the call:
+//
+//	mp.Columns(User{}, "u").
+//		WithCTE("recent", "SELECT {columns} FROM orders o WHERE o.paid", mfp.Root{Model: Order{}, Alias: "o"}).
+//		InQuery("SELECT {columns}, {cte:recent} FROM users u JOIN recent ON recent.user_id = u.id")
+func (mp *ModelFieldsPrefixer) WithCTE(name, query string, model any) *ModelFieldsPrefixer {
+	p := mp.Acquire()
+	defer p.Release()
+
+	if root, ok := model.(Root); ok {
+		p.ColumnsMulti(root)
+	} else {
+		p.Columns(model)
+	}
+
+	if p.rootModel != nil && p.rootAlias == "" {
+		mp.fail(fmt.Errorf("%w: %s of cte %s", prefixererr.ErrNoAlias, p.rootModel.Name, name), "cte", name)
+
+		return mp
+	}
+
+	// the comment is appended to the outer query only
+	rendered := p.renderQuery(query, false)
+
+	// placeholders of the CTE's columns follow the parameters captured before, the query's own ones are kept as is
+	if offset := len(mp.args); offset > 0 && len(p.args) > 0 {
+		columns := p.String()
+		rendered = strings.Replace(rendered, columns, mp.renumberPlaceholders(columns, offset), 1)
+	}
+
+	mp.ctes = append(mp.ctes, cte{name: name, query: rendered, columns: p.AsSubquery(name).String()})
+
+	mp.args = append(mp.args, p.args...)
+
+	if err := p.Err(); err != nil {
+		mp.errs = append(mp.errs, err)
+	}
+
+	return mp
+}
+
+// withCTEs replaces {cte:name} placeholders of the rendered query and prepends WITH clause of the CTEs to it
+func withCTEs(ctes []cte, query string) string {
+	if len(ctes) == 0 {
+		return query
+	}
+
+	definitions := make([]string, 0, len(ctes))
+
+	for _, cte := range ctes {
+		query = strings.ReplaceAll(query, "{cte:"+cte.name+"}", cte.columns)
+		definitions = append(definitions, cte.name+" AS ("+cte.query+")")
+	}
+
+	return "WITH " + strings.Join(definitions, ", ") + " " + query
+}
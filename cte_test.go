@@ -0,0 +1,73 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Visit struct {
+	UserID int64  `db:"user_id"`
+	Page   string `db:"page"`
+}
+
+func TestWithCTE(t *testing.T) {
+	tests := []struct {
+		name  string
+		model any
+		cte   string
+		want  string
+		err   error
+	}{
+		{
+			name:  "root",
+			model: mfp.Root{Model: Visit{}, Alias: "v"},
+			cte:   "SELECT {columns} FROM visits v",
+			want:  "WITH recent AS (SELECT v.user_id, v.page FROM visits v) SELECT u.id, recent.user_id, recent.page FROM users u JOIN recent ON recent.user_id = u.id",
+		},
+		{
+			name:  "root with join models",
+			model: mfp.Root{Model: User{}, Alias: "u2", Joins: []mfp.M{{N: "Meta", A: "m2"}}},
+			cte:   "SELECT {columns} FROM users u2 JOIN user_meta m2 ON m2.id = u2.id",
+			want:  `WITH recent AS (SELECT u2.id, u2.name, m2.id AS "meta.id", m2.city AS "meta.city" FROM users u2 JOIN user_meta m2 ON m2.id = u2.id) SELECT u.id, recent.id, recent.name, recent."meta.id", recent."meta.city" FROM users u JOIN recent ON recent.user_id = u.id`,
+		},
+		{
+			name:  "model with table",
+			model: UserMeta{},
+			cte:   "SELECT {columns} FROM user_meta",
+			want:  "WITH recent AS (SELECT user_meta.id, user_meta.city FROM user_meta) SELECT u.id, recent.id, recent.city FROM users u JOIN recent ON recent.user_id = u.id",
+		},
+		{
+			name:  "model without alias",
+			model: Visit{},
+			cte:   "SELECT {columns} FROM visits",
+			want:  "SELECT u.id, {cte:recent} FROM users u JOIN recent ON recent.user_id = u.id",
+			err:   prefixererr.ErrNoAlias,
+		},
+		{
+			name:  "root without alias",
+			model: mfp.Root{Model: Visit{}},
+			cte:   "SELECT {columns} FROM visits",
+			want:  "SELECT u.id, {cte:recent} FROM users u JOIN recent ON recent.user_id = u.id",
+			err:   prefixererr.ErrNoAlias,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.Strict())
+
+			query := m.Columns(User{}, "u", mfp.WithDepth(0), mfp.WithOmit("name")).WithCTE("recent", test.cte, test.model).
+				InQuery("SELECT {columns}, {cte:recent} FROM users u JOIN recent ON recent.user_id = u.id")
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Err() = %v, want %v", err, test.err)
+			}
+
+			if query != test.want {
+				t.Errorf("InQuery() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
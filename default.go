@@ -0,0 +1,21 @@
+package model_fields_prefixer
+
+// Default is the prefixer used by the package level functions. Replace it at startup to change the options,
+// e.g. mfp.Default = mfp.New(mfp.WithDialect(mfp.DialectMySQL))
+var Default = New()
+
+// Columns builds the columns with Default prefixer and returns them as Result. It is safe for concurrent use,
+// so simple applications don't need to pass a prefixer around:
+//
+//	query := mfp.Columns(User{}, "u", mfp.M{N: "UserMeta", A: "um"}).InQuery("SELECT {columns} FROM users u ...")
+func Columns(args ...any) Result {
+	return Default.Build(args...)
+}
+
+// InQuery builds the columns with Default prefixer and replaces placeholders of the query with them, args are
+// the ones passed to Columns:
+//
+//	query := mfp.InQuery("SELECT {columns} FROM users u", User{}, "u")
+func InQuery(query string, args ...any) string {
+	return Columns(args...).InQuery(query)
+}
@@ -0,0 +1,45 @@
+package model_fields_prefixer_test
+
+import (
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestInQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		{
+			name:  "columns",
+			query: "SELECT {columns} FROM users u",
+			args:  []any{User{}, "u"},
+		},
+		{
+			name:  "join models",
+			query: "SELECT {columns} FROM users u JOIN user_meta x ON x.id = u.id",
+			args:  []any{User{}, "u", mfp.M{N: "Meta", A: "x"}},
+		},
+		{
+			name:  "tables",
+			query: "SELECT {columns} FROM {table} u JOIN {table:UserMeta} um ON um.id = u.id",
+			args:  []any{User{}, "u"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mfp.New().Columns(tt.args...).InQuery(tt.query)
+
+			if got := mfp.InQuery(tt.query, tt.args...); got != want {
+				t.Errorf("InQuery() = %q, want %q", got, want)
+			}
+
+			if got := mfp.Columns(tt.args...).InQuery(tt.query); got != want {
+				t.Errorf("Columns().InQuery() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package model_fields_prefixer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes SQL syntax differences of databases which affect built columns
+type Dialect interface {
+	// Name is the name of the dialect, e.g. 'postgres'
+	Name() string
+	// QuoteIdent quotes the identifier, e.g. scan alias 'um.city' is quoted as '"um.city"'
+	QuoteIdent(ident string) string
+	// IsReserved returns true if the identifier is a reserved word which must be quoted to be used as a column name, e.g. 'order'
+	IsReserved(ident string) bool
+	// Placeholder returns the placeholder of the n-th (starting from 1) bind parameter, e.g. '$1' or '?'
+	Placeholder(n int) string
+}
+
+var (
+	// DialectPostgres quotes identifiers with double quotes, it is the default dialect. Identifiers are limited to 63 bytes
+	DialectPostgres Dialect = newQuoteDialect("postgres", `"`, `"`, "$", postgresReservedWords).withMaxIdentifierLength(63)
+	// DialectMySQL quotes identifiers with backticks, aliases are limited to 256 characters
+	DialectMySQL Dialect = newQuoteDialect("mysql", "`", "`", "?", mysqlReservedWords).withMaxIdentifierLength(256)
+	// DialectSQLite quotes identifiers with double quotes
+	DialectSQLite Dialect = newQuoteDialect("sqlite", `"`, `"`, "?", sqliteReservedWords)
+	// DialectMSSQL quotes identifiers with square brackets, identifiers are limited to 128 characters and Paginate
+	// uses 'OFFSET n ROWS FETCH NEXT m ROWS ONLY'
+	DialectMSSQL Dialect = mssqlDialect{newQuoteDialect("mssql", "[", "]", "@p", mssqlReservedWords).withMaxIdentifierLength(128)}
+	// DialectOracle quotes identifiers with double quotes and writes column aliases without AS keyword, placeholders
+	// are ':1' and Paginate uses 'FETCH FIRST n ROWS ONLY'. Identifiers are limited to 128 bytes (Oracle 12.2+)
+	DialectOracle Dialect = oracleDialect{newQuoteDialect("oracle", `"`, `"`, ":", oracleReservedWords).withMaxIdentifierLength(128)}
+)
+
+// AliasDialect is implemented by dialects writing column aliases with their own keyword, dialects which don't
+// implement it write 'AS'
+type AliasDialect interface {
+	// AliasKeyword returns the keyword between the column and its alias, empty if there is none, e.g. in Oracle
+	AliasKeyword() string
+}
+
+// quoteDialect is the dialect which differs only by quotes, placeholders and reserved words
+type quoteDialect struct {
+	name        string
+	open, close string
+	// placeholder is '?' for unnumbered placeholders or the prefix of numbered ones, e.g. '$'
+	placeholder   string
+	reservedWords map[string]struct{}
+	// maxIdentifierLength is the limit of identifiers in bytes, 0 if they aren't limited
+	maxIdentifierLength int
+}
+
+func newQuoteDialect(name, open, close, placeholder, reservedWords string) quoteDialect {
+	d := quoteDialect{
+		name:          name,
+		open:          open,
+		close:         close,
+		placeholder:   placeholder,
+		reservedWords: make(map[string]struct{}),
+	}
+
+	for _, word := range strings.Fields(reservedWords) {
+		d.reservedWords[word] = struct{}{}
+	}
+
+	return d
+}
+
+func (d quoteDialect) withMaxIdentifierLength(length int) quoteDialect {
+	d.maxIdentifierLength = length
+
+	return d
+}
+
+func (d quoteDialect) Name() string {
+	return d.name
+}
+
+func (d quoteDialect) QuoteIdent(ident string) string {
+	// closing quotes inside the identifier are escaped by doubling them
+	return d.open + strings.ReplaceAll(ident, d.close, d.close+d.close) + d.close
+}
+
+func (d quoteDialect) IsReserved(ident string) bool {
+	_, ok := d.reservedWords[strings.ToLower(ident)]
+
+	return ok
+}
+
+func (d quoteDialect) Placeholder(n int) string {
+	if d.placeholder == "?" {
+		return d.placeholder
+	}
+
+	return d.placeholder + strconv.Itoa(n)
+}
+
+// oracleDialect differs from the quote dialects by column aliases and pagination
+type oracleDialect struct {
+	quoteDialect
+}
+
+func (d oracleDialect) AliasKeyword() string {
+	return ""
+}
+
+func (d oracleDialect) Pagination(limit, offset int) string {
+	if offset == 0 {
+		return "FETCH FIRST " + strconv.Itoa(limit) + " ROWS ONLY"
+	}
+
+	return "OFFSET " + strconv.Itoa(offset) + " ROWS FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY"
+}
+
+// mssqlDialect differs from the quote dialects by pagination
+type mssqlDialect struct {
+	quoteDialect
+}
+
+// Pagination renders OFFSET even for the first page, MSSQL has no FETCH without it
+func (d mssqlDialect) Pagination(limit, offset int) string {
+	return "OFFSET " + strconv.Itoa(offset) + " ROWS FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY"
+}
+
+// WithQuoteAll makes the prefixer quote all the table aliases and column names, e.g. '"u"."id"',
+// by default only reserved words are quoted, e.g. 'u."order"'
+func WithQuoteAll(quoteAll bool) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.quoteAll = quoteAll
+	}
+}
+
+// quoteColumn returns the column name quoted by the dialect if it is a reserved word or all identifiers are quoted
+func (mp *ModelFieldsPrefixer) quoteColumn(column string) string {
+	if mp.quoteAll || mp.dialect.IsReserved(column) {
+		return mp.dialect.QuoteIdent(column)
+	}
+
+	return column
+}
+
+func (mp *ModelFieldsPrefixer) quoteTableAliasPath(path string) string {
+	column, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return mp.quoteColumn(column)
+	}
+
+	return mp.quoteColumn(column) + "." + mp.quoteTableAliasPath(rest)
+}
+
+// Dialect returns the dialect of the prefixer set with WithDialect
+func (mp *ModelFieldsPrefixer) Dialect() Dialect {
+	return mp.dialect
+}
+
+// writeAlias writes the alias of the column quoted by the dialect - ' AS "um.city"'
+func (mp *ModelFieldsPrefixer) writeAlias(alias string) {
+	keyword := mp.aliasKeyword()
+
+	mp.bytesBuffer.WriteString(" ")
+
+	if keyword != "" {
+		mp.bytesBuffer.WriteString(keyword)
+		mp.bytesBuffer.WriteString(" ")
+	}
+
+	quotedAlias := mp.dialect.QuoteIdent(alias)
+
+	_, err := mp.bytesBuffer.WriteString(quotedAlias)
+	mp.handleBuilderErr(err, quotedAlias)
+}
+
+// aliasKeyword returns the keyword preceding aliases of columns, 'AS' if the dialect doesn't override it
+func (mp *ModelFieldsPrefixer) aliasKeyword() string {
+	if d, ok := mp.dialect.(AliasDialect); ok {
+		return d.AliasKeyword()
+	}
+
+	return "AS"
+}
+
+// aliasSuffix returns what writeAlias writes for the alias
+func (mp *ModelFieldsPrefixer) aliasSuffix(alias string) string {
+	if keyword := mp.aliasKeyword(); keyword != "" {
+		return " " + keyword + " " + mp.dialect.QuoteIdent(alias)
+	}
+
+	return " " + mp.dialect.QuoteIdent(alias)
+}
+
+// quoteTableAlias returns the alias of the table quoted by the dialect if all identifiers are quoted, columns of paths
+// of STRUCT columns' fields are quoted like columns - 'u.address'
+func (mp *ModelFieldsPrefixer) quoteTableAlias(alias string) string {
+	if table, column, ok := strings.Cut(alias, "."); ok {
+		return mp.quoteTableAlias(table) + "." + mp.quoteTableAliasPath(column)
+	}
+
+	if mp.quoteAll && alias != "" {
+		return mp.dialect.QuoteIdent(alias)
+	}
+
+	return alias
+}
+
+const postgresReservedWords = `all analyse analyze and any array as asc asymmetric authorization binary both case cast check
+collate collation column concurrently constraint create cross current_catalog current_date current_role current_schema
+current_time current_timestamp current_user default deferrable desc distinct do else end except false fetch for foreign
+freeze from full grant group having ilike in initially inner intersect into is isnull join lateral leading left like limit
+localtime localtimestamp natural not notnull null offset on only or order outer overlaps placing primary references
+returning right select session_user similar some symmetric system_user table tablesample then to trailing true union
+unique user using variadic verbose when where window with`
+
+const mysqlReservedWords = `accessible add all alter analyze and as asc asensitive before between bigint binary blob both by
+call cascade case change char character check collate column condition constraint continue convert create cross cube
+cume_dist current_date current_time current_timestamp current_user cursor database databases day_hour day_microsecond
+day_minute day_second dec decimal declare default delayed delete dense_rank desc describe deterministic distinct
+distinctrow div double drop dual each else elseif empty enclosed escaped except exists exit explain false fetch
+first_value float float4 float8 for force foreign from fulltext function generated get grant group grouping groups
+having high_priority hour_microsecond hour_minute hour_second if ignore in index infile inner inout insensitive insert
+int int1 int2 int3 int4 int8 integer intersect interval into io_after_gtids io_before_gtids is iterate join json_table
+key keys kill lag last_value lateral lead leading leave left like limit linear lines load localtime localtimestamp lock
+long longblob longtext loop low_priority master_bind master_ssl_verify_server_cert match maxvalue mediumblob mediumint
+mediumtext middleint minute_microsecond minute_second mod modifies natural not no_write_to_binlog nth_value ntile null
+numeric of on optimize optimizer_costs option optionally or order out outer outfile over partition percent_rank
+precision primary procedure purge range rank read reads read_write real recursive references regexp release rename
+repeat replace require resignal restrict return revoke right rlike row rows row_number schema schemas second_microsecond
+select sensitive separator set show signal smallint spatial specific sql sqlexception sqlstate sqlwarning
+sql_big_result sql_calc_found_rows sql_small_result ssl starting stored straight_join system table terminated then
+tinyblob tinyint tinytext to trailing trigger true undo union unique unlock unsigned update usage use using utc_date
+utc_time utc_timestamp values varbinary varchar varcharacter varying virtual when where while window with write xor
+year_month zerofill`
+
+const sqliteReservedWords = `abort action add after all alter always analyze and as asc attach autoincrement before begin
+between by cascade case cast check collate column commit conflict constraint create cross current current_date
+current_time current_timestamp database default deferrable deferred delete desc detach distinct do drop each else end
+escape except exclude exclusive exists explain fail filter first following for foreign from full generated glob group
+groups having if ignore immediate in index indexed initially inner insert instead intersect into is isnull join key last
+left like limit match materialized natural no not nothing notnull null nulls of offset on or order others outer over
+partition plan pragma preceding primary query raise range recursive references regexp reindex release rename replace
+restrict returning right rollback row rows savepoint select set table temp temporary then ties to transaction trigger
+unbounded union unique update using vacuum values view virtual when where window with without`
+
+const mssqlReservedWords = `add all alter and any as asc authorization backup begin between break browse bulk by cascade
+case check checkpoint close clustered coalesce collate column commit compute constraint contains containstable continue
+convert create cross current current_date current_time current_timestamp current_user cursor database dbcc deallocate
+declare default delete deny desc disk distinct distributed double drop dump else end errlvl escape except exec execute
+exists exit external fetch file fillfactor for foreign freetext freetexttable from full function goto grant group having
+holdlock identity identity_insert identitycol if in index inner insert intersect into is join key kill left like lineno
+load merge national nocheck nonclustered not null nullif of off offsets on open opendatasource openquery openrowset
+openxml option or order outer over percent pivot plan precision primary print proc procedure public raiserror read
+readtext reconfigure references replication restore restrict return revert revoke right rollback rowcount rowguidcol
+rule save schema securityaudit select semantickeyphrasetable semanticsimilaritydetailstable semanticsimilaritytable
+session_user set setuser shutdown some statistics system_user table tablesample textsize then to top tran transaction
+trigger truncate try_convert tsequal union unique unpivot update updatetext use user values varying view waitfor when
+where while with within writetext`
+
+const oracleReservedWords = `access add all alter and any as asc audit between by char check cluster column comment compress
+connect create current date decimal default delete desc distinct drop else exclusive exists file float for from grant
+group having identified immediate in increment index initial insert integer intersect into is level like lock long
+maxextents minus mlslabel mode modify noaudit nocompress not nowait null number of offline on online option or order
+pctfree prior public raw rename resource revoke row rowid rownum rows select session set share size smallint start
+successful synonym sysdate table then to trigger uid union unique update user validate values varchar varchar2 view
+whenever where with`
@@ -0,0 +1,118 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Ticket struct {
+	ID      int64        `db:"id,pk"`
+	Comment string       `db:"comment"`
+	Open    bool         `db:"open"`
+	Stats   *TicketStats `db:"stats"`
+}
+
+func (Ticket) TableName() string {
+	return "tickets"
+}
+
+type TicketStats struct {
+	Views  int64 `db:"views"`
+	Closed bool  `db:"closed"`
+}
+
+type TicketFilter struct {
+	IDs  []int64 `db:"id,omitempty"`
+	Open *bool   `db:"open"`
+}
+
+func TestDialectOracle(t *testing.T) {
+	open := true
+
+	tests := []struct {
+		name   string
+		render func(m *mfp.ModelFieldsPrefixer) string
+		want   string
+		args   []any
+		err    error
+	}{
+		{
+			name: "aliases without AS",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(User{}, "u").InQuery("SELECT {columns} FROM users u")
+			},
+			want: `SELECT u.id, u.name, um.id "meta.id", um.city "meta.city" FROM users u`,
+		},
+		{
+			name: "reserved words",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Ticket{}, "t", mfp.WithDepth(0)).InQuery("SELECT {columns} FROM {table} t")
+			},
+			want: `SELECT t.id, t."comment", t.open FROM tickets t`,
+		},
+		{
+			name: "numbered placeholders",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Ticket{}, "t", mfp.WithDepth(0)).Where(TicketFilter{IDs: []int64{1, 2}, Open: &open}).
+					InQuery("SELECT {columns} FROM tickets t {where}")
+			},
+			want: `SELECT t.id, t."comment", t.open FROM tickets t WHERE t.id IN (:1, :2) AND t.open = :3`,
+			args: []any{int64(1), int64(2), true},
+		},
+		{
+			name: "lateral join with coalesced booleans",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Ticket{}, "t", mfp.M{N: "Stats", A: "s", F: "ticket_stats(t.id)", C: true}).
+					InQuery("SELECT {columns} FROM tickets t {joins}")
+			},
+			want: `SELECT t.id, t."comment", t.open, COALESCE(s.views, 0) "stats.views", COALESCE(s.closed, 0) "stats.closed" FROM tickets t OUTER APPLY ticket_stats(t.id) s`,
+		},
+		{
+			name: "keyset pagination",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Ticket{}, "t", mfp.WithDepth(0)).PaginateAfter(10, int64(5)).
+					InQuery("SELECT {columns} FROM tickets t WHERE {keyset} {pagination}")
+			},
+			want: `SELECT t.id, t."comment", t.open FROM tickets t WHERE t.id > :1 ORDER BY t.id FETCH FIRST 10 ROWS ONLY`,
+			args: []any{int64(5)},
+		},
+		{
+			name: "long aliases",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(User{}, "u", mfp.M{N: "Meta", A: "m"}, mfp.WithAliasSeparator(strings.Repeat("_", 130))).String()
+			},
+			want: `u.id, u.name, m.id "meta` + strings.Repeat("_", 115) + `_6f9bd373", m.city "meta` + strings.Repeat("_", 115) + `_71298a89"`,
+		},
+		{
+			name: "shared lock",
+			render: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Ticket{}, "t", mfp.WithDepth(0)).Locking(mfp.ForShare).InQuery("SELECT {columns} FROM tickets t {locking}")
+			},
+			want: `SELECT t.id, t."comment", t.open FROM tickets t `,
+			err:  prefixererr.ErrUnsupportedLocking,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithDialect(mfp.DialectOracle), mfp.Strict())
+
+			if got := test.render(m); got != test.want {
+				t.Errorf("InQuery() = %q, want %q", got, test.want)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+
+			if args := m.Args(); !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Args() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
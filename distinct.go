@@ -0,0 +1,35 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// Distinct makes InQuery prepend DISTINCT to the columns list
+func (mp *ModelFieldsPrefixer) Distinct() *ModelFieldsPrefixer {
+	mp.distinct = "DISTINCT "
+
+	return mp
+}
+
+// DistinctOn makes InQuery prepend PostgreSQL DISTINCT ON (cols) to the columns list.
+// Columns without alias are prefixed with the alias of the root model like in OrderBy
+func (mp *ModelFieldsPrefixer) DistinctOn(cols ...string) *ModelFieldsPrefixer {
+	if len(cols) == 0 {
+		return mp.Distinct()
+	}
+
+	columns := make([]string, 0, len(cols))
+
+	for _, col := range cols {
+		col = strings.TrimSpace(col)
+		if !strings.Contains(col, ".") && mp.rootAlias != "" {
+			col = mp.rootAlias + "." + col
+		}
+
+		columns = append(columns, col)
+	}
+
+	mp.distinct = "DISTINCT ON (" + strings.Join(columns, ", ") + ") "
+
+	return mp
+}
@@ -0,0 +1,37 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+const (
+	encryptedColumnPlaceholder = "{column}"
+	encryptionKeyPlaceholder   = "{key}"
+)
+
+// WithEncryption makes columns marked with 'encrypted' tag option be selected as the decrypting expression,
+// e.g. 'pgp_sym_decrypt({column}, {key})' is rendered as 'pgp_sym_decrypt(u.ssn, $1) AS "ssn"'. {column} is replaced
+// by the column and {key} by the placeholder of key bound as a parameter, so it is passed to the query with Args
+func WithEncryption(decrypt string, key any) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.decryptExpr = decrypt
+		mp.encryptionKey = key
+	}
+}
+
+// decrypt returns the expression decrypting the column binding the key for every {key} placeholder
+func (mp *ModelFieldsPrefixer) decrypt(column string) string {
+	parts := strings.Split(strings.ReplaceAll(mp.decryptExpr, encryptedColumnPlaceholder, column), encryptionKeyPlaceholder)
+
+	var sb strings.Builder
+	sb.WriteString(parts[0])
+
+	for _, part := range parts[1:] {
+		mp.args = append(mp.args, mp.encryptionKey)
+
+		sb.WriteString(mp.dialect.Placeholder(len(mp.args)))
+		sb.WriteString(part)
+	}
+
+	return sb.String()
+}
@@ -1,3 +1,3 @@
 module github.com/ivnku/model-fields-prefixer
 
-go 1.18
+go 1.21
@@ -0,0 +1,75 @@
+package model_fields_prefixer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// customAliasRegexp matches the alias of the custom column, e.g. ' AS is_admin' or ' as "is_admin"'
+var customAliasRegexp = regexp.MustCompile("(?i)\\s+AS\\s+(\\w+|\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\])\\s*$")
+
+// AggregateColumns works as CustomColumns but marks the columns as aggregates, e.g. 'COUNT(o.id) AS orders_count',
+// so GroupBy doesn't repeat them in GROUP BY clause
+func (mp *ModelFieldsPrefixer) AggregateColumns(custom string) *ModelFieldsPrefixer {
+	mp.CustomColumns(custom)
+
+	mp.columns[len(mp.columns)-1].aggregate = true
+
+	return mp
+}
+
+// GroupBy makes InQuery replace {groupby} placeholder with GROUP BY clause listing all the built columns except
+// aggregate ones (see AggregateColumns) and window functions, since databases like Postgres require repeating them.
+// The clause is rendered from the columns built by the time of InQuery, so columns may be added after the call
+func (mp *ModelFieldsPrefixer) GroupBy() *ModelFieldsPrefixer {
+	mp.groupBy = true
+
+	return mp
+}
+
+// groupByClause renders GROUP BY clause of the built columns if GroupBy is called, empty string otherwise
+func (mp *ModelFieldsPrefixer) groupByClause() string {
+	if !mp.groupBy {
+		return ""
+	}
+
+	items := make([]string, 0, len(mp.columns))
+
+	for _, column := range mp.columns {
+		if column.aggregate {
+			continue
+		}
+
+		if item := mp.groupingColumn(column); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return ""
+	}
+
+	return "GROUP BY " + strings.Join(items, ", ")
+}
+
+// groupingColumn renders the column for GROUP BY without its alias. Columns of fields are grouped by the table's
+// columns, so expressions over them (e.g. masked or coalesced ones) stay valid and their bind parameters aren't
+// repeated. Relation counts are skipped: they depend on the grouped key columns only
+func (mp *ModelFieldsPrefixer) groupingColumn(column columnInfo) string {
+	expr := string(mp.bytesBuffer.Bytes()[column.start:column.end])
+
+	switch {
+	case column.field == nil:
+		return customAliasRegexp.ReplaceAllString(expr, "")
+	case column.field.Count != nil:
+		return ""
+	case column.field.IsJSON:
+		if column.scanAlias != "" {
+			return strings.TrimSuffix(expr, mp.aliasSuffix(column.scanAlias))
+		}
+
+		return expr
+	default:
+		return mp.sortableColumn(column.dbAlias, column.field)
+	}
+}
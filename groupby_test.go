@@ -0,0 +1,80 @@
+package model_fields_prefixer_test
+
+import (
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+type Author struct {
+	ID      int64       `db:"id,pk"`
+	Name    string      `db:"name"`
+	Profile *AuthorInfo `db:"profile,json"`
+}
+
+type AuthorInfo struct {
+	Bio string `json:"bio"`
+}
+
+func TestGroupBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(m *mfp.ModelFieldsPrefixer)
+		want  string
+	}{
+		{
+			name: "columns of the models",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(User{}, "u").AggregateColumns("COUNT(o.id) AS orders_count").GroupBy()
+			},
+			want: "GROUP BY u.id, u.name, um.id, um.city",
+		},
+		{
+			name: "custom columns without aliases",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(User{}, "u", mfp.WithDepth(0)).CustomColumns("LOWER(u.name) AS name_lower").GroupBy()
+			},
+			want: "GROUP BY u.id, u.name, LOWER(u.name)",
+		},
+		{
+			name: "columns added after the call",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(User{}, "u", mfp.WithDepth(0)).GroupBy().CustomColumns(`u.name || '!' AS "shout"`)
+			},
+			want: "GROUP BY u.id, u.name, u.name || '!'",
+		},
+		{
+			name: "window functions",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(User{}, "u", mfp.WithDepth(0)).WindowColumn(mfp.Window{Func: "RANK()", Alias: "rank"}).GroupBy()
+			},
+			want: "GROUP BY u.id, u.name",
+		},
+		{
+			name:  "json columns",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.Columns(Author{}, "a").GroupBy() },
+			want:  "GROUP BY a.id, a.name, a.profile->>'bio'",
+		},
+		{
+			name:  "only aggregates",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.AggregateColumns("COUNT(*) AS total").GroupBy() },
+			want:  "",
+		},
+		{
+			name:  "without the call",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.Columns(User{}, "u") },
+			want:  "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New()
+			test.build(m)
+
+			if got := m.InQuery("SELECT {columns} FROM users u {groupby}"); got != "SELECT "+m.String()+" FROM users u "+test.want {
+				t.Errorf("InQuery() = %q, want %q", got, "SELECT "+m.String()+" FROM users u "+test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package model_fields_prefixer
+
+import (
+	"sort"
+	"strings"
+)
+
+// flagTagOptions are tag options without values, they end the list of groups of 'groups' tag option
+var flagTagOptions = map[string]struct{}{
+	"pk":         {},
+	"readonly":   {},
+	"writeonly":  {},
+	"noscan":     {},
+	"json":       {},
+	"softdelete": {},
+	"audit":      {},
+	"masked":     {},
+	"encrypted":  {},
+	"struct":     {},
+}
+
+// WithGroups selects fields of the groups in addition to the fields without groups, e.g. fields tagged
+// `db:"email,groups=admin,internal"` are selected only if "admin" or "internal" group is passed.
+// So one model may have different views (public, admin) without parallel structs
+func WithGroups(groups ...string) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.groups = append(mp.groups, groups...)
+	}
+}
+
+// tagOptionGroups returns groups of 'groups' tag option which are separated by commas like the options themselves,
+// e.g. `db:"email,groups=admin,internal,readonly"`
+func tagOptionGroups(options []string) []string {
+	for i, o := range options {
+		key, value, ok := strings.Cut(strings.TrimSpace(o), "=")
+		if !ok || key != "groups" {
+			continue
+		}
+
+		groups := []string{value}
+
+		for _, next := range options[i+1:] {
+			next = strings.TrimSpace(next)
+
+			if _, ok := flagTagOptions[next]; ok || strings.Contains(next, "=") {
+				break
+			}
+
+			groups = append(groups, next)
+		}
+
+		return groups
+	}
+
+	return nil
+}
+
+// isInGroups reports whether the field is selected by the groups passed to WithGroups
+func (mp *ModelFieldsPrefixer) isInGroups(field *FieldInfo) bool {
+	if len(field.Groups) == 0 {
+		return true
+	}
+
+	for _, group := range field.Groups {
+		for _, selected := range mp.groups {
+			if group == selected {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// groupsKey returns the sorted groups passed to WithGroups to identify rendered columns
+func (mp *ModelFieldsPrefixer) groupsKey() string {
+	if len(mp.groups) == 0 {
+		return ""
+	}
+
+	groups := make([]string, len(mp.groups))
+	copy(groups, mp.groups)
+
+	sort.Strings(groups)
+
+	return strings.Join(groups, "\x00")
+}
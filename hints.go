@@ -0,0 +1,36 @@
+package model_fields_prefixer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hintPlaceholderRegexp matches placeholders of join models' table hints, e.g. '{hint:Orders}'
+var hintPlaceholderRegexp = regexp.MustCompile(`\{hint:([A-Za-z0-9_.]+)\}`)
+
+// setHints keeps table hints of the join models (M.H) to render them in '{hint:Name}' placeholders
+func (mp *ModelFieldsPrefixer) setHints(joinModels []M) {
+	for _, joinModel := range joinModels {
+		if joinModel.N == "" || joinModel.H == "" {
+			continue
+		}
+
+		if mp.hints == nil {
+			mp.hints = make(map[string]string)
+		}
+
+		mp.hints[joinModel.N] = joinModel.H
+	}
+}
+
+// renderHints replaces '{hint:Name}' placeholders of the query with the table hints of the join models,
+// placeholders of join models without hints are removed
+func renderHints(query string, hints map[string]string) string {
+	if !strings.Contains(query, "{hint:") {
+		return query
+	}
+
+	return hintPlaceholderRegexp.ReplaceAllStringFunc(query, func(placeholder string) string {
+		return hints[hintPlaceholderRegexp.FindStringSubmatch(placeholder)[1]]
+	})
+}
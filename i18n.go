@@ -0,0 +1,39 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// WithLocale selects localized columns of the locale, e.g. 'u.name_ru AS "name"' for the field tagged
+// `db:"name,i18n=en|ru|de"`. Fields which don't have the locale select the column of their first locale
+func WithLocale(locale string) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.locale = locale
+	}
+}
+
+// tagOptionLocales returns locales of 'i18n' tag option separated by '|', e.g. `db:"name,i18n=en|ru|de"`
+func tagOptionLocales(options []string) []string {
+	locales := tagOptionValue(options, "i18n")
+	if locales == "" {
+		return nil
+	}
+
+	return strings.Split(locales, "|")
+}
+
+// localizedColumn returns the name of the field's column in the table, it is the column of the locale passed to WithLocale
+// for localized fields, e.g. 'name_ru'
+func (mp *ModelFieldsPrefixer) localizedColumn(field *FieldInfo) string {
+	if len(field.Locales) == 0 {
+		return field.DBTag
+	}
+
+	for _, locale := range field.Locales {
+		if locale == mp.locale {
+			return field.DBTag + "_" + locale
+		}
+	}
+
+	return field.DBTag + "_" + field.Locales[0]
+}
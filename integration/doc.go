@@ -0,0 +1,7 @@
+// Package integration runs round trips of the prefixer against real databases started in docker containers:
+// fixtures are inserted with Values, read back with the columns built by Columns and compared with the original ones.
+//
+//	cd integration && go test -tags integration ./...
+//
+// Set POSTGRES_DSN or MYSQL_DSN to use already running databases instead of containers
+package integration
@@ -0,0 +1,20 @@
+module github.com/ivnku/model-fields-prefixer/integration
+
+go 1.25.0
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/go-sql-driver/mysql v1.10.1
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+	github.com/jackc/pgx/v5 v5.11.0
+)
+
+require (
+	filippo.io/edwards25519 v1.2.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
@@ -0,0 +1,73 @@
+//go:build integration
+
+package integration
+
+import (
+	"database/sql"
+	"time"
+)
+
+// the model zoo covers root columns, nested pointer and value models, slice relations and codecs
+
+type User struct {
+	ID       int64          `db:"id,pk"`
+	Name     string         `db:"name"`
+	Nickname sql.NullString `db:"nickname"`
+	Tags     []string       `db:"tags,codec=csv"`
+	Prefs    *Prefs         `db:"prefs,codec=json"`
+	Address  *Address       `db:"addr"`
+	Comments []Comment      `db:"comments"`
+}
+
+type Prefs struct {
+	Theme string `json:"theme"`
+}
+
+type Address struct {
+	ID           int64         `db:"id,pk"`
+	City         string        `db:"city"`
+	LocationMeta *LocationMeta `db:"lm"`
+}
+
+type LocationMeta struct {
+	ID        int64     `db:"id,pk"`
+	ExtraData string    `db:"extra_data"`
+	CheckedAt time.Time `db:"checked_at,codec=unixtime"`
+}
+
+type Comment struct {
+	ID   int64  `db:"id,pk"`
+	Text string `db:"text"`
+}
+
+var fixtures = []User{
+	{
+		ID:       1,
+		Name:     "alice",
+		Nickname: sql.NullString{String: "al", Valid: true},
+		Tags:     []string{"admin", "a,b"},
+		Prefs:    &Prefs{Theme: "dark"},
+		Address: &Address{
+			ID:   10,
+			City: "Berlin",
+			LocationMeta: &LocationMeta{
+				ID:        100,
+				ExtraData: "meta",
+				CheckedAt: time.Unix(1700000000, 0),
+			},
+		},
+		Comments: []Comment{{ID: 1000, Text: "first"}, {ID: 1001, Text: "second"}},
+	},
+	{
+		ID:       2,
+		Name:     "bob",
+		Tags:     []string{},
+		Comments: []Comment{},
+	},
+}
+
+// UserFilter filters users by their own and their addresses' columns
+type UserFilter struct {
+	Name string `db:"name,omitempty"`
+	City string `db:"addr.city,omitempty"`
+}
@@ -0,0 +1,88 @@
+//go:build integration
+
+package integration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// TestRendering checks the queries the round trips run with, so failures of the round trips are told apart from
+// changes of rendering
+func TestRendering(t *testing.T) {
+	const columns = "u.id, u.name, u.nickname, u.tags, u.prefs, addr.id AS {q}addr.id{q}, addr.city AS {q}addr.city{q}, " +
+		"lm.id AS {q}addr.lm.id{q}, lm.extra_data AS {q}addr.lm.extra_data{q}, lm.checked_at AS {q}addr.lm.checked_at{q}"
+
+	tests := []struct {
+		name     string
+		dialect  mfp.Dialect
+		build    func(m *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer
+		query    string
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:    "postgres where and pagination",
+			dialect: mfp.DialectPostgres,
+			build: func(m *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return m.Where(UserFilter{Name: "alice", City: "Berlin"}).Paginate(2, 1, mfp.Sort{Column: "id", Desc: true})
+			},
+			query:    "SELECT {columns} FROM users u {where} {pagination}",
+			want:     `SELECT ` + quoted(columns, `"`) + ` FROM users u WHERE u.name = $1 AND addr.city = $2 ORDER BY u.id DESC LIMIT 1 OFFSET 1`,
+			wantArgs: []any{"alice", "Berlin"},
+		},
+		{
+			name:    "postgres keyset",
+			dialect: mfp.DialectPostgres,
+			build: func(m *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return m.PaginateAfter(1, int64(1))
+			},
+			query:    "SELECT {columns} FROM users u WHERE {keyset} {pagination}",
+			want:     `SELECT ` + quoted(columns, `"`) + ` FROM users u WHERE u.id > $1 ORDER BY u.id LIMIT 1`,
+			wantArgs: []any{int64(1)},
+		},
+		{
+			name:    "mysql where and pagination",
+			dialect: mfp.DialectMySQL,
+			build: func(m *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return m.Where(UserFilter{Name: "alice", City: "Berlin"}).Paginate(2, 1, mfp.Sort{Column: "id", Desc: true})
+			},
+			query:    "SELECT {columns} FROM users u {where} {pagination}",
+			want:     "SELECT " + quoted(columns, "`") + " FROM users u WHERE u.name = ? AND addr.city = ? ORDER BY u.id DESC LIMIT 1 OFFSET 1",
+			wantArgs: []any{"alice", "Berlin"},
+		},
+		{
+			name:    "mysql keyset",
+			dialect: mfp.DialectMySQL,
+			build: func(m *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return m.PaginateAfter(1, int64(1))
+			},
+			query:    "SELECT {columns} FROM users u WHERE {keyset} {pagination}",
+			want:     "SELECT " + quoted(columns, "`") + " FROM users u WHERE u.id > ? ORDER BY u.id LIMIT 1",
+			wantArgs: []any{int64(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithDialect(tt.dialect))
+			m = tt.build(m.Columns(User{}, "u", Address{}, "addr", LocationMeta{}, "lm"))
+
+			if got := m.InQuery(tt.query); got != tt.want {
+				t.Errorf("InQuery() = %q, want %q", got, tt.want)
+			}
+
+			if got := m.Args(); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("Args() = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// quoted replaces {q} of the columns with the quote of the dialect's identifiers
+func quoted(columns, quote string) string {
+	return strings.ReplaceAll(columns, "{q}", quote)
+}
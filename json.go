@@ -0,0 +1,191 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONDialect is implemented by dialects extracting keys of JSON columns with their own syntax,
+// dialects which don't implement it use the PostgreSQL one
+type JSONDialect interface {
+	// JSONPath returns the expression extracting the value at the path of keys from the JSON column,
+	// as text if asText is true, e.g. 'u.meta->'address'->>'city''
+	JSONPath(column string, keys []string, asText bool) string
+}
+
+func (d quoteDialect) JSONPath(column string, keys []string, asText bool) string {
+	switch d.name {
+	case "mysql":
+		operator := "->"
+		if asText {
+			operator = "->>"
+		}
+
+		return column + operator + "'" + jsonPathExpr(keys) + "'"
+	case "mssql", "oracle", "bigquery":
+		function := "JSON_QUERY"
+		if asText {
+			function = "JSON_VALUE"
+		}
+
+		return function + "(" + column + ", '" + jsonPathExpr(keys) + "')"
+	}
+
+	return postgresJSONPath(column, keys, asText)
+}
+
+// postgresJSONPath chains '->' operators ending with '->>' for text values, e.g. 'u.meta->'address'->>'city”
+func postgresJSONPath(column string, keys []string, asText bool) string {
+	var sb strings.Builder
+	sb.WriteString(column)
+
+	for i, key := range keys {
+		if asText && i == len(keys)-1 {
+			sb.WriteString("->>")
+		} else {
+			sb.WriteString("->")
+		}
+
+		sb.WriteString(quoteString(key))
+	}
+
+	return sb.String()
+}
+
+// jsonPathExpr returns SQL/JSON path of the keys, e.g. '$."address"."city"'
+func jsonPathExpr(keys []string) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+
+	for _, key := range keys {
+		sb.WriteString(`."`)
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(key, `"`, `\"`), "'", "''"))
+		sb.WriteString(`"`)
+	}
+
+	return sb.String()
+}
+
+// quoteString quotes the SQL string literal, e.g. 'city'
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (mp *ModelFieldsPrefixer) jsonPath(column string, keys []string, asText bool) string {
+	if d, ok := mp.dialect.(JSONDialect); ok {
+		return d.JSONPath(column, keys, asText)
+	}
+
+	return postgresJSONPath(column, keys, asText)
+}
+
+// collectJSON builds model info of the payload of JSON column from json tags of the struct: DBTag of every field is
+// its JSON key. Nested objects are fields with their own model info, values which are not strings, numbers or booleans
+// (e.g. arrays or time.Time) are extracted as JSON and decoded with json codec
+func (mp *ModelFieldsPrefixer) collectJSON(t reflect.Type, modelsPrefix string, visiting map[reflect.Type]bool) *ModelInfo {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	modelInfo := &ModelInfo{
+		Name:         t.Name(),
+		ModelsPrefix: modelsPrefix,
+		Fields:       make([]*FieldInfo, 0, t.NumField()),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		key, _ := parseDBTag(field.Tag.Get("json"))
+		if key == "-" {
+			continue
+		}
+
+		if key == "" {
+			key = field.Name
+		}
+
+		fieldInfo := &FieldInfo{
+			Name:   field.Name,
+			DBTag:  key,
+			Index:  i,
+			Type:   field.Type,
+			IsJSON: true,
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		case reflect.Struct:
+			if fieldType != timeType && !isScannerType(fieldType) && !visiting[fieldType] {
+				fieldInfo.ModelInfo = mp.collectJSON(fieldType, modelsPrefix+"."+key, visiting)
+
+				break
+			}
+
+			fieldInfo.Codec = "json"
+		default:
+			fieldInfo.Codec = "json"
+		}
+
+		modelInfo.Fields = append(modelInfo.Fields, fieldInfo)
+	}
+
+	return modelInfo
+}
+
+// buildJSON writes keys of the JSON column's payload as columns, e.g. 'u.meta->>'city' AS "meta.city"'.
+// keys is the path of keys leading to the payload and path is indexes of fields leading to it from the root model
+func (mp *ModelFieldsPrefixer) buildJSON(payload *ModelInfo, dbAlias, column string, keys []string, path []int) {
+	for _, field := range payload.Fields {
+		fieldKeys := append(keys[:len(keys):len(keys)], field.DBTag)
+
+		var fieldPath []int
+		if path != nil {
+			fieldPath = appendPath(path, field.Index)
+		}
+
+		if field.ModelInfo != nil {
+			mp.buildJSON(field.ModelInfo, dbAlias, column, fieldKeys, fieldPath)
+
+			continue
+		}
+
+		mp.addAlias(dbAlias)
+
+		start := mp.bytesBuffer.Len()
+
+		// values decoded by codecs are extracted as JSON, others as text
+		mp.bytesBuffer.WriteString(mp.jsonPath(column, fieldKeys, field.Codec == ""))
+
+		var scanAlias, fullAlias string
+		if !mp.noAliases {
+			scanAlias, fullAlias = mp.limitScanAlias(mp.scanAlias(payload, field))
+			scanAlias = mp.uniqueScanAlias(scanAlias)
+
+			mp.writeAlias(scanAlias)
+		}
+
+		mp.columns = append(mp.columns, columnInfo{
+			path:      fieldPath,
+			field:     field,
+			start:     start,
+			end:       mp.bytesBuffer.Len(),
+			dbAlias:   dbAlias,
+			scanAlias: scanAlias,
+			isExpr:    true,
+			fullAlias: fullAlias,
+		})
+
+		mp.bytesBuffer.WriteString(", ")
+	}
+}
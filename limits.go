@@ -0,0 +1,84 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"unicode/utf8"
+)
+
+// IdentifierLimitDialect is implemented by dialects limiting the length of identifiers, e.g. PostgreSQL truncates
+// them at 63 bytes, so long scan aliases of deep relations silently collide. Dialects which don't implement it
+// have no limit
+type IdentifierLimitDialect interface {
+	// MaxIdentifierLength returns the max length of identifiers in bytes, 0 if they aren't limited
+	MaxIdentifierLength() int
+}
+
+func (d quoteDialect) MaxIdentifierLength() int {
+	return d.maxIdentifierLength
+}
+
+// shortAliasHashLength is the length of the hash suffix of shortened aliases including the separator - '_1a2b3c4d'
+const shortAliasHashLength = 9
+
+// maxIdentifierLength returns the limit of identifiers of the dialect, 0 if they aren't limited
+func (mp *ModelFieldsPrefixer) maxIdentifierLength() int {
+	if d, ok := mp.dialect.(IdentifierLimitDialect); ok {
+		return d.MaxIdentifierLength()
+	}
+
+	return 0
+}
+
+// shortenScanAlias returns the alias fitting the identifier limit of the dialect: longer aliases are cut and suffixed
+// with FNV-1a hash of the whole alias - 'users.users_meta.address.ci_1a2b3c4d', so the short alias is the same for
+// every build and process and Scan resolves it to the same field
+func (mp *ModelFieldsPrefixer) shortenScanAlias(alias string) string {
+	limit := mp.maxIdentifierLength()
+	if limit <= shortAliasHashLength || len(alias) <= limit {
+		return alias
+	}
+
+	cut := limit - shortAliasHashLength
+	for cut > 0 && !utf8.RuneStart(alias[cut]) {
+		cut--
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(alias))
+
+	return fmt.Sprintf("%s_%08x", alias[:cut], hash.Sum32())
+}
+
+// limitScanAlias shortens the alias of the written column if it exceeds the identifier limit of the dialect
+// and reports it, the full alias is returned as the second value if it was shortened
+func (mp *ModelFieldsPrefixer) limitScanAlias(alias string) (string, string) {
+	short := mp.shortenScanAlias(alias)
+	if short == alias {
+		return alias, ""
+	}
+
+	mp.shortenedAliases = append(mp.shortenedAliases, alias)
+	mp.warnShortenedAlias(alias, short)
+
+	return short, alias
+}
+
+func (mp *ModelFieldsPrefixer) warnShortenedAlias(alias, short string) {
+	mp.warnKind(WarningShortenedAlias, "scan alias exceeds the identifier limit of the dialect and is shortened",
+		"alias", alias, "short", short, "limit", mp.maxIdentifierLength())
+}
+
+// ShortAliases returns full scan aliases of the built columns which were shortened to fit the identifier limit
+// of the dialect, keyed by their short aliases, so scanners relying on aliases can still resolve the fields
+func (mp *ModelFieldsPrefixer) ShortAliases() map[string]string {
+	aliases := make(map[string]string)
+
+	for _, column := range mp.columns {
+		if column.fullAlias != "" {
+			aliases[column.scanAlias] = column.fullAlias
+		}
+	}
+
+	return aliases
+}
@@ -0,0 +1,115 @@
+package model_fields_prefixer
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	queryNameTag  = "name:"
+	queryModelTag = "model:"
+)
+
+// LoadQueries registers the queries of all .sql files of fsys (e.g. embed.FS) with RegisterQuery. Like in dotsql,
+// every query starts with '-- name: <query name>' comment and can be bound to a model with '-- model: <model name>'
+// comment, the model must be registered with Register before. Queries are validated like in RegisterQuery, and
+// Render of a query bound to a model fails for other models:
+//
+//	-- name: users.active
+//	-- model: User
+//	SELECT {columns} FROM users u {where} {orderby}
+//
+// Call it at startup, so broken queries fail fast:
+//
+//	//go:embed queries/*.sql
+//	var queries embed.FS
+//
+//	err := mp.MustRegister(User{}).LoadQueries(queries)
+func (mp *ModelFieldsPrefixer) LoadQueries(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(filePath) != ".sql" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		if err := mp.loadQueries(string(content)); err != nil {
+			return fmt.Errorf("failed to load queries of %s: %w", filePath, err)
+		}
+
+		return nil
+	})
+}
+
+// MustLoadQueries works as LoadQueries but panics on error
+func (mp *ModelFieldsPrefixer) MustLoadQueries(fsys fs.FS) *ModelFieldsPrefixer {
+	if err := mp.LoadQueries(fsys); err != nil {
+		panic(err)
+	}
+
+	return mp
+}
+
+func (mp *ModelFieldsPrefixer) loadQueries(content string) error {
+	var name string
+	var query registeredQuery
+	var sql strings.Builder
+
+	register := func() error {
+		if name == "" {
+			return nil
+		}
+
+		query.sqlTemplate = strings.TrimSpace(sql.String())
+
+		if query.model != "" && !mp.cache.hasModelName(query.model) {
+			return fmt.Errorf("failed to register query %s: model %s is not registered", name, query.model)
+		}
+
+		return mp.registerQuery(name, query)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		comment, isComment := strings.CutPrefix(strings.TrimSpace(line), "--")
+		comment = strings.TrimSpace(comment)
+
+		switch {
+		case isComment && strings.HasPrefix(comment, queryNameTag):
+			if err := register(); err != nil {
+				return err
+			}
+
+			name = strings.TrimSpace(strings.TrimPrefix(comment, queryNameTag))
+			query = registeredQuery{}
+			sql.Reset()
+		case isComment && strings.HasPrefix(comment, queryModelTag):
+			query.model = strings.TrimSpace(strings.TrimPrefix(comment, queryModelTag))
+		case name == "":
+			if strings.TrimSpace(line) != "" && !isComment {
+				return fmt.Errorf("line %d: query has no '-- name:' comment", lineNumber)
+			}
+		default:
+			sql.WriteString(line)
+			sql.WriteByte('\n')
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return register()
+}
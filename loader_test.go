@@ -0,0 +1,117 @@
+package model_fields_prefixer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestLoadQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		queries []string
+		wantErr string
+	}{
+		{
+			name: "queries of several files",
+			files: fstest.MapFS{
+				"queries/users.sql":     {Data: []byte("-- name: users.list\n-- model: User\nSELECT {columns}\nFROM users u {where}\n\n-- name: users.count\nSELECT COUNT(*) FROM users u {where} -- {columns}\n")},
+				"queries/meta/meta.sql": {Data: []byte("-- queries of user meta\n\n-- name: meta.list\n-- model: UserMeta\nSELECT {columns} FROM user_meta um\n")},
+				"queries/README.md":     {Data: []byte("SELECT {colums}")},
+			},
+			queries: []string{"meta.list", "users.count", "users.list"},
+		},
+		{
+			name:    "query without name",
+			files:   fstest.MapFS{"users.sql": {Data: []byte("-- users\nSELECT {columns} FROM users u\n")}},
+			wantErr: "failed to load queries of users.sql: line 2: query has no '-- name:' comment",
+		},
+		{
+			name:    "unregistered model",
+			files:   fstest.MapFS{"visits.sql": {Data: []byte("-- name: visits.list\n-- model: Visit\nSELECT {columns} FROM visits v\n")}},
+			wantErr: "failed to register query visits.list: model Visit is not registered",
+		},
+		{
+			name:    "mistyped placeholder",
+			files:   fstest.MapFS{"users.sql": {Data: []byte("-- name: users.list\nSELECT {colums} FROM users u\n")}},
+			wantErr: "failed to load queries of users.sql: failed to register query users.list: unknown placeholder {colums}",
+		},
+		{
+			name: "duplicate name",
+			files: fstest.MapFS{
+				"a.sql": {Data: []byte("-- name: users.list\nSELECT {columns} FROM users u\n")},
+				"b.sql": {Data: []byte("-- name: users.list\nSELECT {columns} FROM users u\n")},
+			},
+			wantErr: "failed to load queries of b.sql: failed to register query users.list: query is already registered",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New().MustRegister(User{}, UserMeta{})
+
+			err := m.LoadQueries(test.files)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("LoadQueries() error = %v, want %q", err, test.wantErr)
+			}
+
+			if test.wantErr != "" {
+				return
+			}
+
+			if queries := m.Queries(); !reflect.DeepEqual(queries, test.queries) {
+				t.Errorf("Queries() = %v, want %v", queries, test.queries)
+			}
+		})
+	}
+}
+
+func TestRenderLoadedQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		bindings mfp.Bindings
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "query of the model",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u", Filter: UserFilter{Name: "Ann"}},
+			want:     "SELECT " + `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"` + "\nFROM users u WHERE u.name = $1",
+		},
+		{
+			name:     "query without model",
+			query:    "meta.count",
+			bindings: mfp.Bindings{Model: UserMeta{}, Alias: "um"},
+			want:     "SELECT COUNT(*) FROM user_meta um -- um.id, um.city",
+		},
+		{
+			name:     "query of another model",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: UserMeta{}, Alias: "um"},
+			wantErr:  "failed to render query users.list: query is bound to model User, got model_fields_prefixer_test.UserMeta",
+		},
+	}
+
+	m := mfp.New().MustRegister(User{}, UserMeta{}).MustLoadQueries(fstest.MapFS{
+		"users.sql": {Data: []byte("-- name: users.list\n-- model: User\nSELECT {columns}\nFROM users u {where}\n\n-- name: meta.count\nSELECT COUNT(*) FROM user_meta um -- {columns}\n")},
+	})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, _, err := m.Render(test.query, test.bindings)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("Render() error = %v, want %q", err, test.wantErr)
+			}
+
+			if query != test.want {
+				t.Errorf("Render() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// LockOption is the option of the row lock built by Locking
+type LockOption int
+
+const (
+	// ForUpdate locks the selected rows against updates and locks by other transactions, it is the default strength
+	ForUpdate LockOption = iota
+	// ForShare locks the selected rows against updates by other transactions
+	ForShare
+	// NoWait fails the query instead of waiting for the rows locked by other transactions
+	NoWait
+	// SkipLocked skips the rows locked by other transactions, e.g. to fetch jobs of a queue
+	SkipLocked
+)
+
+// noLockWait means the lock waits for the rows locked by other transactions
+const noLockWait LockOption = -1
+
+// Locking builds the row locking clause of the dialect which replaces {locking} placeholder, e.g.
+// 'FOR UPDATE SKIP LOCKED' for Locking(ForUpdate, SkipLocked). MSSQL locks rows with table hints instead, so the hint
+// of the root model's table (e.g. 'WITH (UPDLOCK, ROWLOCK, READPAST)') replaces '{hint:Name}' placeholder of the
+// model's name and {locking} is left empty, it must be called after Columns then. Conflicting options fail with
+// prefixererr.ErrConflictingLocking and options the dialect doesn't support fail with
+// prefixererr.ErrUnsupportedLocking, the query isn't locked then: SQLite and BigQuery have no row locks and Oracle
+// has no shared locks
+func (mp *ModelFieldsPrefixer) Locking(opts ...LockOption) *ModelFieldsPrefixer {
+	mp.locking = ""
+
+	strength, wait := ForUpdate, noLockWait
+	strengthSet := false
+
+	for _, opt := range opts {
+		switch opt {
+		case ForUpdate, ForShare:
+			if strengthSet && strength != opt {
+				mp.failLocking(prefixererr.ErrConflictingLocking, strength, opt)
+
+				return mp
+			}
+
+			strength, strengthSet = opt, true
+		case NoWait, SkipLocked:
+			if wait != noLockWait && wait != opt {
+				mp.failLocking(prefixererr.ErrConflictingLocking, wait, opt)
+
+				return mp
+			}
+
+			wait = opt
+		}
+	}
+
+	switch mp.dialect.Name() {
+	case "sqlite", "bigquery":
+		mp.fail(fmt.Errorf("%w: %s", prefixererr.ErrUnsupportedLocking, mp.dialect.Name()), "dialect", mp.dialect.Name())
+
+		return mp
+	case "oracle":
+		if strength == ForShare {
+			mp.failLocking(prefixererr.ErrUnsupportedLocking, strength)
+
+			return mp
+		}
+	case "mssql":
+		mp.lockingHint(strength, wait)
+
+		return mp
+	}
+
+	mp.locking = lockClauses[strength]
+	if wait != noLockWait {
+		mp.locking += " " + lockClauses[wait]
+	}
+
+	return mp
+}
+
+// lockClauses are the clauses of the lock options, lockHints are the MSSQL table hints of them
+var (
+	lockClauses = map[LockOption]string{ForUpdate: "FOR UPDATE", ForShare: "FOR SHARE", NoWait: "NOWAIT", SkipLocked: "SKIP LOCKED"}
+	lockHints   = map[LockOption]string{ForUpdate: "UPDLOCK", ForShare: "REPEATABLEREAD", NoWait: "NOWAIT", SkipLocked: "READPAST"}
+)
+
+// lockingHint sets the MSSQL table hint of the root model's table locking its rows, e.g. 'WITH (UPDLOCK, ROWLOCK)'
+func (mp *ModelFieldsPrefixer) lockingHint(strength, wait LockOption) {
+	if mp.rootModel == nil {
+		mp.fail(fmt.Errorf("%w: %s locks tables of the models built by Columns", prefixererr.ErrUnsupportedLocking, mp.dialect.Name()),
+			"dialect", mp.dialect.Name())
+
+		return
+	}
+
+	hints := []string{lockHints[strength], "ROWLOCK"}
+	if wait != noLockWait {
+		hints = append(hints, lockHints[wait])
+	}
+
+	if mp.hints == nil {
+		mp.hints = make(map[string]string)
+	}
+
+	mp.hints[mp.rootModel.Name] = "WITH (" + strings.Join(hints, ", ") + ")"
+}
+
+// failLocking reports the lock options which are conflicting or aren't supported by the dialect
+func (mp *ModelFieldsPrefixer) failLocking(err error, opts ...LockOption) {
+	clauses := make([]string, 0, len(opts))
+	for _, opt := range opts {
+		clauses = append(clauses, lockClauses[opt])
+	}
+
+	mp.fail(fmt.Errorf("%w: %s", err, strings.Join(clauses, " and ")), "dialect", mp.dialect.Name())
+}
@@ -0,0 +1,118 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func TestLocking(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		opts    []mfp.LockOption
+		want    string
+		err     error
+	}{
+		{
+			name: "default strength",
+			want: "SELECT u.id, u.name FROM users u  WHERE u.id = 1 FOR UPDATE",
+		},
+		{
+			name: "skip locked",
+			opts: []mfp.LockOption{mfp.ForUpdate, mfp.SkipLocked},
+			want: "SELECT u.id, u.name FROM users u  WHERE u.id = 1 FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name: "shared lock",
+			opts: []mfp.LockOption{mfp.ForShare, mfp.NoWait},
+			want: "SELECT u.id, u.name FROM users u  WHERE u.id = 1 FOR SHARE NOWAIT",
+		},
+		{
+			name:    "mysql",
+			dialect: mfp.DialectMySQL,
+			opts:    []mfp.LockOption{mfp.NoWait},
+			want:    "SELECT u.id, u.name FROM users u  WHERE u.id = 1 FOR UPDATE NOWAIT",
+		},
+		{
+			name: "conflicting waits",
+			opts: []mfp.LockOption{mfp.NoWait, mfp.SkipLocked},
+			want: "SELECT u.id, u.name FROM users u  WHERE u.id = 1 ",
+			err:  prefixererr.ErrConflictingLocking,
+		},
+		{
+			name: "conflicting strengths",
+			opts: []mfp.LockOption{mfp.ForUpdate, mfp.ForShare},
+			want: "SELECT u.id, u.name FROM users u  WHERE u.id = 1 ",
+			err:  prefixererr.ErrConflictingLocking,
+		},
+		{
+			name:    "mssql",
+			dialect: mfp.DialectMSSQL,
+			want:    "SELECT u.id, u.name FROM users u WITH (UPDLOCK, ROWLOCK) WHERE u.id = 1 ",
+		},
+		{
+			name:    "mssql skip locked",
+			dialect: mfp.DialectMSSQL,
+			opts:    []mfp.LockOption{mfp.SkipLocked},
+			want:    "SELECT u.id, u.name FROM users u WITH (UPDLOCK, ROWLOCK, READPAST) WHERE u.id = 1 ",
+		},
+		{
+			name:    "mssql shared lock",
+			dialect: mfp.DialectMSSQL,
+			opts:    []mfp.LockOption{mfp.ForShare, mfp.NoWait},
+			want:    "SELECT u.id, u.name FROM users u WITH (REPEATABLEREAD, ROWLOCK, NOWAIT) WHERE u.id = 1 ",
+		},
+		{
+			name:    "oracle",
+			dialect: mfp.DialectOracle,
+			opts:    []mfp.LockOption{mfp.SkipLocked},
+			want:    "SELECT u.id, u.name FROM users u  WHERE u.id = 1 FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name:    "oracle shared lock",
+			dialect: mfp.DialectOracle,
+			opts:    []mfp.LockOption{mfp.ForShare},
+			want:    "SELECT u.id, u.name FROM users u  WHERE u.id = 1 ",
+			err:     prefixererr.ErrUnsupportedLocking,
+		},
+		{
+			name:    "sqlite",
+			dialect: mfp.DialectSQLite,
+			want:    "SELECT u.id, u.name FROM users u  WHERE u.id = 1 ",
+			err:     prefixererr.ErrUnsupportedLocking,
+		},
+		{
+			name:    "bigquery",
+			dialect: mfp.DialectBigQuery,
+			want:    "SELECT u.id, u.name FROM users u  WHERE u.id = 1 ",
+			err:     prefixererr.ErrUnsupportedLocking,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect), mfp.Strict()).Columns(User{}, "u", mfp.WithDepth(0)).Locking(test.opts...)
+
+			query, err := m.InQueryStrict("SELECT {columns} FROM users u {hint:User} WHERE u.id = 1 {locking}")
+			if err != nil {
+				t.Fatalf("InQueryStrict() error = %v", err)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Err() = %v, want %v", err, test.err)
+			}
+
+			if query != test.want {
+				t.Errorf("InQueryStrict() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
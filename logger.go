@@ -0,0 +1,62 @@
+package model_fields_prefixer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// stdoutDebugLogger is used in debug mode if no logger is set
+var stdoutDebugLogger = newDebugLogger(os.Stdout)
+
+func newDebugLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// log returns the logger of the prefixer, nil if diagnostics are disabled
+func (mp *ModelFieldsPrefixer) log() *slog.Logger {
+	if mp.logger != nil {
+		return mp.logger
+	}
+
+	if mp.debug {
+		return stdoutDebugLogger
+	}
+
+	return nil
+}
+
+func (mp *ModelFieldsPrefixer) warn(msg string, args ...any) {
+	mp.warnKind(WarningGeneral, msg, args...)
+}
+
+// SetDebugWriter writes diagnostics of the level and above to w as text: slog.LevelDebug includes events of models
+// cache population, exclusions of types and rendering decisions (e.g. skipped relations), slog.LevelWarn includes
+// warnings only. Nil w disables diagnostics
+func (mp *ModelFieldsPrefixer) SetDebugWriter(w io.Writer, level slog.Level) *ModelFieldsPrefixer {
+	if w == nil {
+		mp.debug = false
+		mp.logger = nil
+
+		return mp
+	}
+
+	mp.debug = true
+	mp.logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+
+	return mp
+}
+
+// isDebug reports whether debug events are logged, so their attributes aren't built on hot paths otherwise
+func (mp *ModelFieldsPrefixer) isDebug() bool {
+	logger := mp.log()
+
+	return logger != nil && logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (mp *ModelFieldsPrefixer) debugLog(msg string, args ...any) {
+	if logger := mp.log(); logger != nil {
+		logger.Debug(msg, args...)
+	}
+}
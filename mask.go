@@ -0,0 +1,38 @@
+package model_fields_prefixer
+
+// MaskFunc returns the expression masking the column, e.g. 'CONCAT(LEFT(u.ssn, 3), '***')'
+type MaskFunc func(column string) string
+
+// WithMaskFunc sets the expression columns marked with 'masked' tag option are rendered as. By default the first
+// 3 characters of the value are kept and the rest is replaced with '***'
+func WithMaskFunc(mask MaskFunc) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if mask != nil {
+			mp.maskFunc = mask
+		}
+	}
+}
+
+// Unmasked selects columns marked with 'masked' tag option as they are, e.g. for privileged code paths
+func Unmasked() ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.unmasked = true
+	}
+}
+
+// mask returns the expression masking the column
+func (mp *ModelFieldsPrefixer) mask(column string) string {
+	if mp.maskFunc != nil {
+		return mp.maskFunc(column)
+	}
+
+	// SQLite has neither LEFT nor CONCAT functions before 3.44, Oracle has no LEFT function
+	switch mp.dialect.Name() {
+	case "sqlite":
+		return "substr(" + column + ", 1, 3) || '***'"
+	case "oracle":
+		return "SUBSTR(" + column + ", 1, 3) || '***'"
+	}
+
+	return "CONCAT(LEFT(" + column + ", 3), '***')"
+}
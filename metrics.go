@@ -0,0 +1,55 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+	"time"
+)
+
+// Metrics receives measurements of the prefixer, e.g. to export them to Prometheus.
+// Set it with WithMetrics option, methods are called synchronously and must be safe for concurrent use
+type Metrics interface {
+	// ModelCacheLookup is called on every lookup of model info in the cache, hit is false if the model has to be scanned
+	ModelCacheLookup(model string, hit bool)
+	// ModelScanned is called after the model is scanned with reflection
+	ModelScanned(model string, duration time.Duration)
+	// ColumnsBuilt is called after every Columns call, rendered is true if the columns were taken from the rendered columns cache
+	ColumnsBuilt(model string, duration time.Duration, rendered bool)
+}
+
+// WithMetrics sets metrics receiving measurements of the cache and building columns
+func WithMetrics(metrics Metrics) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.metrics = metrics
+	}
+}
+
+// metricsStart returns the start time of a measurement, the zero time if there are no metrics so the clock is not read
+func (mp *ModelFieldsPrefixer) metricsStart() time.Time {
+	if mp.metrics == nil {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+func (mp *ModelFieldsPrefixer) reportModelCacheLookup(t reflect.Type, hit bool) {
+	if mp.metrics != nil {
+		mp.metrics.ModelCacheLookup(fullTypeName(t), hit)
+	}
+}
+
+func (mp *ModelFieldsPrefixer) reportModelScanned(t reflect.Type, start time.Time) {
+	if mp.metrics != nil {
+		mp.metrics.ModelScanned(fullTypeName(t), time.Since(start))
+	}
+}
+
+func (mp *ModelFieldsPrefixer) reportColumnsBuilt(t reflect.Type, start time.Time, rendered bool) {
+	if mp.metrics != nil {
+		mp.metrics.ColumnsBuilt(fullTypeName(t), time.Since(start), rendered)
+	}
+
+	if mp.isDebug() {
+		mp.debugLog("columns are built", "model", fullTypeName(t), "alias", mp.rootAlias, "columns", len(mp.columns), "cached", rendered)
+	}
+}
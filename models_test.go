@@ -0,0 +1,25 @@
+package model_fields_prefixer_test
+
+type User struct {
+	ID   int64     `db:"id,pk"`
+	Name string    `db:"name"`
+	Meta *UserMeta `db:"meta" dbalias:"um"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+type UserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+func (UserMeta) TableName() string {
+	return "user_meta"
+}
+
+type UserFilter struct {
+	Name string `db:"name,omitempty"`
+	City string `db:"um.city,omitempty"`
+}
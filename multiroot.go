@@ -0,0 +1,66 @@
+package model_fields_prefixer
+
+// Root is a model selected by ColumnsMulti along with the alias of its table, its join models and the per-call options
+// applied to its columns only
+type Root struct {
+	Model   any
+	Alias   string
+	Joins   []M
+	Options []ColumnsOption
+}
+
+// ColumnsMulti works as Columns but writes columns of several independent models to one columns list in one pass,
+// e.g. for cross or lateral joins of unrelated tables. OrderBy, pagination, soft delete conditions and {table}
+// placeholder refer to the first root:
+//
+//	mp.ColumnsMulti(mfp.Root{Model: User{}, Alias: "u"}, mfp.Root{Model: Setting{}, Alias: "s"}).
+//		InQuery("SELECT {columns} FROM users u CROSS JOIN settings s")
+func (mp *ModelFieldsPrefixer) ColumnsMulti(roots ...Root) *ModelFieldsPrefixer {
+	mp.reset()
+
+	var (
+		rootModel *ModelInfo
+		rootAlias string
+		rootTable string
+	)
+
+	for i, root := range roots {
+		joinModels := make([]any, 0, len(root.Joins)+len(root.Options))
+		for _, joinModel := range root.Joins {
+			joinModels = append(joinModels, joinModel)
+		}
+
+		for _, option := range root.Options {
+			joinModels = append(joinModels, option)
+		}
+
+		start := len(mp.columns)
+
+		// options of the previous root never apply to this one
+		mp.resetColumnsOptions()
+
+		mp.writeRoot(root.Model, root.Alias, joinModels...)
+
+		if i == 0 {
+			rootModel, rootAlias, rootTable = mp.rootModel, mp.rootAlias, mp.rootTable
+		} else {
+			// paths of the other roots' columns don't lead to fields of the first root, so they are scanned to placeholders
+			for j := start; j < len(mp.columns); j++ {
+				mp.columns[j].path = nil
+			}
+		}
+
+		mp.applyOmit(start)
+	}
+
+	mp.rootModel = rootModel
+	mp.rootAlias = rootAlias
+	mp.rootTable = rootTable
+
+	// the rendered columns of the first root don't describe the whole buffer
+	if len(roots) > 1 {
+		mp.rendered = nil
+	}
+
+	return mp
+}
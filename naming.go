@@ -0,0 +1,68 @@
+package model_fields_prefixer
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the column name from the name of a field which has no column name in its db tag
+type NamingStrategy func(fieldName string) string
+
+// WithNamingStrategy makes fields without db tags columns named by the strategy instead of skipping them,
+// e.g. WithNamingStrategy(SnakeCase) maps CreatedAt field to 'created_at' column like sqlx and gorm do.
+// Unexported and embedded fields, and fields tagged with '-' are still skipped
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.namingStrategy = strategy
+	}
+}
+
+// SnakeCase converts the field name to snake case keeping acronyms together, e.g. 'UserID' to 'user_id'
+// and 'HTTPStatus' to 'http_status'
+func SnakeCase(fieldName string) string {
+	runes := []rune(fieldName)
+
+	var sb strings.Builder
+	sb.Grow(len(fieldName) + 4)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			isNextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			// 'aB' starts a new word as well as 'ABc' does ('HTTPStatus' -> 'http_status')
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && isNextLower) {
+				sb.WriteByte('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
+
+// columnName returns the column name of the field and its tag options, empty name if the field is not a column
+func (mp *ModelFieldsPrefixer) columnName(field reflect.StructField) (string, []string) {
+	tag, isTagged := field.Tag.Lookup(mp.tagName)
+
+	dbTag, dbTagOptions := parseDBTag(tag)
+	if dbTag == "-" || dbTag != "" || mp.namingStrategy == nil {
+		return dbTag, dbTagOptions
+	}
+
+	if !field.IsExported() || (field.Anonymous && !isTagged) {
+		return "", nil
+	}
+
+	return mp.namingStrategy(field.Name), dbTagOptions
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isScannerType returns true if pointer to the type implements sql.Scanner
+func isScannerType(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(scannerType)
+}
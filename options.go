@@ -0,0 +1,108 @@
+package model_fields_prefixer
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+)
+
+const (
+	defaultTagName    = "db"
+	defaultBufferSize = 256
+	// aliasTagName is the name of the struct tag with the default alias of a relation's table
+	aliasTagName = "dbalias"
+)
+
+// Option configures the prefixer created by New
+type Option func(mp *ModelFieldsPrefixer)
+
+// WithTagName sets the name of the struct tag with column names, 'db' by default
+func WithTagName(tagName string) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if tagName != "" {
+			mp.tagName = tagName
+		}
+	}
+}
+
+// WithDialect sets the dialect of the built columns, DialectPostgres by default
+func WithDialect(dialect Dialect) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if dialect != nil {
+			mp.dialect = dialect
+		}
+	}
+}
+
+// WithDebugWriter enables debug mode writing debug messages to w as text
+func WithDebugWriter(w io.Writer) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if w != nil {
+			mp.debug = true
+			mp.logger = newDebugLogger(w)
+		}
+	}
+}
+
+// WithLogger routes diagnostics (e.g. unknown columns passed to Values) to the logger, so they are captured
+// by the service's logging pipeline. Warnings are logged at warn level, scans of models at debug level
+func WithLogger(logger *slog.Logger) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.logger = logger
+	}
+}
+
+// WithBufferSize sets the initial size of the buffer the columns are written to. By default it's 256 bytes for New
+// and the max measured length of the rendered columns for prefixers allocated later, e.g. by AllocPrefixer or Acquire
+func WithBufferSize(size int) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		if size > 0 {
+			mp.bufferSize = size
+			mp.bufferSizeSet = true
+		}
+	}
+}
+
+// WithStableOrder works as SetStableOrder
+func WithStableOrder(stableOrder bool) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.stableOrder = stableOrder
+	}
+}
+
+// WithCodec works as SetCodec
+func WithCodec(name string, codec Codec) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.codecs.set(name, codec)
+	}
+}
+
+// WithRenderedCacheSize works as SetRenderedCacheSize
+func WithRenderedCacheSize(maxEntries int) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.cache.rendered.setMaxEntries(maxEntries)
+	}
+}
+
+// New creates the prefixer configured with the options, e.g.
+//
+//	mp := mfp.New(mfp.WithDialect(mfp.DialectMySQL), mfp.WithBufferSize(1024))
+func New(opts ...Option) *ModelFieldsPrefixer {
+	mp := &ModelFieldsPrefixer{config: config{
+		cache:      newModelsInfoCache(defaultRenderedCacheSize),
+		codecs:     newCodecRegistry(),
+		tagName:    defaultTagName,
+		dialect:    DialectPostgres,
+		bufferSize: defaultBufferSize,
+		pool:       &sync.Pool{},
+		queries:    newQueryRegistry(),
+	}}
+
+	for _, opt := range opts {
+		opt(mp)
+	}
+
+	mp.bytesBuffer = newColumnsBuffer(defaultBufferBackend, mp.bufferSize)
+
+	return mp
+}
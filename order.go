@@ -0,0 +1,161 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// SetStableOrder makes OrderBy append primary key columns (marked with 'pk' tag option) of the root model
+// as a tiebreaker, so rows with equal sort values always come in the same order and pagination stays stable
+func (mp *ModelFieldsPrefixer) SetStableOrder(stableOrder bool) *ModelFieldsPrefixer {
+	mp.stableOrder = stableOrder
+
+	return mp
+}
+
+// OrderBy builds ORDER BY clause for the model passed to the last Columns call which replaces {orderby} placeholder.
+// orderBy is a comma separated list of columns with optional directions, e.g. 'created_at DESC, name', or '-created_at'
+// for descending order. It is safe to pass user input: only columns of the root model ('created_at') and built columns
+// ('um.city') are accepted and only ASC, DESC and NULLS FIRST/LAST directions are allowed, other items are skipped
+// and reported as failures (see Strict). Columns without alias are prefixed with the alias of the root model
+func (mp *ModelFieldsPrefixer) OrderBy(orderBy string) *ModelFieldsPrefixer {
+	items, orderedColumns := mp.orderItems("order by", orderBy, mp.sortableColumns(), true)
+
+	if mp.stableOrder && mp.rootModel != nil {
+		for _, field := range mp.rootModel.Fields {
+			if !field.IsPK {
+				continue
+			}
+
+			if _, ok := orderedColumns[mp.rootAlias+"."+field.DBTag]; ok {
+				continue
+			}
+
+			items = append(items, mp.quoteTableAlias(mp.rootAlias)+"."+mp.quoteColumn(field.DBTag))
+		}
+	}
+
+	mp.orderBy = ""
+	if len(items) > 0 {
+		mp.orderBy = "ORDER BY " + strings.Join(items, ", ")
+	}
+
+	return mp
+}
+
+// orderItems renders the comma separated list of columns validated as OrderBy argument, clause names the list in
+// failures. Directions are accepted only if directed is true, e.g. they are not allowed in PARTITION BY
+func (mp *ModelFieldsPrefixer) orderItems(clause, list string, sortableColumns map[string]string, directed bool) ([]string, map[string]struct{}) {
+	items := make([]string, 0)
+	orderedColumns := make(map[string]struct{})
+
+	for _, item := range strings.Split(list, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+
+		column := fields[0]
+		directions := fields[1:]
+
+		if directed && strings.HasPrefix(column, "-") && len(directions) == 0 {
+			column = strings.TrimPrefix(column, "-")
+			directions = []string{"DESC"}
+		}
+
+		if !strings.Contains(column, ".") && mp.rootAlias != "" {
+			column = mp.rootAlias + "." + column
+		}
+
+		rendered, ok := sortableColumns[column]
+		if !ok {
+			mp.fail(fmt.Errorf("%w: %s of %s", prefixererr.ErrUnknownColumn, column, clause), "column", column)
+
+			continue
+		}
+
+		direction, ok := orderDirection(directions)
+		if !ok || (!directed && direction != "") {
+			mp.fail(fmt.Errorf("%w: %q of %s", prefixererr.ErrInvalidDirection, strings.Join(directions, " "), clause),
+				"column", column)
+
+			continue
+		}
+
+		orderedColumns[column] = struct{}{}
+
+		items = append(items, rendered+direction)
+	}
+
+	return items, orderedColumns
+}
+
+// sortableColumns maps columns which can be used in ORDER BY ('u.id', 'um.city') to how they are rendered
+func (mp *ModelFieldsPrefixer) sortableColumns() map[string]string {
+	columns := make(map[string]string)
+
+	if mp.rootModel != nil {
+		for _, field := range mp.rootModel.Fields {
+			if field.IsStruct || field.IsWriteOnly {
+				continue
+			}
+
+			columns[mp.rootAlias+"."+field.DBTag] = mp.sortableColumn(mp.rootAlias, field)
+		}
+	}
+
+	for _, column := range mp.columns {
+		// keys of JSON columns are not columns of the tables
+		if column.field == nil || column.field.IsJSON {
+			continue
+		}
+
+		columns[column.dbAlias+"."+column.field.DBTag] = mp.sortableColumn(column.dbAlias, column.field)
+	}
+
+	return columns
+}
+
+// sortableColumn renders the column for ORDER BY, expression columns are rendered as their expressions
+func (mp *ModelFieldsPrefixer) sortableColumn(dbAlias string, field *FieldInfo) string {
+	if field.Count != nil {
+		return mp.countExpr(field.Count, dbAlias)
+	}
+
+	if field.Expr != "" {
+		return strings.ReplaceAll(field.Expr, exprAliasPlaceholder, dbAlias)
+	}
+
+	return mp.quoteTableAlias(dbAlias) + "." + mp.quoteColumn(mp.localizedColumn(field))
+}
+
+// orderDirection validates direction of ORDER BY item, e.g. ['desc', 'nulls', 'last'] is rendered as ' DESC NULLS LAST'
+func orderDirection(fields []string) (string, bool) {
+	direction := ""
+
+	if len(fields) > 0 {
+		switch strings.ToUpper(fields[0]) {
+		case "ASC", "DESC":
+			direction = " " + strings.ToUpper(fields[0])
+			fields = fields[1:]
+		}
+	}
+
+	switch len(fields) {
+	case 0:
+		return direction, true
+	case 2:
+		if strings.ToUpper(fields[0]) != "NULLS" {
+			return "", false
+		}
+
+		switch strings.ToUpper(fields[1]) {
+		case "FIRST", "LAST":
+			return direction + " NULLS " + strings.ToUpper(fields[1]), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,78 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// selectUsers is the query of User{} columns with "u" alias followed by its clauses
+const selectUsers = `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u `
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderBy     string
+		stableOrder bool
+		want        string
+		err         error
+	}{
+		{
+			name:    "root column",
+			orderBy: "name",
+			want:    selectUsers + "ORDER BY u.name",
+		},
+		{
+			name:    "directions",
+			orderBy: "-name, um.city asc nulls last",
+			want:    selectUsers + "ORDER BY u.name DESC, um.city ASC NULLS LAST",
+		},
+		{
+			name:        "stable order",
+			orderBy:     "name",
+			stableOrder: true,
+			want:        selectUsers + "ORDER BY u.name, u.id",
+		},
+		{
+			name:        "stable order by pk",
+			orderBy:     "id DESC",
+			stableOrder: true,
+			want:        selectUsers + "ORDER BY u.id DESC",
+		},
+		{
+			name:    "unknown column",
+			orderBy: "name, email",
+			want:    selectUsers + "ORDER BY u.name",
+			err:     prefixererr.ErrUnknownColumn,
+		},
+		{
+			name:    "injection",
+			orderBy: "name DESC; DROP TABLE users",
+			want:    selectUsers,
+			err:     prefixererr.ErrInvalidDirection,
+		},
+		{
+			name:    "nulls without order",
+			orderBy: "name NULLS",
+			want:    selectUsers,
+			err:     prefixererr.ErrInvalidDirection,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.Strict()).SetStableOrder(test.stableOrder)
+
+			query := m.Columns(User{}, "u").OrderBy(test.orderBy).InQuery("SELECT {columns} FROM users u {orderby}")
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Err() = %v, want %v", err, test.err)
+			}
+
+			if query != test.want {
+				t.Errorf("InQuery() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
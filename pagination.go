@@ -0,0 +1,154 @@
+package model_fields_prefixer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Sort is an item of ORDER BY clause built by Paginate
+type Sort struct {
+	// Column is the name of the column, validated like in OrderBy
+	Column string
+	Desc   bool
+}
+
+// PaginationDialect is implemented by dialects limiting rows with their own syntax, dialects which don't implement it
+// use 'LIMIT n OFFSET m'
+type PaginationDialect interface {
+	// Pagination returns the clause selecting limit rows after skipping offset ones, offset is 0 for the first page
+	// and for keyset pagination
+	Pagination(limit, offset int) string
+}
+
+// limitRows returns the clause of the dialect selecting limit rows after skipping offset ones,
+// 'LIMIT n OFFSET m' by default and 'LIMIT n' for keyset pagination
+func (mp *ModelFieldsPrefixer) limitRows(limit, offset int, keyset bool) string {
+	if d, ok := mp.dialect.(PaginationDialect); ok {
+		return d.Pagination(limit, offset)
+	}
+
+	if keyset {
+		return "LIMIT " + strconv.Itoa(limit)
+	}
+
+	return "LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+}
+
+// Paginate builds ORDER BY of the sort items (validated like in OrderBy) followed by LIMIT and OFFSET of the page
+// (starting from 1), and replaces {pagination} placeholder with them:
+//
+//	m.Columns(Post{}, "p").Paginate(2, 20, mfp.Sort{Column: "created_at", Desc: true}).
+//		InQuery("SELECT {columns} FROM posts p {pagination}") // ... ORDER BY p.created_at DESC LIMIT 20 OFFSET 20
+//
+// If no sort items are passed, ORDER BY built by OrderBy is used, or ORDER BY primary key columns if there is none
+// and SetStableOrder is enabled. MSSQL pages require ORDER BY, so it's '(SELECT NULL)' if there is nothing to order by
+func (mp *ModelFieldsPrefixer) Paginate(page, perPage int, sort ...Sort) *ModelFieldsPrefixer {
+	if page < 1 {
+		page = 1
+	}
+
+	if len(sort) > 0 || mp.orderBy == "" && mp.stableOrder {
+		items := make([]string, 0, len(sort))
+
+		for _, s := range sort {
+			item := s.Column
+			if s.Desc {
+				item += " DESC"
+			}
+
+			items = append(items, item)
+		}
+
+		mp.OrderBy(strings.Join(items, ", "))
+	}
+
+	mp.pagination = mp.orderBy
+
+	if perPage > 0 && mp.pagination == "" && mp.dialect.Name() == "mssql" {
+		mp.pagination = "ORDER BY (SELECT NULL)"
+	}
+
+	if perPage > 0 {
+		mp.pagination = joinClauses(mp.pagination, mp.limitRows(perPage, (page-1)*perPage, false))
+	}
+
+	return mp
+}
+
+// PaginateAfter builds keyset pagination by primary key columns (marked with 'pk' tag option) of the root model: the
+// condition selecting rows after the given primary key values replaces {keyset} placeholder and ORDER BY primary key
+// columns with LIMIT replaces {pagination} placeholder. The values are bound as parameters (see Args), no values
+// means the first page:
+//
+//	m.Columns(Post{}, "p").PaginateAfter(20, lastID).
+//		InQuery("SELECT {columns} FROM posts p WHERE {keyset} {pagination}") // ... WHERE p.id > $1 ORDER BY p.id LIMIT 20
+func (mp *ModelFieldsPrefixer) PaginateAfter(perPage int, after ...any) *ModelFieldsPrefixer {
+	// the condition selecting all the rows is used for the first page
+	mp.keyset = "1 = 1"
+
+	var pkColumns []string
+
+	if mp.rootModel != nil {
+		for _, field := range mp.rootModel.Fields {
+			if field.IsPK {
+				pkColumns = append(pkColumns, mp.sortableColumn(mp.rootAlias, field))
+			}
+		}
+	}
+
+	if len(pkColumns) == 0 {
+		mp.warn("keyset pagination requires pk columns, the first page is selected", "model", mp.rootModelName())
+
+		return mp.Paginate(1, perPage)
+	}
+
+	switch {
+	case len(after) == 0:
+	case len(after) != len(pkColumns):
+		mp.warn("number of keyset values doesn't match pk columns, the first page is selected", "model", mp.rootModelName())
+	default:
+		placeholders := make([]string, 0, len(after))
+
+		for _, value := range after {
+			mp.args = append(mp.args, value)
+			placeholders = append(placeholders, mp.dialect.Placeholder(len(mp.args)))
+		}
+
+		if len(pkColumns) == 1 {
+			mp.keyset = pkColumns[0] + " > " + placeholders[0]
+		} else {
+			// row values comparison keeps the order of composite keys
+			mp.keyset = "(" + strings.Join(pkColumns, ", ") + ") > (" + strings.Join(placeholders, ", ") + ")"
+		}
+	}
+
+	mp.orderBy = "ORDER BY " + strings.Join(pkColumns, ", ")
+	mp.pagination = mp.orderBy
+
+	if perPage > 0 {
+		mp.pagination = joinClauses(mp.pagination, mp.limitRows(perPage, 0, true))
+	}
+
+	return mp
+}
+
+func (mp *ModelFieldsPrefixer) rootModelName() string {
+	if mp.rootModel == nil {
+		return ""
+	}
+
+	return mp.rootModel.Name
+}
+
+// joinClauses joins non empty clauses with spaces
+func joinClauses(clauses ...string) string {
+	nonEmpty := make([]string, 0, len(clauses))
+
+	for _, clause := range clauses {
+		if clause != "" {
+			nonEmpty = append(nonEmpty, clause)
+		}
+	}
+
+	return strings.Join(nonEmpty, " ")
+}
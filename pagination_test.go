@@ -0,0 +1,212 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     mfp.Dialect
+		stableOrder bool
+		orderBy     string
+		page        int
+		perPage     int
+		sort        []mfp.Sort
+		want        string
+		err         error
+	}{
+		{
+			name:    "sort",
+			page:    2,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name", Desc: true}},
+			want:    "ORDER BY u.name DESC LIMIT 20 OFFSET 20",
+		},
+		{
+			name:    "page before the first one",
+			page:    0,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name LIMIT 20 OFFSET 0",
+		},
+		{
+			name:    "order by",
+			orderBy: "um.city",
+			page:    3,
+			perPage: 10,
+			want:    "ORDER BY um.city LIMIT 10 OFFSET 20",
+		},
+		{
+			name:    "sort without limit",
+			page:    1,
+			perPage: 0,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name",
+		},
+		{
+			name:        "stable order without sort",
+			stableOrder: true,
+			page:        2,
+			perPage:     20,
+			want:        "ORDER BY u.id LIMIT 20 OFFSET 20",
+		},
+		{
+			name:        "stable order of sort",
+			stableOrder: true,
+			page:        1,
+			perPage:     20,
+			sort:        []mfp.Sort{{Column: "name"}},
+			want:        "ORDER BY u.name, u.id LIMIT 20 OFFSET 0",
+		},
+		{
+			name:    "unknown sort column",
+			page:    1,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "email"}},
+			want:    "LIMIT 20 OFFSET 0",
+			err:     prefixererr.ErrUnknownColumn,
+		},
+		{
+			name:    "mssql",
+			dialect: mfp.DialectMSSQL,
+			page:    2,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name OFFSET 20 ROWS FETCH NEXT 20 ROWS ONLY",
+		},
+		{
+			name:    "mssql first page",
+			dialect: mfp.DialectMSSQL,
+			page:    1,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+		},
+		{
+			name:    "mssql without order",
+			dialect: mfp.DialectMSSQL,
+			page:    1,
+			perPage: 20,
+			want:    "ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+		},
+		{
+			name:    "oracle first page",
+			dialect: mfp.DialectOracle,
+			page:    1,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name FETCH FIRST 20 ROWS ONLY",
+		},
+		{
+			name:    "oracle",
+			dialect: mfp.DialectOracle,
+			page:    2,
+			perPage: 20,
+			sort:    []mfp.Sort{{Column: "name"}},
+			want:    "ORDER BY u.name OFFSET 20 ROWS FETCH NEXT 20 ROWS ONLY",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect), mfp.Strict()).SetStableOrder(test.stableOrder)
+			m.Columns(User{}, "u")
+
+			if test.orderBy != "" {
+				m.OrderBy(test.orderBy)
+			}
+
+			query := m.Paginate(test.page, test.perPage, test.sort...).InQuery("{columns} {pagination}")
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Err() = %v, want %v", err, test.err)
+			}
+
+			if want := m.String() + " " + test.want; query != want {
+				t.Errorf("InQuery() = %q, want %q", query, want)
+			}
+		})
+	}
+}
+
+func TestPaginateAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    mfp.Dialect
+		perPage    int
+		after      []any
+		keyset     string
+		pagination string
+		args       []any
+	}{
+		{
+			name:       "first page",
+			perPage:    20,
+			keyset:     "1 = 1",
+			pagination: "ORDER BY u.id LIMIT 20",
+		},
+		{
+			name:       "next page",
+			perPage:    20,
+			after:      []any{int64(42)},
+			keyset:     "u.id > $1",
+			pagination: "ORDER BY u.id LIMIT 20",
+			args:       []any{int64(42)},
+		},
+		{
+			name:       "values not matching pk",
+			perPage:    20,
+			after:      []any{int64(42), "name"},
+			keyset:     "1 = 1",
+			pagination: "ORDER BY u.id LIMIT 20",
+		},
+		{
+			name:       "mssql",
+			dialect:    mfp.DialectMSSQL,
+			perPage:    20,
+			after:      []any{int64(42)},
+			keyset:     "u.id > @p1",
+			pagination: "ORDER BY u.id OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+			args:       []any{int64(42)},
+		},
+		{
+			name:       "oracle",
+			dialect:    mfp.DialectOracle,
+			perPage:    20,
+			after:      []any{int64(42)},
+			keyset:     "u.id > :1",
+			pagination: "ORDER BY u.id FETCH FIRST 20 ROWS ONLY",
+			args:       []any{int64(42)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect)).Columns(User{}, "u", mfp.WithDepth(0)).PaginateAfter(test.perPage, test.after...)
+
+			query := m.InQuery("SELECT {columns} FROM users u WHERE {keyset} {pagination}")
+			if want := "SELECT " + m.String() + " FROM users u WHERE " + test.keyset + " " + test.pagination; query != want {
+				t.Errorf("InQuery() = %q, want %q", query, want)
+			}
+
+			if args := m.Args(); !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Args() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
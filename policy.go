@@ -0,0 +1,36 @@
+package model_fields_prefixer
+
+// RelationPolicy defines which nested models (relations) are included in the columns built by Columns
+type RelationPolicy int
+
+const (
+	// RelationsByJoinModels includes all the relations recursively if no join models are passed to Columns
+	// and only the passed ones otherwise. It is the default policy
+	RelationsByJoinModels RelationPolicy = iota
+	// IncludeAllRelations always includes all the relations recursively, join models only set their aliases
+	IncludeAllRelations
+	// RootOnly includes only columns of the root model, join models are used only for lateral joins (M.F)
+	RootOnly
+	// ExplicitOnly includes only relations passed as join models, so no join models means only columns of the root model
+	ExplicitOnly
+)
+
+// WithRelationPolicy sets the policy of including relations, so the set of columns doesn't depend on whether
+// join models are passed or not
+func WithRelationPolicy(policy RelationPolicy) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.relationPolicy = policy
+	}
+}
+
+// isFullyRecursive returns true if all the relations are included regardless of the join models
+func (mp *ModelFieldsPrefixer) isFullyRecursive(joinModelsMap map[string]M) bool {
+	switch mp.relationPolicy {
+	case IncludeAllRelations:
+		return true
+	case RootOnly, ExplicitOnly:
+		return false
+	default:
+		return len(joinModelsMap) == 0
+	}
+}
@@ -0,0 +1,41 @@
+package model_fields_prefixer
+
+// maxPooledBufferSize is the max capacity of the buffer of a released prefixer,
+// prefixers with larger buffers are not pooled, so rare huge queries don't keep memory forever
+const maxPooledBufferSize = 64 * 1024
+
+// Acquire returns a prefixer sharing the cache with mp (like AllocPrefixer) taken from the pool, so its buffer is reused.
+// Call Release when the prefixer is not needed anymore, e.g. at the end of a request handler:
+//
+//	p := mp.Acquire()
+//	defer p.Release()
+func (mp *ModelFieldsPrefixer) Acquire() *ModelFieldsPrefixer {
+	p, ok := mp.pool.Get().(*ModelFieldsPrefixer)
+	if !ok || p.cache != mp.cache {
+		p = mp.AllocPrefixer()
+	}
+
+	// settings may be changed since the prefixer was released
+	mp.copyConfig(p)
+	p.acquired = true
+
+	return p
+}
+
+// Release resets the prefixer and puts it back to the pool. The prefixer must not be used after the call.
+// Release of a prefixer which wasn't returned by Acquire does nothing
+func (mp *ModelFieldsPrefixer) Release() {
+	if !mp.acquired || mp.pool == nil {
+		return
+	}
+
+	mp.acquired = false
+
+	if mp.bytesBuffer.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	mp.reset()
+
+	mp.pool.Put(mp)
+}
@@ -0,0 +1,67 @@
+package model_fields_prefixer_test
+
+import (
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestAcquireConfig(t *testing.T) {
+	const (
+		query  = "SELECT {columns} FROM users u"
+		plain  = `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u`
+		pretty = "SELECT \n    u.id,\n    u.name,\n    um.id AS \"meta.id\",\n    um.city AS \"meta.city\"\n FROM users u"
+	)
+
+	tests := []struct {
+		name string
+		// parent and released configure the prefixer Acquire is called on and the prefixer released to the pool
+		parent   func(mp *mfp.ModelFieldsPrefixer)
+		released func(p *mfp.ModelFieldsPrefixer)
+		want     string
+	}{
+		{
+			name:     "defaults",
+			parent:   func(mp *mfp.ModelFieldsPrefixer) {},
+			released: func(p *mfp.ModelFieldsPrefixer) {},
+			want:     plain,
+		},
+		{
+			name: "changed parent",
+			parent: func(mp *mfp.ModelFieldsPrefixer) {
+				mp.SetPrettyQuery(true)
+			},
+			released: func(p *mfp.ModelFieldsPrefixer) {},
+			want:     pretty,
+		},
+		{
+			name:   "changed released prefixer",
+			parent: func(mp *mfp.ModelFieldsPrefixer) {},
+			released: func(p *mfp.ModelFieldsPrefixer) {
+				p.SetPrettyQuery(true)
+			},
+			want: plain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := mfp.New()
+
+			// the released prefixer is reused by the next Acquire
+			p := mp.Acquire()
+			tt.released(p)
+			p.Columns(User{}, "u")
+			p.Release()
+
+			tt.parent(mp)
+
+			p = mp.Acquire()
+			defer p.Release()
+
+			if got := p.Columns(User{}, "u").InQuery(query); got != tt.want {
+				t.Errorf("InQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
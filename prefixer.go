@@ -1,43 +1,219 @@
 package model_fields_prefixer
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
 )
 
-const prefixedColumnsPlaceholder = "{columns}"
+const (
+	prefixedColumnsPlaceholder = "{columns}"
+	joinsPlaceholder           = "{joins}"
+	orderByPlaceholder         = "{orderby}"
+	groupByPlaceholder         = "{groupby}"
+	lockingPlaceholder         = "{locking}"
+	paginationPlaceholder      = "{pagination}"
+	keysetPlaceholder          = "{keyset}"
+	// exprAliasPlaceholder is replaced with the alias of the model's table in expressions of expression columns
+	exprAliasPlaceholder = "{alias}"
+)
 
 type ModelFieldsPrefixer struct {
-	bytesBuffer     *bytes.Buffer
-	cache           *ModelsInfoCache
-	excludeScanning map[string]struct{}
+	bytesBuffer columnsBuffer
+	config
+	callState
+	callOptions
+	// acquired is true if the instance was taken from the pool
+	acquired bool
+}
 
-	debug bool
+// config is the configuration of the prefixer set by options along with the caches and registries shared with
+// the prefixers allocated from it, see copyConfig
+type config struct {
+	cache  *ModelsInfoCache
+	codecs *codecRegistry
+	// tagName is the name of the struct tag with column names and dialect quotes scan aliases
+	tagName        string
+	dialect        Dialect
+	namingStrategy NamingStrategy
+	quoteAll       bool
+	relationPolicy RelationPolicy
+	// tenantColumn is the column compared with the tenant returned by tenantFunc in {where}
+	tenantColumn string
+	tenantFunc   TenantFunc
+	// commentFunc returns the tags of the query comment appended by InQuery, see WithSQLCommenter
+	commentFunc CommentFunc
+	tracer      Tracer
+	// auditColumns are names of the columns set by the database which are handled as columns marked with 'audit' tag option
+	auditColumns map[string]struct{}
+	columnOrder  ColumnOrder
+	maskFunc     MaskFunc
+	// decryptExpr and encryptionKey are set by WithEncryption
+	decryptExpr   string
+	encryptionKey any
+	tableResolver TableResolver
+	// tableQualifier qualifies tables of placeholders, e.g. 'project.dataset', see WithTableQualifier
+	tableQualifier string
+	// aliasStrategy generates aliases of the tables passed without them, see WithAutoAlias
+	aliasStrategy AliasStrategy
+	strict        bool
+	// validator checks queries rendered by ValidateQuery
+	validator QueryValidator
+
+	// logger receives diagnostics, if it is nil then they are written to stdout in debug mode only
+	logger     *slog.Logger
+	metrics    Metrics
+	debug      bool
+	bufferSize int
+	// configID identifies the configuration of the prefixer in the rendered columns cache, it changes only with Clone
+	configID uint64
+	// defaultAliasSeparator is set with WithDefaultAliasSeparator, aliasSeparator is reset to it by every Columns call
+	defaultAliasSeparator string
+	// bufferSizeSet is true if the size is set with WithBufferSize, otherwise it is tuned by the measured columns length
+	bufferSizeSet bool
+	stableOrder   bool
+	// prettyQuery makes InQuery write the columns one per line, see SetPrettyQuery
+	prettyQuery bool
+
+	// pool keeps released prefixers sharing the cache
+	pool *sync.Pool
+	// queries are the query templates registered with RegisterQuery
+	queries *queryRegistry
+}
+
+// callState is the state of the last Columns call and the calls following it, see reset
+type callState struct {
+	// lateralJoins are join models of the last Columns call which are joined from function calls
+	lateralJoins []M
+	// hints are table hints of the join models of the last Columns call keyed by their names
+	hints map[string]string
+	// columns describe every column written to the builder
+	columns []columnInfo
+	// rootModel and rootAlias are the model and its db alias passed to the last Columns call
+	rootModel *ModelInfo
+	rootAlias string
+	// rootTable and tables are the tables of the root model and the join models resolved by the table resolver
+	rootTable string
+	tables    map[string]string
+	orderBy   string
+	// groupBy is true if GroupBy is called, the clause is rendered from the columns in InQuery
+	groupBy bool
+	// locking is the row locking clause built by Locking, e.g. 'FOR UPDATE SKIP LOCKED'
+	locking string
+	// distinct is DISTINCT or DISTINCT ON clause prepended to the columns list in InQuery
+	distinct string
+	// pagination is ORDER BY with LIMIT and OFFSET clauses and keyset is the condition of keyset pagination
+	pagination string
+	keyset     string
+	// where are the conditions of WHERE clause built by Where
+	where []string
+	// ctes are the common table expressions declared by WithCTE
+	ctes []cte
+	// withTrashed disables soft delete conditions in {where}
+	withTrashed bool
+	// tenant are the tenant conditions bound by WithContext, tenantBound is true if it was called
+	tenant      []string
+	tenantBound bool
+	// tenantArgs are the positions (starting from 1) of the tenant's bind parameters in args
+	tenantArgs []int
+	// queryCtx is the context passed to WithContext, the tags of the query comment are taken from it
+	queryCtx context.Context
+	// spanCtx carries the span of the running Columns call, see startSpan
+	spanCtx context.Context
+	// aliases are db aliases of all the tables which columns were written by the last Columns call
+	aliases []string
+	// args are bind parameters of the columns captured by CustomColumnsf
+	args []any
+	// errs are failures of the last Columns call and the calls following it kept in strict mode
+	errs []error
+	// warnings are warnings of the last Columns call and the calls following it, see Warnings
+	warnings []Warning
+	// unknownJoins are names of join models of the last Columns call which don't match any relation
+	unknownJoins []string
+	// autoAliases are the aliases generated by aliasStrategy for the last Columns call, usedAliases are all the aliases
+	// of the call generated aliases must not collide with
+	autoAliases []autoAlias
+	usedAliases map[string]struct{}
+	// tableAliases are the aliases of the tables written by the last Columns call, see checkAliasCollisions
+	tableAliases []tableAlias
+	// coalescing is true while columns of a relation coalesced by WithCoalesce or M.C are written
+	coalescing bool
+
+	// rendered is the cached rendering the buffer was restored from, nil if the buffer was written since then
+	rendered *renderedColumns
+
+	// scanAliases are result set names of the written columns, they are collected on the first write after reset
+	// if scanAliasesReady is false. duplicateAliases are the aliases renamed to make them unique
+	scanAliases      map[string]struct{}
+	scanAliasesReady bool
+	duplicateAliases []duplicateAlias
+	// shortenedAliases are full scan aliases shortened to the identifier limit of the dialect
+	shortenedAliases []string
+}
+
+// callOptions are set by ColumnsOption values of the last Columns call, see resetColumnsOptions
+type callOptions struct {
+	callJoins []M
+	omit      []string
+	// maxDepth is -1 if the depth is not limited
+	maxDepth       int
+	aliasSeparator string
+	rootAliasing   bool
+	noAliases      bool
+	coalesce       bool
+	groups         []string
+	unmasked       bool
+	locale         string
+	requestCtx     context.Context
+}
+
+// columnInfo describes a column written to the builder
+type columnInfo struct {
+	// path is indexes of fields from the root model to the column's field, nil for custom columns and columns of slice relations
+	path  []int
+	field *FieldInfo
+	// start and end are offsets of the column in the buffer
+	start, end int
+	// dbAlias is the alias of the column's table and scanAlias is the name of the column in the result set if it is aliased
+	dbAlias   string
+	scanAlias string
+	// isExpr is true if the column is written as an expression (e.g. masked one) instead of the table's column
+	isExpr bool
+	// args is the number of bind parameters of the column, e.g. encryption keys
+	args int
+	// column is the name of the table's column if it differs from the field's db tag, e.g. localized 'name_ru'
+	column string
+	// fullAlias is the scan alias before it was shortened to the identifier limit of the dialect, empty if it wasn't
+	fullAlias string
+	// aggregate is true for aggregate and window function columns which GroupBy doesn't repeat
+	aggregate bool
 }
 
 type M struct {
-	N string // name of Model
+	N string // name of Model, name of the relation's field (e.g. 'Buyer') or dotted path of fields (e.g. 'Author.Profile')
 	A string // DB alias for using in queries
+	F string // function call returning rows of the model, e.g. 'get_user_stats(u.id)', rendered as a lateral join in {joins}
+	W bool   // select all columns of the model with 'alias.*' instead of enumerating them, e.g. for tools expecting star-selects
+	C bool   // wrap columns of the model in COALESCE with zero values of their types, e.g. for optional LEFT JOINs
+	H string // table hint of the model's table rendered by '{hint:Name}' placeholder in MSSQL, e.g. 'WITH (NOLOCK)'
 }
 
+// NewModelFieldsPrefixer creates the prefixer with default options.
+//
+// Deprecated: use New which also accepts options
 func NewModelFieldsPrefixer() *ModelFieldsPrefixer {
-	bytesBuffer := &bytes.Buffer{}
-	bytesBuffer.Grow(256)
-
-	return &ModelFieldsPrefixer{
-		bytesBuffer: bytesBuffer,
-		cache: &ModelsInfoCache{
-			modelsCache: make(map[string]*ModelInfo),
-			mu:          &sync.RWMutex{},
-		},
-		excludeScanning: make(map[string]struct{}),
-		debug:           false,
-	}
+	return New()
 }
 
+// SetDebug enables writing diagnostics to stdout.
+//
+// Deprecated: use SetDebugWriter which also sets the level, or WithLogger
 func (mp *ModelFieldsPrefixer) SetDebug(debug bool) *ModelFieldsPrefixer {
 	mp.debug = debug
 
@@ -45,145 +221,700 @@ func (mp *ModelFieldsPrefixer) SetDebug(debug bool) *ModelFieldsPrefixer {
 }
 
 func (mp *ModelFieldsPrefixer) handleBuilderErr(err error, str string) {
-	if mp.debug && err != nil {
-		fmt.Printf("failed to write string (%s) to builder: %+v\n", str, err)
+	if err != nil {
+		mp.warn("failed to write string to builder", "value", str, "error", err)
 	}
 }
 
 // AllocPrefixer creates new ModelFieldsPrefixer instance with the cache from the parent instance.
 // Use this method if you access ModelFieldsPrefixer from multiple goroutines and you want concurrent safe behavior
 func (mp *ModelFieldsPrefixer) AllocPrefixer() *ModelFieldsPrefixer {
-	bytesBuffer := &bytes.Buffer{}
-	bytesBuffer.Grow(256)
+	p := &ModelFieldsPrefixer{bytesBuffer: newColumnsBuffer(defaultBufferBackend, mp.initialBufferSize())}
 
-	return &ModelFieldsPrefixer{
-		bytesBuffer:     bytesBuffer,
-		cache:           mp.cache,
-		excludeScanning: mp.excludeScanning,
-	}
+	mp.copyConfig(p)
+
+	return p
+}
+
+// copyConfig copies the configuration of mp (options and shared caches and registries) to p, the columns built by p
+// are kept
+func (mp *ModelFieldsPrefixer) copyConfig(p *ModelFieldsPrefixer) {
+	p.config = mp.config
 }
 
 // CustomColumns allows to write columns in a custom way. E.g. if you need conditions, switch cases and so on
 func (mp *ModelFieldsPrefixer) CustomColumns(custom string) *ModelFieldsPrefixer {
-	if mp.bytesBuffer.Len() > 0 {
-		mp.bytesBuffer.WriteString(", ")
-	}
+	start := mp.bytesBuffer.Len()
+	mp.rendered = nil
 
+	// every column in the buffer is followed by the separator, String() trims the last one
 	mp.bytesBuffer.WriteString(custom)
+	mp.columns = append(mp.columns, columnInfo{start: start, end: mp.bytesBuffer.Len()})
+	mp.bytesBuffer.WriteString(", ")
 
 	return mp
 }
 
 func (mp *ModelFieldsPrefixer) Columns(args ...any) *ModelFieldsPrefixer {
-	mp.bytesBuffer.Reset()
+	mp.reset()
 
-	if len(args) < 2 {
+	if len(args) == 0 {
 		return mp
 	}
 
-	model := args[0]
-	dbTableAlias := args[1].(string)
+	// the alias of the model implementing TableName may be omitted, see writeRoot, so the second argument is the alias
+	// only if it's a string, otherwise it's a join model or an option
+	var dbTableAlias string
 
-	t := reflect.TypeOf(model)
+	joinModels := args[1:]
+	if len(joinModels) > 0 {
+		if alias, ok := joinModels[0].(string); ok {
+			dbTableAlias = alias
+			joinModels = joinModels[1:]
+		}
+	}
 
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	mp.writeRoot(args[0], dbTableAlias, joinModels...)
+	mp.applyOmit(0)
+
+	return mp
+}
+
+// writeRoot appends columns of the root model and its join models to the buffer
+func (mp *ModelFieldsPrefixer) writeRoot(model any, dbTableAlias string, args ...any) {
+	t, ok := modelType(model)
+	if !ok {
+		mp.fail(fmt.Errorf("%w: %T", prefixererr.ErrNotStruct, model))
+
+		return
 	}
 
-	tKind := t.Kind()
-	tName := t.Name()
+	start := mp.metricsStart()
 
-	if tKind == reflect.Ptr {
-		t = t.Elem()
+	joinModels := mp.getJoinModels(args...)
+
+	span := mp.startSpan(SpanColumns)
+
+	// the alias of the model implementing TableName defaults to its table - 'users.id', or is generated by WithAutoAlias
+	if dbTableAlias == "" {
+		dbTableAlias = mp.defaultAlias(t.Name(), mp.modelTable(t), joinModels)
 	}
 
-	if tKind != reflect.Struct {
+	dbTableAlias = mp.resolveTables(t.Name(), dbTableAlias, joinModels)
+
+	tables := len(mp.tableAliases)
+
+	// rendered columns are cached only at the start of the buffer, e.g. placeholders of their parameters are numbered from 1
+	cacheable := mp.bytesBuffer.Len() == 0
+
+	// columns of the same model, alias and join models are rendered only once
+	var key renderedKey
+	if cacheable {
+		key = mp.newRenderedKey(t, dbTableAlias, joinModels)
+
+		if rendered := mp.cache.rendered.get(key); rendered != nil {
+			mp.addModelWarnings(t)
+			mp.restoreRendered(rendered, dbTableAlias)
+			mp.setLateralJoins(joinModels)
+			mp.setHints(joinModels)
+			mp.checkColumns()
+			mp.checkAliasCollisions(tables)
+
+			mp.reportColumnsBuilt(t, start, true)
+			mp.endColumnsSpan(span, t, true)
+
+			return
+		}
+	}
+
+	modelInfo := mp.getModelInfo(t)
+
+	mp.buildColumns(modelInfo, dbTableAlias, joinModels)
+
+	if modelInfo != nil && cacheable {
+		mp.rendered = mp.renderedSnapshot()
+		mp.cache.rendered.set(key, mp.rendered)
+	}
+
+	mp.checkColumns()
+	mp.checkAliasCollisions(tables)
+
+	mp.reportColumnsBuilt(t, start, false)
+	mp.endColumnsSpan(span, t, false)
+}
+
+// ColumnsOf works as Columns but renders the given model info instead of the model's cached one,
+// e.g. the one built without reflection by prefixer-gen
+func (mp *ModelFieldsPrefixer) ColumnsOf(modelInfo *ModelInfo, dbTableAlias string, joinModels ...any) *ModelFieldsPrefixer {
+	mp.reset()
+
+	if modelInfo == nil {
 		return mp
 	}
 
-	modelInfo := mp.cache.getModelCacheValue(tName)
+	resolvedJoinModels := mp.getJoinModels(joinModels...)
+
+	if dbTableAlias == "" {
+		dbTableAlias = mp.defaultAlias(modelInfo.Name, modelInfo.Table, resolvedJoinModels)
+	}
+
+	dbTableAlias = mp.resolveTables(modelInfo.Name, dbTableAlias, resolvedJoinModels)
+
+	mp.buildColumns(modelInfo, dbTableAlias, resolvedJoinModels)
+	mp.checkColumns()
+	mp.checkAliasCollisions(0)
+	mp.applyOmit(0)
+
+	return mp
+}
+
+// SetModelInfo puts prebuilt info of the model to the cache, e.g. the one generated by prefixer-gen, so the model is never scanned
+func (mp *ModelFieldsPrefixer) SetModelInfo(model any, modelInfo *ModelInfo) *ModelFieldsPrefixer {
+	t, ok := modelType(model)
+	if !ok {
+		return mp
+	}
+
+	mp.cache.setModelCacheValue(t, modelInfo)
+
+	return mp
+}
+
+// Reset clears the built columns and everything built along with them (joins, ORDER BY, DISTINCT and bind parameters).
+// Columns resets the builder itself, so it is needed only to reuse the builder for custom columns
+func (mp *ModelFieldsPrefixer) Reset() *ModelFieldsPrefixer {
+	mp.reset()
+
+	return mp
+}
+
+func (mp *ModelFieldsPrefixer) reset() {
+	mp.bytesBuffer.Reset()
+	mp.callState.reset()
+	mp.resetColumnsOptions()
+}
+
+// reset zeroes the state, slices and maps are emptied and kept, so their memory is reused by the next call
+func (s *callState) reset() {
+	clear(s.hints)
+	clear(s.tables)
+	clear(s.usedAliases)
+
+	*s = callState{
+		lateralJoins:     s.lateralJoins[:0],
+		hints:            s.hints,
+		columns:          s.columns[:0],
+		tables:           s.tables,
+		where:            s.where[:0],
+		ctes:             s.ctes[:0],
+		tenant:           s.tenant[:0],
+		tenantArgs:       s.tenantArgs[:0],
+		aliases:          s.aliases[:0],
+		args:             s.args[:0],
+		errs:             s.errs[:0],
+		warnings:         s.warnings[:0],
+		autoAliases:      s.autoAliases[:0],
+		usedAliases:      s.usedAliases,
+		tableAliases:     s.tableAliases[:0],
+		scanAliases:      s.scanAliases,
+		duplicateAliases: s.duplicateAliases[:0],
+		shortenedAliases: s.shortenedAliases[:0],
+	}
+}
+
+func (mp *ModelFieldsPrefixer) buildColumns(modelInfo *ModelInfo, dbTableAlias string, joinModels []M) {
+	mp.rootModel = modelInfo
+	mp.rootAlias = dbTableAlias
+
+	mp.setLateralJoins(joinModels)
+	mp.setHints(joinModels)
 
 	if modelInfo == nil {
-		modelInfo, _ = mp.collectCache(t, nil, dbTableAlias, "")
+		return
+	}
 
-		if modelInfo != nil {
-			mp.cache.setModelCacheValue(tName, modelInfo)
+	mp.useAliases(dbTableAlias, joinModels)
+
+	// the buffer is grown once to the length measured on the previous renders, so large models don't reallocate it
+	start := mp.bytesBuffer.Len()
+	mp.bytesBuffer.Grow(mp.cache.getRenderedLen(modelInfo))
+
+	mp.buildString(modelInfo, dbTableAlias, mp.getJoinModelsMap(joinModels), []int{}, "", false)
+
+	mp.cache.setRenderedLen(modelInfo, mp.bytesBuffer.Len()-start)
+
+	mp.unknownJoins = unknownJoinModels(modelInfo, joinModels)
+}
+
+// setLateralJoins keeps join models joined from function calls to render them in {joins}, they fail if the dialect
+// has no lateral joins
+func (mp *ModelFieldsPrefixer) setLateralJoins(joinModels []M) {
+	for _, joinModel := range joinModels {
+		if joinModel.F == "" {
+			continue
+		}
+
+		if _, ok := mp.lateralJoin(joinModel); !ok {
+			mp.fail(fmt.Errorf("%w: %s", prefixererr.ErrUnsupportedJoin, mp.dialect.Name()), "model", joinModel.N, "function", joinModel.F)
+
+			continue
 		}
+
+		mp.lateralJoins = append(mp.lateralJoins, joinModel)
 	}
+}
 
-	// build string here
-	var joinModelsMap map[string]M
-	if len(args) > 2 && (len(args[2:])%2 == 0) {
-		joinModelsMap = mp.getJoinModelsMap(args[2:]...)
+// lateralJoin renders the join of the function call of the join model, false if the dialect has no lateral joins
+func (mp *ModelFieldsPrefixer) lateralJoin(joinModel M) (string, bool) {
+	switch mp.dialect.Name() {
+	case "postgres", "mysql":
+		return "LEFT JOIN LATERAL " + joinModel.F + " " + joinModel.A + " ON true", true
+	case "mssql", "oracle":
+		return "OUTER APPLY " + joinModel.F + " " + joinModel.A, true
 	}
 
-	mp.buildString(modelInfo, joinModelsMap)
+	return "", false
+}
 
-	return mp
+// matchJoinModel returns the join model of the relation field. Join models are matched by the dotted path of fields
+// ('Author.Profile'), then by the name of the field, so relations of the same model (e.g. Buyer and Seller of User model)
+// may have different aliases, and then by the name of the model. Relations leading to the ones selected by dotted paths
+// are matched as well with their own aliases
+func matchJoinModel(joinModelsMap map[string]M, namePath string, field *FieldInfo) (M, bool) {
+	if joinModel, ok := joinModelsMap[namePath]; ok {
+		return joinModel, true
+	}
+
+	if joinModel, ok := joinModelsMap[field.Name]; ok {
+		return joinModel, true
+	}
+
+	if joinModel, ok := joinModelsMap[field.ModelInfo.Name]; ok {
+		return joinModel, true
+	}
+
+	for name := range joinModelsMap {
+		if strings.HasPrefix(name, namePath+".") {
+			return M{}, true
+		}
+	}
+
+	return M{}, false
 }
 
-func (mp *ModelFieldsPrefixer) buildString(model *ModelInfo, joinModelsMap map[string]M) {
-	isFullyRecursive := true
+// writeWildcard writes 'alias.*' column selecting all columns of the model's table
+func (mp *ModelFieldsPrefixer) writeWildcard(dbAlias string) {
+	mp.addAlias(dbAlias)
+
+	start := mp.bytesBuffer.Len()
+
+	tableAlias := mp.quoteTableAlias(dbAlias)
+
+	_, err := mp.bytesBuffer.WriteString(tableAlias)
+	mp.handleBuilderErr(err, tableAlias)
 
-	if len(joinModelsMap) > 0 {
-		isFullyRecursive = false
+	_, _ = mp.bytesBuffer.WriteString(".*")
+
+	// wildcard columns aren't mapped to fields, so they are scanned as custom ones
+	mp.columns = append(mp.columns, columnInfo{start: start, end: mp.bytesBuffer.Len(), dbAlias: dbAlias})
+
+	_, _ = mp.bytesBuffer.WriteString(", ")
+}
+
+// resultColumnName returns the name of the field's column in the result set: its own alias set with 'as' tag option,
+// the db tag prefixed with db tags of the parent models for nested models ('um.city') or just the db tag for the root model
+func resultColumnName(model *ModelInfo, field *FieldInfo) string {
+	if field.ScanAlias != "" {
+		return field.ScanAlias
 	}
 
-	for _, field := range model.Fields {
+	if model.ModelsPrefix != "" {
+		return model.ModelsPrefix + "." + field.DBTag
+	}
+
+	return field.DBTag
+}
+
+// scanAlias returns the name of the column in the result set using the separator set with WithAliasSeparator
+func (mp *ModelFieldsPrefixer) scanAlias(model *ModelInfo, field *FieldInfo) string {
+	if mp.aliasSeparator == "" || field.ScanAlias != "" || model.ModelsPrefix == "" {
+		return resultColumnName(model, field)
+	}
+
+	return strings.ReplaceAll(model.ModelsPrefix, ".", mp.aliasSeparator) + mp.aliasSeparator + field.DBTag
+}
+
+// rootScanAlias returns the scan alias of the root model's column prefixed with the alias of the table
+func (mp *ModelFieldsPrefixer) rootScanAlias(dbAlias, column string) string {
+	if mp.aliasSeparator != "" {
+		return dbAlias + mp.aliasSeparator + column
+	}
+
+	return dbAlias + "." + column
+}
+
+// modelType returns the struct type of the model, dereferencing pointers. The second value is false if the model is not a struct
+func modelType(model any) (reflect.Type, bool) {
+	t := reflect.TypeOf(model)
+	if t == nil {
+		return nil, false
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	return t, true
+}
+
+// getModelInfo returns cached info of the model, scanning the model and caching the result if it wasn't cached yet
+func (mp *ModelFieldsPrefixer) getModelInfo(t reflect.Type) *ModelInfo {
+	modelInfo := mp.cache.getModelCacheValue(t)
+
+	mp.reportModelCacheLookup(t, modelInfo != nil)
+
+	if modelInfo != nil {
+		atomic.AddInt64(&mp.cache.hits, 1)
+
+		mp.addModelWarnings(t)
+	} else {
+		atomic.AddInt64(&mp.cache.misses, 1)
+
+		if staticModels {
+			mp.fail(fmt.Errorf("%w: %s", prefixererr.ErrNotPrebuilt, fullTypeName(t)), "model", fullTypeName(t))
+
+			return nil
+		}
+
+		mp.debugLog("model is scanned", "model", fullTypeName(t))
+
+		start := mp.metricsStart()
+		span := mp.startSpan(SpanCollect)
+
+		warnings := len(mp.warnings)
+
+		modelInfo, _ = mp.collectCache(t, nil, "", "")
+
+		mp.reportModelScanned(t, start)
+		mp.endCollectSpan(span, t)
+
+		if modelInfo != nil {
+			// warnings of the collected model are reported by every Columns call of it
+			if len(mp.warnings) > warnings {
+				mp.cache.setModelWarnings(t, append([]Warning(nil), mp.warnings[warnings:]...))
+			}
+
+			mp.cache.setModelCacheValue(t, modelInfo)
+
+			mp.debugLog("model is cached", "model", fullTypeName(t), "fields", len(modelInfo.Fields))
+		}
+	}
+
+	return modelInfo
+}
+
+// buildString writes columns of the model to the buffer. dbAlias is the alias of the model's table, nested models use aliases
+// of joinModelsMap or their own DBAlias if there are none.
+// path is indexes of fields leading to the model from the root one, nil if the model's fields are not addressable (e.g. slice elements),
+// namePath is names of those fields joined with dots, e.g. 'Author.Profile'. If wildcard is true then the model's own columns are written as 'alias.*'
+func (mp *ModelFieldsPrefixer) buildString(model *ModelInfo, dbAlias string, joinModelsMap map[string]M, path []int, namePath string, wildcard bool) {
+	isFullyRecursive := mp.isFullyRecursive(joinModelsMap)
+
+	mp.addTableAlias(model, dbAlias, namePath)
+
+	if wildcard {
+		mp.writeWildcard(dbAlias)
+	}
+
+	var audit []*FieldInfo
+
+	for _, field := range mp.orderedFields(model) {
+		// fields of groups which aren't passed to WithGroups are not selected
+		if !mp.isInGroups(field) {
+			if mp.isDebug() {
+				mp.debugLog("field is skipped", "model", model.Name, "field", field.Name, "reason", "groups")
+			}
+
+			continue
+		}
+
+		// keys of JSON columns are selected instead of the columns themselves
+		if field.IsJSON && field.ModelInfo != nil {
+			if !field.IsWriteOnly && !wildcard {
+				var fieldPath []int
+				if path != nil {
+					fieldPath = appendPath(path, field.Index)
+				}
+
+				column := mp.quoteTableAlias(dbAlias) + "." + mp.quoteColumn(field.DBTag)
+
+				mp.buildJSON(field.ModelInfo, dbAlias, column, nil, fieldPath)
+			}
+
+			continue
+		}
+
+		// fields of STRUCT columns are selected by their paths from the column of the model's table - 'u.address.city'
+		if field.IsStructColumn && field.IsStruct && field.ModelInfo != nil {
+			if !field.IsWriteOnly && !wildcard {
+				var fieldPath []int
+				if path != nil && !isSliceType(field.Type) {
+					fieldPath = appendPath(path, field.Index)
+				}
+
+				fieldNamePath := field.Name
+				if namePath != "" {
+					fieldNamePath = namePath + "." + field.Name
+				}
+
+				mp.buildString(field.ModelInfo, dbAlias+"."+field.DBTag, joinModelsMap, fieldPath, fieldNamePath, false)
+			}
+
+			continue
+		}
+
 		// if it is a struct and join model is exist then go recursive
 		if field.IsStruct && field.ModelInfo != nil {
-			joinModel, ok := joinModelsMap[field.ModelInfo.Name]
+			if mp.relationPolicy == RootOnly || mp.isDeeperThanMaxDepth(namePath) {
+				if mp.isDebug() {
+					mp.debugLog("relation is skipped", "model", model.Name, "field", field.Name, "reason", "depth")
+				}
+
+				continue
+			}
+
+			fieldNamePath := field.Name
+			if namePath != "" {
+				fieldNamePath = namePath + "." + field.Name
+			}
+
+			joinModel, ok := matchJoinModel(joinModelsMap, fieldNamePath, field)
 
 			if !isFullyRecursive && !ok {
+				if mp.isDebug() {
+					mp.debugLog("relation is skipped", "model", model.Name, "field", field.Name, "reason", "join models")
+				}
+
 				continue
 			}
 
-			if joinModel.A != "" {
-				field.ModelInfo.DBAlias = joinModel.A
+			// cached model infos are shared between prefixers, so aliases of join models are never written to them
+			fieldDBAlias := joinModel.A
+			if fieldDBAlias == "" {
+				fieldDBAlias = mp.relationAlias(fieldNamePath, field)
+			}
+
+			var fieldPath []int
+			if path != nil && !isSliceType(field.Type) {
+				fieldPath = appendPath(path, field.Index)
 			}
 
-			mp.buildString(field.ModelInfo, joinModelsMap)
+			// columns of nested relations of a coalesced relation are coalesced as well
+			coalescing := mp.coalescing
+			mp.coalescing = coalescing || mp.coalesce || joinModel.C
+
+			mp.buildString(field.ModelInfo, fieldDBAlias, joinModelsMap, fieldPath, fieldNamePath, joinModel.W)
+
+			mp.coalescing = coalescing
 
 			continue
 		}
 
-		// write first part with db alias - 'users.id'
-		_, err := mp.bytesBuffer.WriteString(model.DBAlias)
-		mp.handleBuilderErr(err, model.DBAlias)
+		// write only columns (e.g. password hashes) are never selected, columns of wildcard models are selected by '*'
+		if field.IsWriteOnly || wildcard {
+			continue
+		}
 
-		_, _ = mp.bytesBuffer.WriteString(".")
+		// audit columns are written after the model's other columns
+		if mp.isAudit(field) {
+			audit = append(audit, field)
 
-		_, err = mp.bytesBuffer.WriteString(field.DBTag)
-		mp.handleBuilderErr(err, field.DBTag)
+			continue
+		}
+
+		mp.writeColumn(model, field, dbAlias, path)
+	}
 
-		// if this is the inner struct then write the second part - 'users_meta.user_id -->AS "um.user_id"<--'
-		if model.ModelsPrefix != "" {
-			_, _ = mp.bytesBuffer.WriteString(" AS \"")
+	for _, field := range audit {
+		mp.writeColumn(model, field, dbAlias, path)
+	}
+}
 
-			_, err = mp.bytesBuffer.WriteString(model.ModelsPrefix)
-			mp.handleBuilderErr(err, model.ModelsPrefix)
+// writeColumn writes the column of the model's field to the buffer, e.g. 'users_meta.user_id AS "um.user_id"'
+func (mp *ModelFieldsPrefixer) writeColumn(model *ModelInfo, field *FieldInfo, dbAlias string, path []int) {
+	// fields of STRUCT columns belong to the table of the column - 'u.address'
+	tableAlias, _, _ := strings.Cut(dbAlias, ".")
+	mp.addAlias(tableAlias)
 
-			_, _ = mp.bytesBuffer.WriteString(".")
+	start := mp.bytesBuffer.Len()
 
-			_, err = mp.bytesBuffer.WriteString(field.DBTag)
-			mp.handleBuilderErr(err, field.DBTag)
+	var err error
 
-			_, _ = mp.bytesBuffer.WriteString("\"")
+	scanAlias := mp.scanAlias(model, field)
+
+	// localized fields select the column of the locale aliased back to the field's name - 'u.name_ru AS "name"'
+	columnName := mp.localizedColumn(field)
+
+	// masked columns are written as masking expressions unless Unmasked is passed - 'CONCAT(LEFT(u.ssn, 3), '***')',
+	// encrypted ones are wrapped into the decrypting expression - 'pgp_sym_decrypt(u.ssn, $1)'
+	isMasked := field.IsMasked && !mp.unmasked
+	isEncrypted := field.IsEncrypted && mp.decryptExpr != ""
+
+	args := len(mp.args)
+
+	// columns of coalesced relations are wrapped with the zero value of the field's type - 'COALESCE(um.age, 0)'
+	zero, isCoalesced := mp.coalesceDefault(field)
+	if isCoalesced {
+		_, _ = mp.bytesBuffer.WriteString("COALESCE(")
+	}
+
+	if isMasked || isEncrypted {
+		column := strings.ReplaceAll(field.Expr, exprAliasPlaceholder, dbAlias)
+		if column == "" {
+			column = mp.quoteTableAlias(dbAlias) + "." + mp.quoteColumn(columnName)
+		}
+
+		expr := column
+		if isEncrypted {
+			expr = mp.decrypt(expr)
+		}
+
+		if isMasked {
+			expr = mp.mask(expr)
 		}
 
-		_, _ = mp.bytesBuffer.WriteString(", ")
+		_, err = mp.bytesBuffer.WriteString(expr)
+		mp.handleBuilderErr(err, expr)
+	} else if field.Count != nil {
+		// relation counts are correlated subqueries - '(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id)'
+		expr := mp.countExpr(field.Count, dbAlias)
+
+		_, err = mp.bytesBuffer.WriteString(expr)
+		mp.handleBuilderErr(err, expr)
+	} else if field.Expr != "" {
+		// expression columns are written as is with {alias} replaced by the alias of the table - 'CONCAT(u.first_name, ...)'
+		expr := strings.ReplaceAll(field.Expr, exprAliasPlaceholder, dbAlias)
+
+		_, err = mp.bytesBuffer.WriteString(expr)
+		mp.handleBuilderErr(err, expr)
+	} else {
+		// write first part with db alias - 'users.id', reserved words are quoted - 'users."order"'
+		tableAlias := mp.quoteTableAlias(dbAlias)
+
+		_, err = mp.bytesBuffer.WriteString(tableAlias)
+		mp.handleBuilderErr(err, tableAlias)
+
+		_, _ = mp.bytesBuffer.WriteString(".")
+
+		column := mp.quoteColumn(columnName)
+
+		_, err = mp.bytesBuffer.WriteString(column)
+		mp.handleBuilderErr(err, column)
+	}
+
+	if isCoalesced {
+		_, _ = mp.bytesBuffer.WriteString(", " + zero + ")")
+	}
+
+	// columns of root models are aliased with the alias of the table with WithRootAlias - 'u.id AS "u.id"'
+	if mp.rootAliasing && model.ModelsPrefix == "" && field.ScanAlias == "" {
+		scanAlias = mp.rootScanAlias(dbAlias, scanAlias)
 	}
+
+	// aliases exceeding the identifier limit of the dialect are shortened, names colliding with the names of the written
+	// columns are made unique - 'm.id AS "id_2"', columns aren't aliased at all with WithoutAliases
+	var fullAlias string
+	if !mp.noAliases {
+		scanAlias, fullAlias = mp.limitScanAlias(scanAlias)
+		scanAlias = mp.uniqueScanAlias(scanAlias)
+	}
+
+	// if this is the inner struct, the column has its own alias or it is an expression then write the second part
+	// quoted by the dialect - 'users_meta.user_id -->AS "um.user_id"<--'
+	if mp.noAliases {
+		scanAlias = ""
+	} else if scanAlias != field.DBTag || field.Expr != "" || field.Count != nil || isMasked || isEncrypted || isCoalesced || columnName != field.DBTag {
+		mp.writeAlias(scanAlias)
+	} else {
+		scanAlias = ""
+	}
+
+	column := columnInfo{
+		field:     field,
+		start:     start,
+		end:       mp.bytesBuffer.Len(),
+		dbAlias:   dbAlias,
+		scanAlias: scanAlias,
+		isExpr:    isMasked || isEncrypted || isCoalesced,
+		args:      len(mp.args) - args,
+		fullAlias: fullAlias,
+	}
+	if columnName != field.DBTag {
+		column.column = columnName
+	}
+	if path != nil {
+		column.path = appendPath(path, field.Index)
+	}
+
+	mp.columns = append(mp.columns, column)
+
+	_, _ = mp.bytesBuffer.WriteString(", ")
 }
 
-func (mp *ModelFieldsPrefixer) getJoinModelsMap(args ...any) map[string]M {
-	joinModelsMap := make(map[string]M)
+// appendPath returns a copy of the path with the index appended, so paths of different fields never share memory
+func appendPath(path []int, index int) []int {
+	newPath := make([]int, len(path), len(path)+1)
+	copy(newPath, path)
 
-	for i := 0; i < len(args); i += 2 {
-		model := M{
-			N: reflect.TypeOf(args[i]).Name(),
-			A: args[i+1].(string),
+	return append(newPath, index)
+}
+
+// getJoinModels parses join models passed to Columns, they are either M values or pairs of a model and its db alias
+func (mp *ModelFieldsPrefixer) getJoinModels(args ...any) []M {
+	joinModels := make([]M, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		if model, ok := args[i].(M); ok {
+			joinModels = append(joinModels, model)
+
+			continue
+		}
+
+		if opt, ok := args[i].(ColumnsOption); ok {
+			opt(mp)
+
+			continue
 		}
 
+		if i+1 >= len(args) {
+			break
+		}
+
+		dbAlias, _ := args[i+1].(string)
+
+		joinModels = append(joinModels, M{
+			N: reflect.TypeOf(args[i]).Name(),
+			A: dbAlias,
+		})
+
+		i++
+	}
+
+	return append(joinModels, mp.callJoins...)
+}
+
+func (mp *ModelFieldsPrefixer) getJoinModelsMap(joinModels []M) map[string]M {
+	if len(joinModels) == 0 {
+		return nil
+	}
+
+	joinModelsMap := make(map[string]M, len(joinModels))
+
+	for _, model := range joinModels {
 		if model.N == "" {
 			continue
 		}
@@ -201,11 +932,18 @@ func (mp *ModelFieldsPrefixer) collectCache(t reflect.Type, modelInfo *ModelInfo
 
 	numField := t.NumField()
 
+	// skipped are exported fields without db tags, they are reported only for models which have columns
+	var skipped []string
+
+	// counts are the relations counted by fields marked with 'count' tag option, they are resolved after all the fields
+	var counts map[*FieldInfo]string
+
 	if modelInfo == nil {
 		modelInfo = &ModelInfo{
 			Name:         modelName,
 			DBAlias:      dbTableAlias,
 			ModelsPrefix: modelsPrefix,
+			Table:        tableName(t),
 			Fields:       make([]*FieldInfo, 0, numField),
 		}
 	}
@@ -213,138 +951,246 @@ func (mp *ModelFieldsPrefixer) collectCache(t reflect.Type, modelInfo *ModelInfo
 	for i := 0; i < numField; i++ {
 		field := t.Field(i)
 
-		dbTag := field.Tag.Get("db")
+		dbTag, dbTagOptions := mp.columnName(field)
 		if dbTag == "" || dbTag == "-" {
+			if dbTag == "" && field.IsExported() && !field.Anonymous {
+				skipped = append(skipped, field.Name)
+			}
+
 			continue
 		}
 
 		isAnyDBTag = true
 
-		fieldType := field.Type
-		if fieldType.Kind() == reflect.Ptr {
-			fieldType = fieldType.Elem()
-		}
+		// relations are structs possibly wrapped into pointers, slices and arrays, e.g. *[]T or [][]*T
+		elemType, _ := relationElemType(field.Type)
 
-		fieldTypeName := fieldType.Name()
-		pkgPath := fieldType.PkgPath()
-		excludeKey := pkgPath + "." + fieldTypeName
-		_, isExcluded := mp.excludeScanning[excludeKey]
+		excludeKey := elemType.PkgPath() + "." + elemType.Name()
+		isExcluded := mp.cache.isExcluded(excludeKey)
 
-		fieldInfo := &FieldInfo{
-			DBTag: dbTag,
+		// the default alias of the nested model's table is declared with dbalias tag, e.g. `db:"meta" dbalias:"um"`,
+		// otherwise it is the db tag of the relation
+		relationAlias := dbTag
+		if alias := field.Tag.Get(aliasTagName); alias != "" {
+			relationAlias = alias
 		}
 
-		switch fieldType.Kind() {
-		case reflect.Ptr:
-			if fieldType.Elem().Kind() == reflect.Struct && !isExcluded {
-				var innerModel *ModelInfo
+		// structs scanning themselves (e.g. sql.NullString) and fields marked with 'noscan' or 'json' tag options
+		// (e.g. JSONB payloads) are columns, not nested models
+		isJSON := hasTagOption(dbTagOptions, "json")
+		isExcluded = isExcluded || isScannerType(elemType) || hasTagOption(dbTagOptions, "noscan") || isJSON
+
+		tag := Tag{Name: dbTag, Options: dbTagOptions}
+
+		info := tag.FieldInfo()
+		fieldInfo := &info
+		fieldInfo.Name = field.Name
+		fieldInfo.Index = i
+		fieldInfo.Type = field.Type
 
-				modelsPrefixToPass := dbTag
+		if order := tag.Value("order"); order != "" && fieldInfo.Order == 0 {
+			mp.warn("order tag option must be a positive number", "model", modelName, "field", field.Name, "order", order)
+		}
+
+		if isJSON {
+			// keys of JSON objects are selected as columns, other JSON values (e.g. arrays) are selected as is
+			if _, depth := relationElemType(field.Type); depth == 0 && elemType.Kind() == reflect.Struct {
+				jsonPrefix := dbTag
 				if modelsPrefix != "" {
-					modelsPrefixToPass = modelsPrefix + "." + dbTag
+					jsonPrefix = modelsPrefix + "." + dbTag
 				}
 
-				innerModel, isAnyDBTag = mp.collectCache(fieldType.Elem(), innerModel, dbTag, modelsPrefixToPass)
+				fieldInfo.ModelInfo = mp.collectJSON(elemType, jsonPrefix, make(map[reflect.Type]bool))
+			}
+		}
 
-				if !isAnyDBTag {
-					mp.excludeScanning[excludeKey] = struct{}{}
+		if elemType.Kind() == reflect.Struct && !isExcluded {
+			modelsPrefixToPass := dbTag
+			if modelsPrefix != "" {
+				modelsPrefixToPass = modelsPrefix + "." + dbTag
+			}
 
-					break
-				}
+			innerModel, isAnyInnerDBTag := mp.collectCache(elemType, nil, relationAlias, modelsPrefixToPass)
 
+			if isAnyInnerDBTag {
 				fieldInfo.IsStruct = true
 				fieldInfo.ModelInfo = innerModel
+			} else {
+				mp.debugLog("type is excluded from scanning, it has no db tags", "type", excludeKey)
+
+				mp.cache.exclude(excludeKey)
 			}
+		}
 
-		case reflect.Struct:
-			if !isExcluded {
-				var innerModel *ModelInfo
+		// time.Time is a column without being excluded explicitly
+		if elemType.Kind() == reflect.Struct && !fieldInfo.IsStruct && !isJSON && elemType != timeType &&
+			!isScannerType(elemType) && !hasTagOption(dbTagOptions, "noscan") {
+			mp.warnKind(WarningExcludedType, "struct is excluded from scanning, the field is a column",
+				"model", modelName, "field", field.Name, "type", excludeKey)
+		}
 
-				modelsPrefixToPass := dbTag
-				if modelsPrefix != "" {
-					modelsPrefixToPass = modelsPrefix + "." + dbTag
-				}
+		if relation := tagOptionValue(dbTagOptions, "count"); relation != "" {
+			if counts == nil {
+				counts = make(map[*FieldInfo]string)
+			}
 
-				innerModel, isAnyDBTag = mp.collectCache(fieldType, innerModel, dbTag, modelsPrefixToPass)
+			counts[fieldInfo] = relation
+		}
 
-				if !isAnyDBTag {
-					mp.excludeScanning[excludeKey] = struct{}{}
+		modelInfo.Fields = append(modelInfo.Fields, fieldInfo)
+	}
 
-					break
-				}
+	if len(counts) > 0 {
+		mp.resolveCounts(modelInfo, counts)
+	}
 
-				fieldInfo.IsStruct = true
-				fieldInfo.ModelInfo = innerModel
-			}
+	if isAnyDBTag {
+		for _, name := range skipped {
+			mp.warnKind(WarningSkippedField, "field has no db tag", "model", modelName, "field", name)
+		}
+	}
 
-		case reflect.Slice:
-			elemType := fieldType.Elem()
+	return modelInfo, isAnyDBTag
+}
 
-			// []Struct
-			if elemType.Kind() == reflect.Struct && !isExcluded {
-				var innerModel *ModelInfo
+// parseDBTag splits db tag into the column name and its options, e.g. 'id,pk' gives 'id' and ['pk']
+func parseDBTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
 
-				modelsPrefixToPass := dbTag
-				if modelsPrefix != "" {
-					modelsPrefixToPass = modelsPrefix + "." + dbTag
-				}
+	// expressions contain commas themselves, so 'expr' option takes the rest of the tag
+	for i := 1; i < len(parts); i++ {
+		if strings.HasPrefix(strings.TrimSpace(parts[i]), "expr=") {
+			parts = append(parts[:i], strings.Join(parts[i:], ","))
 
-				innerModel, isAnyDBTag = mp.collectCache(elemType, nil, dbTag, modelsPrefixToPass)
+			break
+		}
+	}
 
-				if !isAnyDBTag {
-					mp.excludeScanning[excludeKey] = struct{}{}
+	return strings.TrimSpace(parts[0]), parts[1:]
+}
 
-					break
-				}
+func hasTagOption(options []string, option string) bool {
+	for _, o := range options {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
 
-				fieldInfo.IsStruct = true
-				fieldInfo.ModelInfo = innerModel
-			}
+	return false
+}
 
-			// []*Struct
-			if elemType.Kind() == reflect.Ptr && elemType.Elem().Kind() == reflect.Struct && !isExcluded {
-				var innerModel *ModelInfo
+// tagOptionValue returns the value of 'name=value' tag option or empty string if there is no such option
+func tagOptionValue(options []string, name string) string {
+	for _, o := range options {
+		key, value, ok := strings.Cut(strings.TrimSpace(o), "=")
+		if ok && key == name {
+			return value
+		}
+	}
 
-				modelsPrefixToPass := dbTag
-				if modelsPrefix != "" {
-					modelsPrefixToPass = modelsPrefix + "." + dbTag
-				}
+	return ""
+}
 
-				innerModel, isAnyDBTag = mp.collectCache(elemType.Elem(), nil, dbTag, modelsPrefixToPass)
+func (mp *ModelFieldsPrefixer) InQuery(query string) string {
+	return mp.renderQuery(query, true)
+}
 
-				if !isAnyDBTag {
-					mp.excludeScanning[excludeKey] = struct{}{}
+// renderQuery renders the query as InQuery, the sqlcommenter comment is appended only if comment is true, so bodies
+// of CTEs and subqueries are rendered without it and only the outermost query is commented
+func (mp *ModelFieldsPrefixer) renderQuery(query string, comment bool) string {
+	if mp.bytesBuffer == nil {
+		return ""
+	}
 
-					break
-				}
+	span := mp.startSpan(SpanRender)
+	defer mp.endRenderSpan(span)
 
-				fieldInfo.IsStruct = true
-				fieldInfo.ModelInfo = innerModel
-			}
+	// templates are split around placeholders once, so rendering them is concatenation
+	compiled := mp.cache.templates.get(query)
 
-		default:
-		}
+	if mp.ByteLen() > 0 && !compiled.hasColumns {
+		mp.fail(fmt.Errorf("%w: %s", prefixererr.ErrPlaceholderMissing, prefixedColumnsPlaceholder), "query", query)
+	}
 
-		modelInfo.Fields = append(modelInfo.Fields, fieldInfo)
+	values := mp.queryValues(compiled.hasTables)
+	if !comment {
+		values.comment = ""
 	}
 
-	return modelInfo, isAnyDBTag
+	return renderQuery(compiled, values)
 }
 
-func (mp *ModelFieldsPrefixer) InQuery(query string) string {
-	if mp.bytesBuffer == nil {
+// Joins returns lateral joins of the join models which are joined from function calls (M.F),
+// e.g. 'LEFT JOIN LATERAL get_user_stats(u.id) s ON true' or 'OUTER APPLY get_user_stats(u.id) s' in MSSQL and Oracle
+func (mp *ModelFieldsPrefixer) Joins() string {
+	if len(mp.lateralJoins) == 0 {
 		return ""
 	}
 
-	return strings.ReplaceAll(query, prefixedColumnsPlaceholder, mp.String())
+	joins := make([]string, 0, len(mp.lateralJoins))
+
+	for _, joinModel := range mp.lateralJoins {
+		join, _ := mp.lateralJoin(joinModel)
+		joins = append(joins, join)
+	}
+
+	return strings.Join(joins, "\n")
 }
 
+// String returns the built columns list. It doesn't change the builder, so it returns the same value on every call
 func (mp *ModelFieldsPrefixer) String() string {
-	if mp.bytesBuffer == nil || mp.bytesBuffer.Len() == 0 {
+	if mp.bytesBuffer == nil {
 		return ""
 	}
 
-	mp.bytesBuffer.Truncate(mp.bytesBuffer.Len() - 2)
+	// columns restored from the rendered columns cache are returned without copying
+	if mp.rendered != nil {
+		return mp.rendered.String()
+	}
+
+	return string(mp.bytesBuffer.Bytes()[:mp.ByteLen()])
+}
+
+// ColumnCount returns the number of columns written to the builder including custom ones
+func (mp *ModelFieldsPrefixer) ColumnCount() int {
+	return len(mp.columns)
+}
+
+// ByteLen returns the length of the built columns list in bytes
+func (mp *ModelFieldsPrefixer) ByteLen() int {
+	if mp.bytesBuffer == nil || mp.bytesBuffer.Len() == 0 {
+		return 0
+	}
+
+	// every column in the buffer is followed by the separator, the last one is not a part of the list
+	return mp.bytesBuffer.Len() - 2
+}
+
+// Aliases returns db aliases of the tables involved in the built columns list in order of their appearance
+func (mp *ModelFieldsPrefixer) Aliases() []string {
+	aliases := make([]string, len(mp.aliases))
+	copy(aliases, mp.aliases)
+
+	return aliases
+}
+
+func (mp *ModelFieldsPrefixer) addAlias(dbAlias string) {
+	for _, alias := range mp.aliases {
+		if alias == dbAlias {
+			return
+		}
+	}
+
+	mp.aliases = append(mp.aliases, dbAlias)
+}
+
+// Slice returns the built columns as separate expressions, e.g. ["u.id", "um.city AS \"um.city\""]
+func (mp *ModelFieldsPrefixer) Slice() []string {
+	columns := make([]string, 0, len(mp.columns))
+
+	for _, column := range mp.columns {
+		columns = append(columns, string(mp.bytesBuffer.Bytes()[column.start:column.end]))
+	}
 
-	return mp.bytesBuffer.String()
+	return columns
 }
@@ -0,0 +1,99 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func BenchmarkColumns(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		args []any
+	}{
+		{"default aliases", []any{User{}, "u"}},
+		{"join models", []any{User{}, "u", mfp.M{N: "Meta", A: "x"}}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			mp := mfp.New()
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p := mp.Acquire()
+				p.Columns(bm.args...)
+				p.Release()
+			}
+		})
+	}
+}
+
+func BenchmarkInQuery(b *testing.B) {
+	const query = "SELECT {columns} FROM users u JOIN user_meta um ON um.id = u.id"
+
+	benchmarks := []struct {
+		name   string
+		render func(p *mfp.ModelFieldsPrefixer) int
+	}{
+		{"InQuery", func(p *mfp.ModelFieldsPrefixer) int {
+			return len(p.InQuery(query))
+		}},
+		{"Bytes", func(p *mfp.ModelFieldsPrefixer) int {
+			return len(p.Bytes())
+		}},
+		{"AppendTo", func(p *mfp.ModelFieldsPrefixer) int {
+			return len(p.AppendTo(make([]byte, 0, 256)))
+		}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			mp := mfp.New()
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p := mp.Acquire()
+				bm.render(p.Columns(User{}, "u"))
+				p.Release()
+			}
+		})
+	}
+}
+
+func TestJoins(t *testing.T) {
+	tests := []struct {
+		dialect mfp.Dialect
+		joins   string
+		err     error
+	}{
+		{mfp.DialectPostgres, "LEFT JOIN LATERAL get_meta(u.id) s ON true", nil},
+		{mfp.DialectMySQL, "LEFT JOIN LATERAL get_meta(u.id) s ON true", nil},
+		{mfp.DialectMSSQL, "OUTER APPLY get_meta(u.id) s", nil},
+		{mfp.DialectOracle, "OUTER APPLY get_meta(u.id) s", nil},
+		{mfp.DialectSQLite, "", prefixererr.ErrUnsupportedJoin},
+		{mfp.DialectBigQuery, "", prefixererr.ErrUnsupportedJoin},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			mp := mfp.New(mfp.WithDialect(test.dialect), mfp.Strict()).Columns(User{}, "u", mfp.M{N: "Meta", A: "s", F: "get_meta(u.id)"})
+
+			if joins := mp.Joins(); joins != test.joins {
+				t.Errorf("Joins() = %q, want %q", joins, test.joins)
+			}
+
+			if joins := mp.Result().Joins(); joins != test.joins {
+				t.Errorf("Result().Joins() = %q, want %q", joins, test.joins)
+			}
+
+			if err := mp.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package prefixererr defines errors of model-fields-prefixer, they are wrapped with details of the failure,
+// so check them with errors.Is:
+//
+//	if errors.Is(m.Columns(User{}, "u").Err(), prefixererr.ErrNoDBTags) { ... }
+package prefixererr
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotStruct is returned if the model or the filter is not a struct or a pointer to a struct
+	ErrNotStruct = errors.New("model is not a struct")
+	// ErrNoDBTags is returned if the model has no columns, e.g. its fields have no db tags
+	ErrNoDBTags = errors.New("model has no db tags")
+	// ErrAliasCollision is returned if several tables of the query have the same alias
+	ErrAliasCollision = errors.New("alias is used by several tables")
+	// ErrDuplicateScanAlias is returned if several columns have the same name in the result set, later ones are renamed
+	ErrDuplicateScanAlias = errors.New("scan alias is used by several columns")
+	// ErrUnknownJoinModel is returned if the join model doesn't match any relation of the model
+	ErrUnknownJoinModel = errors.New("join model doesn't match any relation")
+	// ErrNotPrebuilt is returned if the binary is built with prefixer_static tag and the model isn't in the cache
+	ErrNotPrebuilt = errors.New("model is not prebuilt")
+	// ErrPlaceholderMissing is returned if the query has no placeholder the built columns must be put in
+	ErrPlaceholderMissing = errors.New("query has no placeholder")
+	// ErrPlaceholderUnbound is returned if the query has a placeholder which isn't replaced, e.g. a typo like '{colums}'
+	ErrPlaceholderUnbound = errors.New("query has unbound placeholder")
+	// ErrUnknownColumn is returned if the column passed to Values, OrderBy or Paginate or the column of the filter
+	// passed to Where doesn't match any field of the model
+	ErrUnknownColumn = errors.New("column doesn't match any field")
+	// ErrInvalidDirection is returned if the sort direction passed to OrderBy or Paginate isn't ASC, DESC or
+	// NULLS FIRST/LAST, e.g. an injection attempt like 'name DESC; DROP TABLE users'
+	ErrInvalidDirection = errors.New("sort direction is not valid")
+	// ErrUnexportedField is returned if the column passed to Values is the unexported field, its value can't be read
+	ErrUnexportedField = errors.New("field of the column is unexported")
+	// ErrNoColumns is returned if the query is rendered while no columns are built
+	ErrNoColumns = errors.New("no columns are built")
+	// ErrUnsupportedJoin is returned if the join model is joined from a function call (M.F) while the dialect has
+	// no lateral joins, e.g. SQLite or BigQuery
+	ErrUnsupportedJoin = errors.New("lateral join isn't supported by the dialect")
+	// ErrNoAlias is returned if the model passed to WithCTE has neither the alias nor the table, so its columns can't
+	// be prefixed, pass Root with the alias instead
+	ErrNoAlias = errors.New("model has no alias")
+	// ErrConflictingLocking is returned if the lock options passed to Locking exclude each other, e.g. NoWait and
+	// SkipLocked
+	ErrConflictingLocking = errors.New("lock options are conflicting")
+	// ErrUnsupportedLocking is returned if the dialect has no row locks or doesn't support the lock option, e.g.
+	// ForShare in Oracle
+	ErrUnsupportedLocking = errors.New("locking isn't supported by the dialect")
+	// ErrNullKey is returned by Collect if the row has NULL pk columns of the root model, so it can't be grouped
+	ErrNullKey = errors.New("key of the model is NULL")
+)
@@ -0,0 +1,160 @@
+// Package prefixerexplain explains queries with {columns} placeholder while debugging, so queries built by the prefixer
+// are profiled without copying them out of logs and editing them by hand.
+//
+// Helpers accept any value with QueryContext method of database/sql, e.g. *sql.DB, *sql.Tx or *sql.Conn
+package prefixerexplain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// Querier is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Plan is the plan of the query reported by the database
+type Plan struct {
+	// Query is the explained query with expanded placeholders
+	Query string
+	// JSON is the plan reported by PostgreSQL and MySQL as is, empty in SQLite
+	JSON json.RawMessage
+	// Root is the root node of the plan of PostgreSQL, nil in other databases
+	Root *Node
+	// PlanningTime and ExecutionTime are reported by PostgreSQL in milliseconds
+	PlanningTime  float64
+	ExecutionTime float64
+	// Steps are the details of EXPLAIN QUERY PLAN steps of SQLite in order of the rows
+	Steps []string
+}
+
+// Node is the node of the plan of PostgreSQL, costs are in the planner's units and times are in milliseconds
+type Node struct {
+	Type            string  `json:"Node Type"`
+	Relation        string  `json:"Relation Name"`
+	Alias           string  `json:"Alias"`
+	Index           string  `json:"Index Name"`
+	StartupCost     float64 `json:"Startup Cost"`
+	TotalCost       float64 `json:"Total Cost"`
+	PlanRows        float64 `json:"Plan Rows"`
+	ActualTotalTime float64 `json:"Actual Total Time"`
+	ActualRows      float64 `json:"Actual Rows"`
+	Loops           float64 `json:"Actual Loops"`
+	Plans           []Node  `json:"Plans"`
+}
+
+// Explain expands the placeholders of the query with the columns built by mp (see InQuery), prepends EXPLAIN statement
+// of mp's dialect to it and returns the parsed plan: 'EXPLAIN (ANALYZE, FORMAT JSON)' in PostgreSQL,
+// 'EXPLAIN FORMAT=JSON' in MySQL and 'EXPLAIN QUERY PLAN' in SQLite. Other dialects are not supported.
+// PostgreSQL executes the query to measure it, so explain modifying queries in transactions rolled back afterwards
+func Explain(ctx context.Context, db Querier, mp *mfp.ModelFieldsPrefixer, query string, args ...any) (Plan, error) {
+	plan := Plan{Query: mp.InQuery(query)}
+
+	switch name := mp.Dialect().Name(); name {
+	case "postgres":
+		return plan, explainPostgres(ctx, db, &plan, args)
+	case "mysql":
+		return plan, explainJSON(ctx, db, &plan, "EXPLAIN FORMAT=JSON ", args)
+	case "sqlite":
+		return plan, explainSQLite(ctx, db, &plan, args)
+	default:
+		return plan, fmt.Errorf("failed to explain query: dialect %s is not supported", name)
+	}
+}
+
+func explainPostgres(ctx context.Context, db Querier, plan *Plan, args []any) error {
+	if err := explainJSON(ctx, db, plan, "EXPLAIN (ANALYZE, FORMAT JSON) ", args); err != nil {
+		return err
+	}
+
+	var plans []struct {
+		Plan          Node    `json:"Plan"`
+		PlanningTime  float64 `json:"Planning Time"`
+		ExecutionTime float64 `json:"Execution Time"`
+	}
+
+	if err := json.Unmarshal(plan.JSON, &plans); err != nil {
+		return fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	if len(plans) == 0 {
+		return errors.New("failed to parse plan: plan is empty")
+	}
+
+	plan.Root = &plans[0].Plan
+	plan.PlanningTime = plans[0].PlanningTime
+	plan.ExecutionTime = plans[0].ExecutionTime
+
+	return nil
+}
+
+// explainJSON runs the explain statement reporting the plan as JSON in the first column of a single row
+func explainJSON(ctx context.Context, db Querier, plan *Plan, explain string, args []any) error {
+	rows, err := db.QueryContext(ctx, explain+plan.Query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to explain query: %w", err)
+		}
+
+		return errors.New("failed to explain query: no plan is returned")
+	}
+
+	var raw []byte
+
+	dest := make([]any, len(columns))
+	dest[0] = &raw
+
+	for i := 1; i < len(dest); i++ {
+		dest[i] = new(any)
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return fmt.Errorf("failed to scan plan: %w", err)
+	}
+
+	plan.JSON = json.RawMessage(raw)
+
+	return rows.Err()
+}
+
+func explainSQLite(ctx context.Context, db Querier, plan *Plan, args []any) error {
+	rows, err := db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+plan.Query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id, parent, unused int
+			detail             string
+		)
+
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			return fmt.Errorf("failed to scan plan: %w", err)
+		}
+
+		plan.Steps = append(plan.Steps, strings.TrimSpace(detail))
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,209 @@
+package prefixerexplain_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixerexplain"
+)
+
+type User struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+const pgPlan = `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "users", "Alias": "u", "Index Name": "users_pkey",
+	"Total Cost": 8.3, "Actual Rows": 1, "Actual Loops": 1, "Plans": [{"Node Type": "Seq Scan"}]},
+	"Planning Time": 0.1, "Execution Time": 0.02}]`
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   mfp.Dialect
+		columns   []string
+		rows      [][]driver.Value
+		dbErr     error
+		wantQuery string
+		want      prefixerexplain.Plan
+		wantErr   string
+	}{
+		{
+			name:      "postgres",
+			dialect:   mfp.DialectPostgres,
+			columns:   []string{"QUERY PLAN"},
+			rows:      [][]driver.Value{{pgPlan}},
+			wantQuery: "EXPLAIN (ANALYZE, FORMAT JSON) SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			want: prefixerexplain.Plan{
+				JSON: json.RawMessage(pgPlan),
+				Root: &prefixerexplain.Node{
+					Type: "Index Scan", Relation: "users", Alias: "u", Index: "users_pkey", TotalCost: 8.3, ActualRows: 1, Loops: 1,
+					Plans: []prefixerexplain.Node{{Type: "Seq Scan"}},
+				},
+				PlanningTime:  0.1,
+				ExecutionTime: 0.02,
+			},
+		},
+		{
+			name:      "mysql",
+			dialect:   mfp.DialectMySQL,
+			columns:   []string{"EXPLAIN", "warnings"},
+			rows:      [][]driver.Value{{`{"query_block": {"select_id": 1}}`, nil}},
+			wantQuery: "EXPLAIN FORMAT=JSON SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			want:      prefixerexplain.Plan{JSON: json.RawMessage(`{"query_block": {"select_id": 1}}`)},
+		},
+		{
+			name:      "sqlite",
+			dialect:   mfp.DialectSQLite,
+			columns:   []string{"id", "parent", "notused", "detail"},
+			rows:      [][]driver.Value{{int64(2), int64(0), int64(0), "SEARCH u USING INTEGER PRIMARY KEY (rowid=?) "}, {int64(5), int64(0), int64(0), "USE TEMP B-TREE FOR ORDER BY"}},
+			wantQuery: "EXPLAIN QUERY PLAN SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			want:      prefixerexplain.Plan{Steps: []string{"SEARCH u USING INTEGER PRIMARY KEY (rowid=?)", "USE TEMP B-TREE FOR ORDER BY"}},
+		},
+		{
+			name:    "unsupported dialect",
+			dialect: mfp.DialectMSSQL,
+			wantErr: "failed to explain query: dialect mssql is not supported",
+		},
+		{
+			name:      "database failure",
+			dialect:   mfp.DialectPostgres,
+			dbErr:     errors.New("relation \"users\" does not exist"),
+			wantQuery: "EXPLAIN (ANALYZE, FORMAT JSON) SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			wantErr:   "failed to explain query: relation \"users\" does not exist",
+		},
+		{
+			name:      "no plan",
+			dialect:   mfp.DialectMySQL,
+			columns:   []string{"EXPLAIN"},
+			wantQuery: "EXPLAIN FORMAT=JSON SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			wantErr:   "failed to explain query: no plan is returned",
+		},
+		{
+			name:      "empty plan",
+			dialect:   mfp.DialectPostgres,
+			columns:   []string{"QUERY PLAN"},
+			rows:      [][]driver.Value{{"[]"}},
+			wantQuery: "EXPLAIN (ANALYZE, FORMAT JSON) SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			want:      prefixerexplain.Plan{JSON: json.RawMessage("[]")},
+			wantErr:   "failed to parse plan: plan is empty",
+		},
+		{
+			name:      "malformed plan",
+			dialect:   mfp.DialectPostgres,
+			columns:   []string{"QUERY PLAN"},
+			rows:      [][]driver.Value{{"{"}},
+			wantQuery: "EXPLAIN (ANALYZE, FORMAT JSON) SELECT u.id, u.name FROM users u WHERE u.id = $1",
+			want:      prefixerexplain.Plan{JSON: json.RawMessage("{")},
+			wantErr:   "failed to parse plan",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &stubConn{columns: test.columns, rows: test.rows, err: test.dbErr}
+			db := sql.OpenDB(stubConnector{conn: conn})
+			defer db.Close()
+
+			mp := mfp.New(mfp.WithDialect(test.dialect)).Columns(User{}, "u")
+
+			plan, err := prefixerexplain.Explain(context.Background(), db, mp, "SELECT {columns} FROM users u WHERE u.id = $1", 1)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("Explain() error = %v, want %q", err, test.wantErr)
+			}
+
+			if conn.query != test.wantQuery {
+				t.Errorf("executed query = %q, want %q", conn.query, test.wantQuery)
+			}
+
+			if test.wantQuery != "" && !reflect.DeepEqual(conn.args, []any{int64(1)}) {
+				t.Errorf("executed args = %v, want [1]", conn.args)
+			}
+
+			test.want.Query = "SELECT u.id, u.name FROM users u WHERE u.id = $1"
+			if !reflect.DeepEqual(plan, test.want) {
+				t.Errorf("Explain() = %+v, want %+v", plan, test.want)
+			}
+		})
+	}
+}
+
+// stubConnector connects database/sql to the stub connection returning the canned result of any query
+type stubConnector struct {
+	conn *stubConn
+}
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c stubConnector) Driver() driver.Driver {
+	return nil
+}
+
+type stubConn struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+	// query and args are the last executed query and its arguments
+	query string
+	args  []any
+}
+
+func (c *stubConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.query = query
+	c.args = nil
+
+	for _, arg := range args {
+		c.args = append(c.args, arg.Value)
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &stubRows{columns: c.columns, rows: c.rows}, nil
+}
+
+func (c *stubConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string {
+	return r.columns
+}
+
+func (r *stubRows) Close() error {
+	return nil
+}
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
@@ -0,0 +1,10 @@
+module github.com/ivnku/model-fields-prefixer/prefixergoqu
+
+go 1.21
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/doug-martin/goqu/v9 v9.19.0
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+)
@@ -0,0 +1,38 @@
+// Package prefixergoqu converts the columns built by the prefixer to goqu expressions,
+// so goqu users keep quoting and parameterization of their dialect
+package prefixergoqu
+
+import (
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// Columns returns the columns built by mp as goqu expressions to be passed to goqu.Select or SelectDataset.Select:
+// 'um.city AS "um.city"' becomes goqu.T("um").Col("city").As(goqu.C("um.city")), custom columns become literals
+func Columns(mp *mfp.ModelFieldsPrefixer) []any {
+	builtColumns := mp.BuiltColumns()
+
+	columns := make([]any, 0, len(builtColumns))
+
+	for _, column := range builtColumns {
+		columns = append(columns, Expression(column))
+	}
+
+	return columns
+}
+
+// Expression converts a single built column to goqu expression
+func Expression(column mfp.Column) exp.Expression {
+	if column.IsCustom() {
+		return goqu.L(column.Expr)
+	}
+
+	identifier := goqu.T(column.Table).Col(column.Name)
+	if column.Alias == "" {
+		return identifier
+	}
+
+	return identifier.As(goqu.C(column.Alias))
+}
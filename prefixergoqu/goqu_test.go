@@ -0,0 +1,120 @@
+package prefixergoqu_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixergoqu"
+)
+
+type User struct {
+	ID   int64     `db:"id"`
+	Name string    `db:"name"`
+	Meta *UserMeta `db:"meta" dbalias:"um"`
+}
+
+type UserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(mp *mfp.ModelFieldsPrefixer) *goqu.SelectDataset
+		prepared bool
+		want     string
+		args     []any
+	}{
+		{
+			name: "columns of the models",
+			build: func(mp *mfp.ModelFieldsPrefixer) *goqu.SelectDataset {
+				return goqu.From(goqu.T("users").As("u")).Select(prefixergoqu.Columns(mp.Columns(User{}, "u"))...)
+			},
+			want: `SELECT "u"."id", "u"."name", "um"."id" AS "meta.id", "um"."city" AS "meta.city" FROM "users" AS "u"`,
+		},
+		{
+			name: "custom columns",
+			build: func(mp *mfp.ModelFieldsPrefixer) *goqu.SelectDataset {
+				mp.Columns(UserMeta{}, "um").CustomColumns("LOWER(um.city) AS city_lower")
+
+				return goqu.From(goqu.T("user_meta").As("um")).Select(prefixergoqu.Columns(mp)...)
+			},
+			want: `SELECT "um"."id", "um"."city", LOWER(um.city) AS city_lower FROM "user_meta" AS "um"`,
+		},
+		{
+			name: "prepared statement",
+			build: func(mp *mfp.ModelFieldsPrefixer) *goqu.SelectDataset {
+				return goqu.Dialect("postgres").From(goqu.T("user_meta").As("um")).Select(prefixergoqu.Columns(mp.Columns(UserMeta{}, "um"))...).
+					Where(goqu.T("um").Col("city").Eq("Paris")).Prepared(true)
+			},
+			want: `SELECT "um"."id", "um"."city" FROM "user_meta" AS "um" WHERE ("um"."city" = $1)`,
+			args: []any{"Paris"},
+		},
+		{
+			name: "no columns",
+			build: func(mp *mfp.ModelFieldsPrefixer) *goqu.SelectDataset {
+				return goqu.From("users").Select(prefixergoqu.Columns(mp.Columns(1, "x"))...)
+			},
+			want: `SELECT * FROM "users"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := test.build(mfp.New()).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if query != test.want {
+				t.Errorf("ToSQL() = %q, want %q", query, test.want)
+			}
+
+			if !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("ToSQL() args = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
+
+func TestExpression(t *testing.T) {
+	tests := []struct {
+		name   string
+		column mfp.Column
+		want   string
+	}{
+		{
+			name:   "column of the root model",
+			column: mfp.Column{Table: "u", Name: "id", Expr: "u.id"},
+			want:   `SELECT "u"."id"`,
+		},
+		{
+			name:   "aliased column",
+			column: mfp.Column{Table: "um", Name: "city", Alias: "meta.city", Expr: `um.city AS "meta.city"`},
+			want:   `SELECT "um"."city" AS "meta.city"`,
+		},
+		{
+			name:   "custom column",
+			column: mfp.Column{Expr: "COUNT(*) AS total"},
+			want:   "SELECT COUNT(*) AS total",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, _, err := goqu.Select(prefixergoqu.Expression(test.column)).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if query != test.want {
+				t.Errorf("ToSQL() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
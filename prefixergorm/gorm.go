@@ -0,0 +1,45 @@
+// Package prefixergorm injects the columns built by the prefixer into GORM queries,
+// so GORM users can adopt the prefixer incrementally for complex read queries
+package prefixergorm
+
+import (
+	"gorm.io/gorm"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// defaultPrefixer keeps models metadata for GormScope
+var defaultPrefixer = mfp.New()
+
+// GormScope returns GORM scope selecting the columns of the model and its join models, lateral joins
+// of the join models joined from function calls (M.F) are added as well:
+//
+//	db.Table("users u").Joins("LEFT JOIN users_meta um ON um.user_id = u.id").
+//		Scopes(prefixergorm.GormScope(User{}, "u", mfp.M{N: "UserMeta", A: "um"})).
+//		Find(&users)
+func GormScope(model any, alias string, joins ...mfp.M) func(*gorm.DB) *gorm.DB {
+	return GormScopeWith(defaultPrefixer, model, alias, joins...)
+}
+
+// GormScopeWith is like GormScope but uses models metadata of mp
+func GormScopeWith(mp *mfp.ModelFieldsPrefixer, model any, alias string, joins ...mfp.M) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		args := make([]any, 0, len(joins)+2)
+		args = append(args, model, alias)
+
+		for _, join := range joins {
+			args = append(args, join)
+		}
+
+		// scopes may be applied concurrently, so every query gets its own builder
+		p := mp.AllocPrefixer().Columns(args...)
+
+		db = db.Select(p.String())
+
+		if lateralJoins := p.Joins(); lateralJoins != "" {
+			db = db.Joins(lateralJoins)
+		}
+
+		return db
+	}
+}
@@ -0,0 +1,112 @@
+package prefixergorm_test
+
+import (
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixergorm"
+)
+
+type User struct {
+	ID   int64     `db:"id"`
+	Name string    `db:"name"`
+	Meta *UserMeta `db:"meta" dbalias:"um" gorm:"-"`
+}
+
+type UserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+// stubDialector builds statements of PostgreSQL without a connection, queries are only rendered in dry run mode
+type stubDialector struct{}
+
+func (stubDialector) Name() string {
+	return "stub"
+}
+
+func (stubDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+
+	return nil
+}
+
+func (stubDialector) Migrator(*gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (stubDialector) DataTypeOf(*schema.Field) string {
+	return ""
+}
+
+func (stubDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (stubDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, _ any) {
+	writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func (stubDialector) QuoteTo(writer clause.Writer, str string) {
+	writer.WriteString(`"` + str + `"`)
+}
+
+func (stubDialector) Explain(sql string, _ ...any) string {
+	return sql
+}
+
+func TestGormScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		query func(db *gorm.DB) *gorm.DB
+		want  string
+	}{
+		{
+			name: "columns of the models",
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Table("users u").Joins("LEFT JOIN user_meta um ON um.id = u.id").
+					Scopes(prefixergorm.GormScope(User{}, "u", mfp.M{N: "Meta", A: "um"})).Where("u.id = ?", 1).Find(&[]User{})
+			},
+			want: `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u LEFT JOIN user_meta um ON um.id = u.id WHERE u.id = $1`,
+		},
+		{
+			name: "lateral join",
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Table("users u").Scopes(prefixergorm.GormScope(User{}, "u", mfp.M{N: "Meta", A: "m", F: "user_meta(u.id)"})).Find(&[]User{})
+			},
+			want: `SELECT u.id, u.name, m.id AS "meta.id", m.city AS "meta.city" FROM users u LEFT JOIN LATERAL user_meta(u.id) m ON true`,
+		},
+		{
+			name: "prefixer of the caller",
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Table("users u").Scopes(prefixergorm.GormScopeWith(mfp.New(mfp.WithDialect(mfp.DialectMySQL)), User{}, "u")).Find(&[]User{})
+			},
+			want: "SELECT u.id, u.name, um.id AS `meta.id`, um.city AS `meta.city` FROM users u",
+		},
+	}
+
+	db, err := gorm.Open(stubDialector{}, &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := test.query(db.Session(&gorm.Session{}))
+			if tx.Error != nil {
+				t.Fatalf("query error = %v", tx.Error)
+			}
+
+			if query := tx.Statement.SQL.String(); query != test.want {
+				t.Errorf("query = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/ivnku/model-fields-prefixer/prefixerotel
+
+go 1.25.0
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.44.0
+	go.opentelemetry.io/otel/trace v1.44.0
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
@@ -0,0 +1,67 @@
+// Package prefixerotel traces the prefixer with OpenTelemetry: spans of scanning models into the cache, Columns calls
+// and rendering queries carry the name of the model, the number of columns and whether the columns were taken from
+// the rendered columns cache
+package prefixerotel
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// tracer starts the prefixer's spans with the OpenTelemetry tracer
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// span is the OpenTelemetry span of the prefixer's operation
+type span struct {
+	span trace.Span
+}
+
+// NewTracer returns the tracer of the prefixer starting spans with t, e.g. otel.Tracer("model-fields-prefixer"):
+//
+//	mp := mfp.New(mfp.WithTracer(prefixerotel.NewTracer(otel.Tracer("model-fields-prefixer"))))
+func NewTracer(t trace.Tracer) mfp.Tracer {
+	return tracer{tracer: t}
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, mfp.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+
+	return ctx, span{span: s}
+}
+
+func (s span) End(attrs ...slog.Attr) {
+	if s.span.IsRecording() {
+		s.span.SetAttributes(attributes(attrs)...)
+	}
+
+	s.span.End()
+}
+
+// attributes converts the attributes of the prefixer to OpenTelemetry ones, values of other kinds are strings
+func attributes(attrs []slog.Attr) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr.Value.Kind() {
+		case slog.KindString:
+			kvs = append(kvs, attribute.String(attr.Key, attr.Value.String()))
+		case slog.KindInt64:
+			kvs = append(kvs, attribute.Int64(attr.Key, attr.Value.Int64()))
+		case slog.KindBool:
+			kvs = append(kvs, attribute.Bool(attr.Key, attr.Value.Bool()))
+		case slog.KindFloat64:
+			kvs = append(kvs, attribute.Float64(attr.Key, attr.Value.Float64()))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, attr.Value.String()))
+		}
+	}
+
+	return kvs
+}
@@ -0,0 +1,147 @@
+package prefixerotel_test
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixerotel"
+)
+
+type User struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+// recordingTracer records the spans it starts, spans aren't recording if disabled is true
+type recordingTracer struct {
+	embedded.Tracer
+
+	disabled bool
+	spans    []*recordingSpan
+}
+
+type recordingSpan struct {
+	noop.Span
+
+	name      string
+	kind      trace.SpanKind
+	recording bool
+	attrs     []attribute.KeyValue
+	ended     bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+
+	span := &recordingSpan{name: name, kind: config.SpanKind(), recording: !t.disabled}
+	t.spans = append(t.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordingSpan) IsRecording() bool {
+	return s.recording
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func TestSpanAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		attrs    []slog.Attr
+		want     []attribute.KeyValue
+	}{
+		{
+			name:  "kinds of values",
+			attrs: []slog.Attr{slog.String("prefixer.model", "User"), slog.Int("prefixer.columns", 2), slog.Bool("prefixer.cache_hit", true), slog.Float64("ratio", 0.5)},
+			want:  []attribute.KeyValue{attribute.String("prefixer.model", "User"), attribute.Int64("prefixer.columns", 2), attribute.Bool("prefixer.cache_hit", true), attribute.Float64("ratio", 0.5)},
+		},
+		{
+			name:  "other kinds as strings",
+			attrs: []slog.Attr{slog.Duration("elapsed", time.Second), slog.Any("tables", []string{"users"})},
+			want:  []attribute.KeyValue{attribute.String("elapsed", "1s"), attribute.String("tables", "[users]")},
+		},
+		{
+			name:     "not recording span",
+			disabled: true,
+			attrs:    []slog.Attr{slog.String("prefixer.model", "User")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer := &recordingTracer{disabled: test.disabled}
+
+			_, span := prefixerotel.NewTracer(tracer).Start(context.Background(), mfp.SpanColumns)
+			span.End(test.attrs...)
+
+			got := tracer.spans[0]
+			if !got.ended || got.name != mfp.SpanColumns || got.kind != trace.SpanKindInternal {
+				t.Errorf("span = %q of kind %v ended %v, want ended internal span %q", got.name, got.kind, got.ended, mfp.SpanColumns)
+			}
+
+			if !reflect.DeepEqual(got.attrs, test.want) && (len(got.attrs) > 0 || len(test.want) > 0) {
+				t.Errorf("attributes = %v, want %v", got.attrs, test.want)
+			}
+		})
+	}
+}
+
+func TestTracedPrefixer(t *testing.T) {
+	tracer := &recordingTracer{}
+	mp := mfp.New(mfp.WithTracer(prefixerotel.NewTracer(tracer)))
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+
+	mp.Columns(User{}, "u", mfp.WithRequestContext(ctx)).WithContext(ctx).InQuery("SELECT {columns} FROM users u")
+
+	want := []struct {
+		name  string
+		attrs []attribute.KeyValue
+	}{
+		{name: mfp.SpanColumns, attrs: []attribute.KeyValue{
+			attribute.String("prefixer.model", "github.com/ivnku/model-fields-prefixer/prefixerotel_test.User"),
+			attribute.Int64("prefixer.columns", 2),
+			attribute.Bool("prefixer.cache_hit", false),
+		}},
+		{name: mfp.SpanCollect, attrs: []attribute.KeyValue{
+			attribute.String("prefixer.model", "github.com/ivnku/model-fields-prefixer/prefixerotel_test.User"),
+		}},
+		{name: mfp.SpanRender, attrs: []attribute.KeyValue{
+			attribute.String("prefixer.model", "User"),
+			attribute.Int64("prefixer.columns", 2),
+		}},
+	}
+
+	// the first span is the one of the request
+	spans := tracer.spans[1:]
+	if len(spans) != len(want) {
+		t.Fatalf("%d spans are started, want %d", len(spans), len(want))
+	}
+
+	for i, span := range spans {
+		if span.name != want[i].name || !span.ended || !reflect.DeepEqual(span.attrs, want[i].attrs) {
+			t.Errorf("span %d = %q ended %v with %v, want ended %q with %v", i, span.name, span.ended, span.attrs, want[i].name, want[i].attrs)
+		}
+	}
+
+	if parent.(*recordingSpan).ended {
+		t.Errorf("span of the request is ended by the prefixer")
+	}
+}
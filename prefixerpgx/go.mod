@@ -0,0 +1,16 @@
+module github.com/ivnku/model-fields-prefixer/prefixerpgx
+
+go 1.25.0
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+	github.com/jackc/pgx/v5 v5.11.0
+)
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
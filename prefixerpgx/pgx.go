@@ -0,0 +1,55 @@
+// Package prefixerpgx maps rows of queries built with the prefixer onto nested structs for pgx v5 users.
+// Columns aliased by the prefixer (e.g. "um.city") are set to the nested models with the corresponding db tags,
+// nested models referenced by pointers stay nil if all their columns are NULL
+package prefixerpgx
+
+import (
+	"github.com/jackc/pgx/v5"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// defaultPrefixer keeps models metadata for RowToPrefixedStruct and RowToAddrOfPrefixedStruct
+var defaultPrefixer = mfp.New()
+
+// RowToPrefixedStruct scans a row into a new T, to be used with pgx.CollectRows, pgx.CollectOneRow and so on:
+//
+//	users, err := pgx.CollectRows(rows, prefixerpgx.RowToPrefixedStruct[User])
+func RowToPrefixedStruct[T any](row pgx.CollectableRow) (T, error) {
+	var value T
+
+	err := scanRow(defaultPrefixer, row, &value)
+
+	return value, err
+}
+
+// RowToAddrOfPrefixedStruct is like RowToPrefixedStruct but returns a pointer to the new T
+func RowToAddrOfPrefixedStruct[T any](row pgx.CollectableRow) (*T, error) {
+	value := new(T)
+
+	err := scanRow(defaultPrefixer, row, value)
+
+	return value, err
+}
+
+// RowToPrefixedStructWith returns pgx.RowToFunc using models metadata and codecs of mp
+func RowToPrefixedStructWith[T any](mp *mfp.ModelFieldsPrefixer) pgx.RowToFunc[T] {
+	return func(row pgx.CollectableRow) (T, error) {
+		var value T
+
+		err := scanRow(mp, row, &value)
+
+		return value, err
+	}
+}
+
+func scanRow(mp *mfp.ModelFieldsPrefixer, row pgx.CollectableRow, dest any) error {
+	fields := row.FieldDescriptions()
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Name
+	}
+
+	return mp.ScanRow(columns, row.Scan, dest)
+}
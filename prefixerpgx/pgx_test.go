@@ -0,0 +1,157 @@
+package prefixerpgx_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixerpgx"
+)
+
+type User struct {
+	ID   int64     `db:"id"`
+	Name string    `db:"name"`
+	Meta *UserMeta `db:"meta" dbalias:"um"`
+}
+
+type UserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+// stubRow is the row of the result set with the columns and values, nil values are NULLs
+type stubRow struct {
+	pgx.CollectableRow
+
+	columns []string
+	values  []any
+	err     error
+}
+
+func (r stubRow) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.columns))
+	for i, column := range r.columns {
+		fields[i] = pgconn.FieldDescription{Name: column}
+	}
+
+	return fields
+}
+
+func (r stubRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	for i, value := range r.values {
+		if value == nil {
+			continue
+		}
+
+		target := reflect.ValueOf(dest[i]).Elem()
+		if target.Kind() == reflect.Ptr {
+			target.Set(reflect.New(target.Type().Elem()))
+			target = target.Elem()
+		}
+
+		target.Set(reflect.ValueOf(value))
+	}
+
+	return nil
+}
+
+func TestRowToPrefixedStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     stubRow
+		want    User
+		wantErr string
+	}{
+		{
+			name: "nested model",
+			row:  stubRow{columns: []string{"id", "name", "meta.id", "meta.city"}, values: []any{int64(1), "Ann", int64(2), "Paris"}},
+			want: User{ID: 1, Name: "Ann", Meta: &UserMeta{ID: 2, City: "Paris"}},
+		},
+		{
+			name: "NULL nested model",
+			row:  stubRow{columns: []string{"id", "name", "meta.id", "meta.city"}, values: []any{int64(1), "Ann", nil, nil}},
+			want: User{ID: 1, Name: "Ann"},
+		},
+		{
+			name: "unknown columns",
+			row:  stubRow{columns: []string{"id", "total"}, values: []any{int64(1), int64(10)}},
+			want: User{ID: 1},
+		},
+		{
+			name:    "scan failure",
+			row:     stubRow{columns: []string{"id"}, err: errors.New("can't scan into dest[0]")},
+			wantErr: "can't scan into dest[0]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := prefixerpgx.RowToPrefixedStruct[User](test.row)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("RowToPrefixedStruct() error = %v, want %q", err, test.wantErr)
+			}
+
+			if !reflect.DeepEqual(user, test.want) {
+				t.Errorf("RowToPrefixedStruct() = %+v, want %+v", user, test.want)
+			}
+
+			addr, err := prefixerpgx.RowToAddrOfPrefixedStruct[User](test.row)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("RowToAddrOfPrefixedStruct() error = %v, want %q", err, test.wantErr)
+			}
+
+			if !reflect.DeepEqual(*addr, test.want) {
+				t.Errorf("RowToAddrOfPrefixedStruct() = %+v, want %+v", *addr, test.want)
+			}
+		})
+	}
+}
+
+func TestRowToPrefixedStructWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		rowTo   func(row pgx.CollectableRow) (any, error)
+		want    any
+		wantErr string
+	}{
+		{
+			name: "model",
+			rowTo: func(row pgx.CollectableRow) (any, error) {
+				return prefixerpgx.RowToPrefixedStructWith[UserMeta](mfp.New())(row)
+			},
+			want: UserMeta{ID: 1, City: "Paris"},
+		},
+		{
+			name: "not struct",
+			rowTo: func(row pgx.CollectableRow) (any, error) {
+				return prefixerpgx.RowToPrefixedStructWith[int64](mfp.New())(row)
+			},
+			want:    int64(0),
+			wantErr: "dest must be a non-nil pointer to a struct",
+		},
+	}
+
+	row := stubRow{columns: []string{"id", "city"}, values: []any{int64(1), "Paris"}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.rowTo(row)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("RowToPrefixedStructWith() error = %v, want %q", err, test.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("RowToPrefixedStructWith() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/ivnku/model-fields-prefixer/prefixersqlparser
+
+go 1.21
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+	github.com/xwb1989/sqlparser v0.0.0-20180606152119-120387863bf2
+)
@@ -0,0 +1,43 @@
+// Package prefixersqlparser validates queries rendered by the prefixer with the SQL parser of vitess, see
+// mfp.WithQueryValidator. The parser implements MySQL grammar, so dialect specific syntax of other databases
+// (e.g. PostgreSQL casts or LATERAL joins) is reported as an error
+package prefixersqlparser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// numberedPlaceholderRegexp matches numbered placeholders of PostgreSQL ('$1') and MSSQL ('@p1')
+var numberedPlaceholderRegexp = regexp.MustCompile(`\$\d+|@p\d+`)
+
+// Validate parses the query and returns *mfp.QueryError pointing to the syntax error. Numbered placeholders
+// are replaced with '?' before parsing:
+//
+//	m := mfp.New(mfp.WithDialect(mfp.DialectMySQL), mfp.WithQueryValidator(prefixersqlparser.Validate))
+func Validate(query string) error {
+	// placeholders are padded with spaces to keep offsets of the query
+	parsed := numberedPlaceholderRegexp.ReplaceAllStringFunc(query, func(placeholder string) string {
+		return "?" + strings.Repeat(" ", len(placeholder)-1)
+	})
+
+	_, err := sqlparser.Parse(parsed)
+	if err == nil {
+		return nil
+	}
+
+	offset := -1
+
+	// syntax errors are 'syntax error at position 18 near 'from'', the position is the end of the offending token
+	var position int
+	if _, scanErr := fmt.Sscanf(err.Error(), "syntax error at position %d", &position); scanErr == nil {
+		offset = min(max(position-1, 0), len(query))
+	}
+
+	return mfp.NewQueryError(query, offset, err)
+}
@@ -0,0 +1,112 @@
+package prefixersqlparser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixersqlparser"
+)
+
+type User struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		offset  int
+		wantErr string
+	}{
+		{
+			name:  "valid query",
+			query: "SELECT u.id, u.name AS `user.name` FROM users u WHERE u.id IN (?, ?)",
+		},
+		{
+			name:  "numbered placeholders",
+			query: "SELECT u.id FROM users u WHERE u.id = $1 AND u.name = @p2",
+		},
+		{
+			name:    "syntax error",
+			query:   "SELECT u.id, FROM users u",
+			offset:  17,
+			wantErr: "syntax error at position",
+		},
+		{
+			name:    "syntax of another dialect",
+			query:   "SELECT u.id::text FROM users u",
+			offset:  17,
+			wantErr: "syntax error at position",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := prefixersqlparser.Validate(test.query)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("Validate() error = %v, want %q", err, test.wantErr)
+			}
+
+			if test.wantErr == "" {
+				return
+			}
+
+			var queryErr *mfp.QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("Validate() error = %T, want *mfp.QueryError", err)
+			}
+
+			if queryErr.Query != test.query || queryErr.Offset != test.offset {
+				t.Errorf("Validate() error = %q at %d, want %q at %d", queryErr.Query, queryErr.Offset, test.query, test.offset)
+			}
+		})
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		query   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "mysql",
+			dialect: mfp.DialectMySQL,
+			query:   "SELECT {columns} FROM users u WHERE u.id = ?",
+			want:    "SELECT u.id, u.name FROM users u WHERE u.id = ?",
+		},
+		{
+			name:    "postgres placeholders",
+			dialect: mfp.DialectPostgres,
+			query:   "SELECT {columns} FROM users u WHERE u.id = $1",
+			want:    "SELECT u.id, u.name FROM users u WHERE u.id = $1",
+		},
+		{
+			name:    "invalid query",
+			dialect: mfp.DialectMySQL,
+			query:   "SELECT {columns} FORM users u",
+			want:    "SELECT u.id, u.name FORM users u",
+			wantErr: "invalid query near 'd, u.name FORM users u': syntax error at position 31 near 'users'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithDialect(test.dialect), mfp.WithQueryValidator(prefixersqlparser.Validate)).Columns(User{}, "u")
+
+			query, err := m.ValidateQuery(test.query)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("ValidateQuery() error = %v, want %q", err, test.wantErr)
+			}
+
+			if query != test.want {
+				t.Errorf("ValidateQuery() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
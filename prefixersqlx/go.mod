@@ -0,0 +1,10 @@
+module github.com/ivnku/model-fields-prefixer/prefixersqlx
+
+go 1.21
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+	github.com/jmoiron/sqlx v1.4.0
+)
@@ -0,0 +1,88 @@
+// Package prefixersqlx provides helpers to run queries with {columns} placeholder through sqlx.
+// sqlx maps dotted column aliases (e.g. "um.city") onto nested structs by db tags,
+// so the columns built by the prefixer are scanned into nested models out of the box.
+//
+// Helpers accept any value with the corresponding sqlx methods, e.g. *sqlx.DB, *sqlx.Tx or *sqlx.Conn.
+// Values bound by the prefixer (see mfp.Bind) are passed before the query's own arguments
+package prefixersqlx
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/jmoiron/sqlx"
+)
+
+// Getter is implemented by *sqlx.DB, *sqlx.Tx and *sqlx.Conn
+type Getter interface {
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// Selector is implemented by *sqlx.DB, *sqlx.Tx and *sqlx.Conn
+type Selector interface {
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// Execer is implemented by *sqlx.DB, *sqlx.Tx and *sqlx.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+var (
+	_ Getter   = (*sqlx.DB)(nil)
+	_ Selector = (*sqlx.Tx)(nil)
+	_ Execer   = (*sqlx.Conn)(nil)
+)
+
+// Get expands {columns} placeholder of the query with the columns built by mp and scans a single row into dest
+func Get(ctx context.Context, db Getter, mp *mfp.ModelFieldsPrefixer, dest any, query string, args ...any) error {
+	return db.GetContext(ctx, dest, mp.InQuery(query), mp.Args(args...)...)
+}
+
+// Select expands {columns} placeholder of the query with the columns built by mp and scans all the rows into dest slice
+func Select(ctx context.Context, db Selector, mp *mfp.ModelFieldsPrefixer, dest any, query string, args ...any) error {
+	return db.SelectContext(ctx, dest, mp.InQuery(query), mp.Args(args...)...)
+}
+
+// NamedExec expands {columns} placeholder of the query with the columns built by mp (e.g. in RETURNING clause)
+// and executes it binding named parameters from arg. Named parameters are compiled into placeholders of mp's dialect
+// numbered after the values bound by mp
+func NamedExec(ctx context.Context, db Execer, mp *mfp.ModelFieldsPrefixer, query string, arg any) (sql.Result, error) {
+	named, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ExecContext(ctx, mp.InQuery(bindNamed(named, mp)), mp.Args(args...)...)
+}
+
+// bindNamed replaces '?' bindvars of the query compiled by sqlx.Named with placeholders of mp's dialect numbered
+// after the values bound by mp
+func bindNamed(query string, mp *mfp.ModelFieldsPrefixer) string {
+	offset := len(mp.Args())
+
+	sb := strings.Builder{}
+	sb.Grow(len(query))
+
+	n := 0
+
+	for {
+		i := strings.IndexByte(query, '?')
+		if i < 0 {
+			break
+		}
+
+		n++
+
+		sb.WriteString(query[:i])
+		sb.WriteString(mp.Dialect().Placeholder(offset + n))
+
+		query = query[i+1:]
+	}
+
+	sb.WriteString(query)
+
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package prefixersqlx_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixersqlx"
+)
+
+type User struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+// recorder records the query and the arguments it is called with
+type recorder struct {
+	query string
+	args  []any
+}
+
+func (r *recorder) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	r.query, r.args = query, args
+
+	return nil
+}
+
+func (r *recorder) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	r.query, r.args = query, args
+
+	return nil
+}
+
+func (r *recorder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r.query, r.args = query, args
+
+	return nil, nil
+}
+
+func TestHelpers(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   mfp.Dialect
+		run       func(db *recorder, mp *mfp.ModelFieldsPrefixer) error
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:    "get",
+			dialect: mfp.DialectPostgres,
+			run: func(db *recorder, mp *mfp.ModelFieldsPrefixer) error {
+				return prefixersqlx.Get(context.Background(), db, mp, &User{}, "SELECT {columns} FROM users u WHERE u.id = $2", 7)
+			},
+			wantQuery: "SELECT u.id, u.name, COALESCE(u.name, $1) AS label FROM users u WHERE u.id = $2",
+			wantArgs:  []any{"anonymous", 7},
+		},
+		{
+			name:    "select",
+			dialect: mfp.DialectMySQL,
+			run: func(db *recorder, mp *mfp.ModelFieldsPrefixer) error {
+				return prefixersqlx.Select(context.Background(), db, mp, &[]User{}, "SELECT {columns} FROM users u WHERE u.name = ?", "bob")
+			},
+			wantQuery: "SELECT u.id, u.name, COALESCE(u.name, ?) AS label FROM users u WHERE u.name = ?",
+			wantArgs:  []any{"anonymous", "bob"},
+		},
+		{
+			name:    "named exec",
+			dialect: mfp.DialectPostgres,
+			run: func(db *recorder, mp *mfp.ModelFieldsPrefixer) error {
+				_, err := prefixersqlx.NamedExec(context.Background(), db, mp, "UPDATE users u SET name = :name WHERE u.id = :id RETURNING {columns}", User{ID: 7, Name: "bob"})
+
+				return err
+			},
+			wantQuery: "UPDATE users u SET name = $2 WHERE u.id = $3 RETURNING u.id, u.name, COALESCE(u.name, $1) AS label",
+			wantArgs:  []any{"anonymous", "bob", int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := mfp.New(mfp.WithDialect(tt.dialect)).Columns(User{}, "u").CustomColumnsf("COALESCE(u.name, %v) AS label", mfp.Bind("anonymous"))
+
+			db := &recorder{}
+			if err := tt.run(db, mp); err != nil {
+				t.Fatal(err)
+			}
+
+			if db.query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", db.query, tt.wantQuery)
+			}
+
+			if !reflect.DeepEqual(db.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", db.args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/ivnku/model-fields-prefixer/prefixersquirrel
+
+go 1.21
+
+replace github.com/ivnku/model-fields-prefixer => ../
+
+require (
+	github.com/Masterminds/squirrel v1.5.4
+	github.com/ivnku/model-fields-prefixer v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+)
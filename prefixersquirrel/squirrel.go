@@ -0,0 +1,31 @@
+// Package prefixersquirrel starts squirrel select builders from the columns built by the prefixer
+package prefixersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// SelectBuilder returns squirrel.SelectBuilder selecting the columns built by mp, lateral joins of
+// the join models joined from function calls (M.F) are added as well
+func SelectBuilder(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+	builder := sq.Select(mp.Slice()...)
+
+	if joins := mp.Joins(); joins != "" {
+		builder = builder.JoinClause(joins)
+	}
+
+	return builder
+}
+
+// StatementBuilder is like SelectBuilder but uses the given statement builder, e.g. with dollar placeholders
+func StatementBuilder(b sq.StatementBuilderType, mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+	builder := b.Select(mp.Slice()...)
+
+	if joins := mp.Joins(); joins != "" {
+		builder = builder.JoinClause(joins)
+	}
+
+	return builder
+}
@@ -0,0 +1,93 @@
+package prefixersquirrel_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixersquirrel"
+)
+
+type User struct {
+	ID   int64     `db:"id"`
+	Name string    `db:"name"`
+	Meta *UserMeta `db:"meta" dbalias:"um"`
+}
+
+type UserMeta struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+}
+
+func TestSelectBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder
+		want    string
+		args    []any
+		wantErr string
+	}{
+		{
+			name: "columns",
+			build: func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+				return prefixersquirrel.SelectBuilder(mp.Columns(User{}, "u")).
+					From("users u").Join("user_meta um ON um.id = u.id").Where(sq.Eq{"u.id": 1})
+			},
+			want: "SELECT u.id, u.name, um.id AS `meta.id`, um.city AS `meta.city` FROM users u JOIN user_meta um ON um.id = u.id WHERE u.id = ?",
+			args: []any{1},
+		},
+		{
+			name: "lateral join",
+			build: func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+				return prefixersquirrel.SelectBuilder(mp.Columns(User{}, "u", mfp.M{N: "Meta", A: "m", F: "user_meta(u.id)"})).From("users u")
+			},
+			want: "SELECT u.id, u.name, m.id AS `meta.id`, m.city AS `meta.city` FROM users u LEFT JOIN LATERAL user_meta(u.id) m ON true",
+		},
+		{
+			name: "statement builder",
+			build: func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+				return prefixersquirrel.StatementBuilder(sq.StatementBuilder.PlaceholderFormat(sq.Dollar), mp.Columns(UserMeta{}, "um")).
+					From("user_meta um").Where(sq.Eq{"um.city": "Paris"})
+			},
+			want: "SELECT um.id, um.city FROM user_meta um WHERE um.city = $1",
+			args: []any{"Paris"},
+		},
+		{
+			name: "bind parameters of the columns",
+			build: func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+				mp.Columns(UserMeta{}, "um").CustomColumnsf("um.city = %s AS local", mfp.Bind("Paris"))
+
+				return sq.Select().Column(mp.Sqlizer()).From("user_meta um").Where(sq.Eq{"um.id": 1})
+			},
+			want: "SELECT um.id, um.city, um.city = ? AS local FROM user_meta um WHERE um.id = ?",
+			args: []any{"Paris", 1},
+		},
+		{
+			name: "no columns",
+			build: func(mp *mfp.ModelFieldsPrefixer) sq.SelectBuilder {
+				return prefixersquirrel.SelectBuilder(mp.Columns(1, "x")).From("users u")
+			},
+			wantErr: "select statements must have at least one result column",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := test.build(mfp.New(mfp.WithDialect(mfp.DialectMySQL))).ToSql()
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("ToSql() error = %v, want %q", err, test.wantErr)
+			}
+
+			if query != test.want {
+				t.Errorf("ToSql() = %q, want %q", query, test.want)
+			}
+
+			if !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("ToSql() args = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// prettyIndent indents the columns of StringPretty
+const prettyIndent = "    "
+
+// SetPrettyQuery makes InQuery write the columns one per line with indentation, so long joined selects are readable
+// in logs while debugging, e.g. 'SELECT\n    u.id,\n    u.name\nFROM users u'
+func (mp *ModelFieldsPrefixer) SetPrettyQuery(pretty bool) *ModelFieldsPrefixer {
+	mp.prettyQuery = pretty
+
+	return mp
+}
+
+// StringPretty returns the columns list with every column on its own indented line, e.g. '    u.id,\n    u.name'
+func (mp *ModelFieldsPrefixer) StringPretty() string {
+	columns := mp.Slice()
+	if len(columns) == 0 {
+		return ""
+	}
+
+	return prettyIndent + strings.Join(columns, ",\n"+prettyIndent)
+}
+
+// queryColumns returns the value of {columns} placeholder of InQuery: the columns list preceded by DISTINCT clause,
+// written on separate lines if SetPrettyQuery is enabled
+func (mp *ModelFieldsPrefixer) queryColumns() string {
+	if !mp.prettyQuery || len(mp.columns) == 0 {
+		return mp.distinct + mp.String()
+	}
+
+	return strings.TrimSuffix(mp.distinct, " ") + "\n" + mp.StringPretty() + "\n"
+}
@@ -0,0 +1,192 @@
+package model_fields_prefixer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// placeholderRegexp matches placeholders of query templates, e.g. '{columns}' or namespaced '{table:UserMeta}', and
+// identifiers in braces which may be mistyped ones, e.g. '{Columns}', '{columns2}' or '{cet:recent}'. The namespace
+// and the name of namespaced placeholders are the submatches
+var placeholderRegexp = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?::([A-Za-z0-9_.]+))?\}`)
+
+var queryPlaceholders = map[string]struct{}{
+	prefixedColumnsPlaceholder: {},
+	joinsPlaceholder:           {},
+	orderByPlaceholder:         {},
+	groupByPlaceholder:         {},
+	lockingPlaceholder:         {},
+	paginationPlaceholder:      {},
+	keysetPlaceholder:          {},
+	wherePlaceholder:           {},
+	tablePlaceholder:           {},
+}
+
+// namespacedPlaceholders are the namespaces of placeholders naming join models ('{table:Name}', '{hint:Name}') and
+// CTEs ('{cte:name}')
+var namespacedPlaceholders = map[string]struct{}{
+	"table": {},
+	"hint":  {},
+	"cte":   {},
+}
+
+// isQueryPlaceholder returns true if the submatches of placeholderRegexp are the placeholder InQuery replaces,
+// names of namespaced ones aren't checked
+func isQueryPlaceholder(match []string) bool {
+	if match[2] != "" {
+		_, ok := namespacedPlaceholders[match[1]]
+
+		return ok
+	}
+
+	_, ok := queryPlaceholders[match[0]]
+
+	return ok
+}
+
+// queryRegistry keeps query templates registered with RegisterQuery, it is shared by the prefixers allocated
+// from the same instance
+type queryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]registeredQuery
+}
+
+// registeredQuery is the query template and the name of the model it is bound to, empty if it isn't bound
+type registeredQuery struct {
+	sqlTemplate string
+	model       string
+}
+
+func newQueryRegistry() *queryRegistry {
+	return &queryRegistry{queries: make(map[string]registeredQuery)}
+}
+
+// Bindings are the parameters a registered query is rendered with, see Render
+type Bindings struct {
+	// Model and Alias are the root model and its table alias passed to Columns
+	Model any
+	Alias string
+	Joins []M
+	// Filter is passed to Where and OrderBy to OrderBy if they are set
+	Filter  any
+	OrderBy string
+	// Context is passed to WithContext and to the table resolver if it is set
+	Context context.Context
+}
+
+// RegisterQuery puts the query template to the registry, so queries live in one place and are rendered by name with
+// Render. Call it at startup: it returns an error if the name is already registered or the template has placeholders
+// which can't be resolved, e.g. '{colums}'
+func (mp *ModelFieldsPrefixer) RegisterQuery(name, sqlTemplate string) error {
+	return mp.registerQuery(name, registeredQuery{sqlTemplate: sqlTemplate})
+}
+
+func (mp *ModelFieldsPrefixer) registerQuery(name string, query registeredQuery) error {
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(query.sqlTemplate, -1) {
+		if !isQueryPlaceholder(match) {
+			return fmt.Errorf("failed to register query %s: unknown placeholder %s", name, match[0])
+		}
+	}
+
+	mp.queries.mu.Lock()
+	defer mp.queries.mu.Unlock()
+
+	if _, ok := mp.queries.queries[name]; ok {
+		return fmt.Errorf("failed to register query %s: query is already registered", name)
+	}
+
+	mp.queries.queries[name] = query
+
+	return nil
+}
+
+// MustRegisterQuery works as RegisterQuery but panics on error
+func (mp *ModelFieldsPrefixer) MustRegisterQuery(name, sqlTemplate string) *ModelFieldsPrefixer {
+	if err := mp.RegisterQuery(name, sqlTemplate); err != nil {
+		panic(err)
+	}
+
+	return mp
+}
+
+// Render builds the columns of the bindings' model and renders the registered query with them, returning the query
+// and its bind parameters. The model must be registered with Register (or SetModelInfo), so models missed at startup
+// are reported instead of being scanned on the first request. Render doesn't change the state of mp:
+//
+//	mp.MustRegister(User{}).MustRegisterQuery("users.active", "SELECT {columns} FROM users u {where} {orderby}")
+//
+//	query, args, err := mp.Render("users.active", mfp.Bindings{Model: User{}, Alias: "u", Filter: filter})
+func (mp *ModelFieldsPrefixer) Render(name string, bindings Bindings) (string, []any, error) {
+	mp.queries.mu.RLock()
+	query, ok := mp.queries.queries[name]
+	mp.queries.mu.RUnlock()
+
+	if !ok {
+		return "", nil, fmt.Errorf("query %s is not registered", name)
+	}
+
+	t, ok := modelType(bindings.Model)
+	if !ok {
+		return "", nil, fmt.Errorf("failed to render query %s: model %T is not a struct", name, bindings.Model)
+	}
+
+	if mp.cache.getModelCacheValue(t) == nil {
+		return "", nil, fmt.Errorf("failed to render query %s: model %s is not registered", name, t.String())
+	}
+
+	if query.model != "" && query.model != t.Name() && query.model != fullTypeName(t) {
+		return "", nil, fmt.Errorf("failed to render query %s: query is bound to model %s, got %s", name, query.model, t.String())
+	}
+
+	p := mp.Acquire()
+	defer p.Release()
+
+	args := []any{bindings.Model, bindings.Alias}
+	for _, joinModel := range bindings.Joins {
+		args = append(args, joinModel)
+	}
+
+	if bindings.Context != nil {
+		args = append(args, WithRequestContext(bindings.Context))
+	}
+
+	p.Columns(args...)
+
+	if bindings.Filter != nil {
+		p.Where(bindings.Filter)
+	}
+
+	if bindings.OrderBy != "" {
+		p.OrderBy(bindings.OrderBy)
+	}
+
+	if bindings.Context != nil {
+		p.WithContext(bindings.Context)
+	}
+
+	rendered := p.InQuery(query.sqlTemplate)
+
+	if err := p.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to render query %s: %w", name, err)
+	}
+
+	return rendered, p.Args(), nil
+}
+
+// Queries returns sorted names of the registered queries
+func (mp *ModelFieldsPrefixer) Queries() []string {
+	mp.queries.mu.RLock()
+	defer mp.queries.mu.RUnlock()
+
+	names := make([]string, 0, len(mp.queries.queries))
+	for name := range mp.queries.queries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
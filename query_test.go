@@ -0,0 +1,138 @@
+package model_fields_prefixer_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestRegisterQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{
+			name:  "placeholders",
+			query: "SELECT {columns} FROM {table} u {joins} {where} {groupby} {orderby} {pagination} {locking}",
+		},
+		{
+			name:  "namespaced placeholders",
+			query: "SELECT {columns}, {cte:recent} FROM users u JOIN {table:UserMeta} um {hint:UserMeta} ON um.id = u.id",
+		},
+		{
+			name:    "mistyped placeholder",
+			query:   "SELECT {colums} FROM users u",
+			wantErr: "unknown placeholder {colums}",
+		},
+		{
+			name:    "unknown namespace",
+			query:   "SELECT {columns} FROM {tabel:UserMeta} um",
+			wantErr: "unknown placeholder {tabel:UserMeta}",
+		},
+		{
+			name:    "registered name",
+			query:   "SELECT {columns} FROM users u",
+			wantErr: "query is already registered",
+		},
+	}
+
+	m := mfp.New().MustRegisterQuery("registered name", "SELECT {columns} FROM users u")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := m.RegisterQuery(test.name, test.query)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("RegisterQuery() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		bindings mfp.Bindings
+		want     string
+		args     []any
+		wantErr  string
+	}{
+		{
+			name:     "columns",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u"},
+			want:     selectUsers + " ",
+		},
+		{
+			name:     "filter and order",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u", Filter: UserFilter{City: "Paris"}, OrderBy: "-name"},
+			want:     selectUsers + "WHERE um.city = $1 ORDER BY u.name DESC",
+			args:     []any{"Paris"},
+		},
+		{
+			name:     "join models",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u", Joins: []mfp.M{{N: "Meta", A: "m"}}},
+			want:     `SELECT u.id, u.name, m.id AS "meta.id", m.city AS "meta.city" FROM users u  `,
+		},
+		{
+			name:     "query of the model",
+			query:    "meta.list",
+			bindings: mfp.Bindings{Model: &UserMeta{}, Alias: "um"},
+			want:     "SELECT um.id, um.city FROM user_meta um",
+		},
+		{
+			name:     "unregistered query",
+			query:    "users.missing",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u"},
+			wantErr:  "query users.missing is not registered",
+		},
+		{
+			name:     "not struct",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: 1, Alias: "u"},
+			wantErr:  "model int is not a struct",
+		},
+		{
+			name:     "unregistered model",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: Visit{}, Alias: "v"},
+			wantErr:  "is not registered",
+		},
+		{
+			name:     "failure in strict mode",
+			query:    "users.list",
+			bindings: mfp.Bindings{Model: User{}, Alias: "u", OrderBy: "email"},
+			wantErr:  "failed to render query users.list: column doesn't match any field",
+		},
+	}
+
+	m := mfp.New(mfp.Strict()).MustRegister(User{}, UserMeta{}).
+		MustRegisterQuery("users.list", "SELECT {columns} FROM users u {where} {orderby}").
+		MustRegisterQuery("meta.list", "SELECT {columns} FROM user_meta um")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := m.Render(test.query, test.bindings)
+			if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("Render() error = %v, want %q", err, test.wantErr)
+			}
+
+			if query != test.want {
+				t.Errorf("Render() = %q, want %q", query, test.want)
+			}
+
+			if !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Render() args = %v, want %v", args, test.args)
+			}
+		})
+	}
+
+	if got, want := m.Queries(), []string{"meta.list", "users.list"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Queries() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// Register scans the models and puts them to the cache at once, so the first query doesn't pay the reflection cost.
+// Call it at startup: it returns an error if any of the models is not a struct or has no db tags,
+// so misconfigured models fail fast. Models infos don't depend on aliases, so models are registered without them
+func (mp *ModelFieldsPrefixer) Register(models ...any) error {
+	for _, model := range models {
+		t, ok := modelType(model)
+		if !ok {
+			return fmt.Errorf("failed to register model %T: model is not a struct", model)
+		}
+
+		// models of static builds are prebuilt, so they are only checked
+		if staticModels {
+			if mp.cache.getModelCacheValue(t) == nil {
+				return fmt.Errorf("failed to register model %s: %w", t.Name(), prefixererr.ErrNotPrebuilt)
+			}
+
+			continue
+		}
+
+		start := mp.metricsStart()
+		span := mp.startSpan(SpanCollect)
+
+		modelInfo, isAnyDBTag := mp.collectCache(t, nil, "", "")
+
+		mp.reportModelScanned(t, start)
+		mp.endCollectSpan(span, t)
+		if !isAnyDBTag {
+			return fmt.Errorf("failed to register model %s: model has no db tags", t.Name())
+		}
+
+		mp.cache.setModelCacheValue(t, modelInfo)
+	}
+
+	return nil
+}
+
+// MustRegister works as Register but panics on error
+func (mp *ModelFieldsPrefixer) MustRegister(models ...any) *ModelFieldsPrefixer {
+	if err := mp.Register(models...); err != nil {
+		panic(err)
+	}
+
+	return mp
+}
+
+// ExcludeScanning makes the structs columns instead of nested models, e.g. types stored as JSON which have no
+// Scan method. Structs without db tags are excluded automatically once they are scanned
+func (mp *ModelFieldsPrefixer) ExcludeScanning(types ...any) *ModelFieldsPrefixer {
+	for _, t := range types {
+		if t, ok := modelType(t); ok {
+			mp.cache.exclude(t.PkgPath() + "." + t.Name())
+		}
+	}
+
+	return mp
+}
+
+// CacheStats returns the number of cached models and fields, and the number of cache hits and misses
+func (mp *ModelFieldsPrefixer) CacheStats() CacheStats {
+	return mp.cache.stats()
+}
+
+// CachedModels returns sorted full names of the cached models, e.g. 'github.com/org/models.User'
+func (mp *ModelFieldsPrefixer) CachedModels() []string {
+	return mp.cache.modelNames()
+}
+
+// Invalidate removes the model from the cache, so it is scanned again on the next use.
+// Use it to refresh metadata of dynamically loaded types. modelName is either the full name of the model
+// ('github.com/org/models.User') or just its name ('User') which invalidates same-named models of all packages
+func (mp *ModelFieldsPrefixer) Invalidate(modelName string) {
+	mp.cache.deleteModelCacheValue(modelName)
+}
@@ -0,0 +1,324 @@
+package model_fields_prefixer
+
+import (
+	"container/list"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// renderedKey identifies columns rendered by Columns: the model, its alias and aliases of the join models
+type renderedKey struct {
+	model reflect.Type
+	alias string
+	// join is the only join model, it keeps the key of the common case comparable without allocations
+	join M
+	// joins are sorted 'name alias' pairs of several join models, marked if the model is selected with wildcard or coalesced
+	joins string
+	// maxDepth, aliasSeparator, rootAliasing, noAliases, coalesce, groups, unmasked and locale are set by ColumnsOption values, groups are sorted and joined
+	maxDepth       int
+	aliasSeparator string
+	rootAliasing   bool
+	noAliases      bool
+	coalesce       bool
+	groups         string
+	unmasked       bool
+	locale         string
+	// config is the configuration ID of the prefixer, so clones with other settings sharing the cache never get each other's columns
+	config uint64
+}
+
+// renderedColumns is the result of a Columns call which is reused by subsequent calls with the same arguments
+type renderedColumns struct {
+	modelInfo *ModelInfo
+	// columns is the content of the buffer including the trailing separator
+	columns    string
+	columnInfo []columnInfo
+	aliases    []string
+	// args are bind parameters of the columns, e.g. encryption keys
+	args []any
+	// unknownJoins are names of join models which don't match any relation of the model
+	unknownJoins []string
+	// duplicateAliases are the scan aliases which were made unique, they are reported again by every restore
+	duplicateAliases []duplicateAlias
+	// shortenedAliases are full scan aliases shortened to the identifier limit of the dialect, they are reported
+	// again by every restore
+	shortenedAliases []string
+	// autoAliases are the aliases generated by WithAutoAlias
+	autoAliases []autoAlias
+	// tableAliases are the aliases of the written tables, collisions are reported again by every restore
+	tableAliases []tableAlias
+}
+
+// String returns the columns list without the trailing separator
+func (r *renderedColumns) String() string {
+	if len(r.columns) == 0 {
+		return ""
+	}
+
+	return r.columns[:len(r.columns)-2]
+}
+
+func (mp *ModelFieldsPrefixer) newRenderedKey(t reflect.Type, dbTableAlias string, joinModels []M) renderedKey {
+	key := renderedKey{
+		model:          t,
+		alias:          dbTableAlias,
+		maxDepth:       mp.maxDepth,
+		aliasSeparator: mp.aliasSeparator,
+		rootAliasing:   mp.rootAliasing,
+		noAliases:      mp.noAliases,
+		coalesce:       mp.coalesce,
+		groups:         mp.groupsKey(),
+		unmasked:       mp.unmasked,
+		locale:         mp.locale,
+		config:         mp.configID,
+	}
+
+	if len(joinModels) == 1 {
+		if joinModels[0].N != "" {
+			key.join = M{N: joinModels[0].N, A: joinModels[0].A, W: joinModels[0].W, C: joinModels[0].C}
+		}
+
+		return key
+	}
+
+	joinModelsMap := mp.getJoinModelsMap(joinModels)
+	if len(joinModelsMap) == 0 {
+		return key
+	}
+
+	joins := make([]string, 0, len(joinModelsMap))
+	for name, joinModel := range joinModelsMap {
+		join := name + "\x00" + joinModel.A
+		if joinModel.W {
+			join += "\x00*"
+		}
+
+		if joinModel.C {
+			join += "\x00?"
+		}
+
+		joins = append(joins, join)
+	}
+
+	sort.Strings(joins)
+
+	key.joins = strings.Join(joins, "\x01")
+
+	return key
+}
+
+// defaultRenderedCacheSize is the default max number of rendered columns kept in the cache
+const defaultRenderedCacheSize = 1024
+
+// RenderedCacheStats describes the state of the rendered columns cache
+type RenderedCacheStats struct {
+	// Entries is the number of cached rendered columns and MaxEntries is the limit of them, 0 if the cache is disabled
+	Entries    int
+	MaxEntries int
+	// Hits and Misses are numbers of Columns calls which were and weren't served from the cache
+	Hits   int64
+	Misses int64
+	// Evictions is the number of least recently used entries removed to keep the cache within MaxEntries
+	Evictions int64
+}
+
+// renderedCache is LRU cache of rendered columns
+type renderedCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[renderedKey]*list.Element
+	// order keeps entries from the most recently used to the least recently used one
+	order *list.List
+
+	hits, misses, evictions int64
+}
+
+type renderedEntry struct {
+	key      renderedKey
+	rendered *renderedColumns
+}
+
+func newRenderedCache(maxEntries int) *renderedCache {
+	return &renderedCache{
+		maxEntries: maxEntries,
+		entries:    make(map[renderedKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *renderedCache) get(key renderedKey) *renderedColumns {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+
+		return nil
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+
+	return element.Value.(*renderedEntry).rendered
+}
+
+func (c *renderedCache) set(key renderedKey, rendered *renderedColumns) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*renderedEntry).rendered = rendered
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&renderedEntry{key: key, rendered: rendered})
+
+	c.evict()
+}
+
+// setMaxEntries changes the limit of the cache evicting the least recently used entries if there are more of them
+func (c *renderedCache) setMaxEntries(maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+
+	c.evict()
+}
+
+func (c *renderedCache) evict() {
+	for c.order.Len() > 0 && c.order.Len() > c.maxEntries {
+		element := c.order.Back()
+
+		c.order.Remove(element)
+		delete(c.entries, element.Value.(*renderedEntry).key)
+
+		c.evictions++
+	}
+}
+
+// deleteModel removes rendered columns of the model, so they are rendered again from the model's new info
+func (c *renderedCache) deleteModel(t reflect.Type) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.entries {
+		if key.model == t {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *renderedCache) stats() RenderedCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	maxEntries := c.maxEntries
+	if maxEntries < 0 {
+		maxEntries = 0
+	}
+
+	return RenderedCacheStats{
+		Entries:    c.order.Len(),
+		MaxEntries: maxEntries,
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+	}
+}
+
+// SetRenderedCacheSize sets the max number of rendered columns kept in the cache shared with allocated prefixers,
+// the least recently used ones are evicted when the limit is reached. Use 0 to disable the cache,
+// e.g. if aliases are generated dynamically and calls are rarely repeated
+func (mp *ModelFieldsPrefixer) SetRenderedCacheSize(maxEntries int) *ModelFieldsPrefixer {
+	mp.cache.rendered.setMaxEntries(maxEntries)
+
+	return mp
+}
+
+// RenderedCacheStats returns the number of cached rendered columns, hits, misses and evictions of the cache
+func (mp *ModelFieldsPrefixer) RenderedCacheStats() RenderedCacheStats {
+	return mp.cache.rendered.stats()
+}
+
+// renderedSnapshot copies the columns written by the last Columns call
+func (mp *ModelFieldsPrefixer) renderedSnapshot() *renderedColumns {
+	rendered := &renderedColumns{
+		modelInfo:  mp.rootModel,
+		columns:    mp.bytesBuffer.String(),
+		columnInfo: make([]columnInfo, len(mp.columns)),
+		aliases:    make([]string, len(mp.aliases)),
+		// the slice is never modified, so it is shared with the prefixer
+		unknownJoins: mp.unknownJoins,
+	}
+
+	copy(rendered.columnInfo, mp.columns)
+	copy(rendered.aliases, mp.aliases)
+
+	mp.cache.bufferSize.observe(len(rendered.columns))
+
+	if len(mp.args) > 0 {
+		rendered.args = make([]any, len(mp.args))
+		copy(rendered.args, mp.args)
+	}
+
+	if len(mp.tableAliases) > 0 {
+		rendered.tableAliases = make([]tableAlias, len(mp.tableAliases))
+		copy(rendered.tableAliases, mp.tableAliases)
+	}
+
+	if len(mp.autoAliases) > 0 {
+		rendered.autoAliases = make([]autoAlias, len(mp.autoAliases))
+		copy(rendered.autoAliases, mp.autoAliases)
+	}
+
+	if len(mp.shortenedAliases) > 0 {
+		rendered.shortenedAliases = make([]string, len(mp.shortenedAliases))
+		copy(rendered.shortenedAliases, mp.shortenedAliases)
+	}
+
+	if len(mp.duplicateAliases) > 0 {
+		rendered.duplicateAliases = make([]duplicateAlias, len(mp.duplicateAliases))
+		copy(rendered.duplicateAliases, mp.duplicateAliases)
+	}
+
+	return rendered
+}
+
+// restoreRendered writes previously rendered columns as if they were built by buildColumns
+func (mp *ModelFieldsPrefixer) restoreRendered(rendered *renderedColumns, dbTableAlias string) {
+	mp.rootModel = rendered.modelInfo
+	mp.rootAlias = dbTableAlias
+	mp.rendered = rendered
+
+	mp.bytesBuffer.WriteString(rendered.columns)
+	mp.columns = append(mp.columns, rendered.columnInfo...)
+	mp.aliases = append(mp.aliases, rendered.aliases...)
+	mp.args = append(mp.args, rendered.args...)
+	mp.unknownJoins = rendered.unknownJoins
+	mp.tableAliases = append(mp.tableAliases, rendered.tableAliases...)
+
+	for _, generated := range rendered.autoAliases {
+		mp.setAutoAlias(generated.path, generated.alias)
+	}
+
+	for _, alias := range rendered.shortenedAliases {
+		mp.shortenedAliases = append(mp.shortenedAliases, alias)
+		mp.warnShortenedAlias(alias, mp.shortenScanAlias(alias))
+	}
+
+	for _, duplicate := range rendered.duplicateAliases {
+		mp.duplicateAliases = append(mp.duplicateAliases, duplicate)
+		mp.failDuplicateAlias(duplicate)
+	}
+}
@@ -0,0 +1,175 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Tag struct {
+	ID int64 `db:"id"`
+}
+
+type Note struct {
+	ID   int64 `db:"id"`
+	Tag  *Tag  `db:"tag" dbalias:"t"`
+	Tag2 *Tag  `db:"tag2" dbalias:"t2"`
+}
+
+func TestRenderedColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  []any
+		second []any
+		want   string
+		hits   int64
+		err    error
+	}{
+		{
+			name:   "same call",
+			first:  []any{User{}, "u"},
+			second: []any{User{}, "u"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"`,
+			hits:   1,
+		},
+		{
+			name:   "other alias",
+			first:  []any{User{}, "u"},
+			second: []any{User{}, "u2"},
+			want:   `u2.id, u2.name, um.id AS "meta.id", um.city AS "meta.city"`,
+		},
+		{
+			name:   "other join alias",
+			first:  []any{User{}, "u", mfp.M{N: "Meta", A: "m"}},
+			second: []any{User{}, "u", mfp.M{N: "Meta", A: "m2"}},
+			want:   `u.id, u.name, m2.id AS "meta.id", m2.city AS "meta.city"`,
+		},
+		{
+			name:   "same joins in other order",
+			first:  []any{Note{}, "n", mfp.M{N: "Tag", A: "a"}, mfp.M{N: "Tag2", A: "b"}},
+			second: []any{Note{}, "n", mfp.M{N: "Tag2", A: "b"}, mfp.M{N: "Tag", A: "a"}},
+			want:   `n.id, a.id AS "tag.id", b.id AS "tag2.id"`,
+			hits:   1,
+		},
+		{
+			name:   "coalesced join",
+			first:  []any{User{}, "u", mfp.M{N: "Meta", A: "m"}},
+			second: []any{User{}, "u", mfp.M{N: "Meta", A: "m", C: true}},
+			want:   `u.id, u.name, COALESCE(m.id, 0) AS "meta.id", COALESCE(m.city, '') AS "meta.city"`,
+		},
+		{
+			name:   "other option",
+			first:  []any{User{}, "u"},
+			second: []any{User{}, "u", mfp.WithDepth(0)},
+			want:   "u.id, u.name",
+		},
+		{
+			name:   "failure reported again",
+			first:  []any{User{}, "u", mfp.M{N: "Mta", A: "m"}},
+			second: []any{User{}, "u", mfp.M{N: "Mta", A: "m"}},
+			want:   "u.id, u.name",
+			hits:   1,
+			err:    prefixererr.ErrUnknownJoinModel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.Strict())
+			m.Columns(test.first...)
+
+			if got := m.Columns(test.second...).String(); got != test.want {
+				t.Errorf("Columns() = %q, want %q", got, test.want)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+
+			if stats := m.RenderedCacheStats(); stats.Hits != test.hits || stats.Misses != 2-test.hits {
+				t.Errorf("RenderedCacheStats() = %+v, want %d hits", stats, test.hits)
+			}
+		})
+	}
+}
+
+func TestRenderedCacheLRU(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		aliases []string
+		stats   mfp.RenderedCacheStats
+	}{
+		{
+			name:    "within limit",
+			size:    2,
+			aliases: []string{"a", "b", "a", "b"},
+			stats:   mfp.RenderedCacheStats{Entries: 2, MaxEntries: 2, Hits: 2, Misses: 2},
+		},
+		{
+			name:    "least recently used is evicted",
+			size:    2,
+			aliases: []string{"a", "b", "a", "c", "a", "b"},
+			stats:   mfp.RenderedCacheStats{Entries: 2, MaxEntries: 2, Hits: 2, Misses: 4, Evictions: 2},
+		},
+		{
+			name:    "scan of more entries than limit",
+			size:    2,
+			aliases: []string{"a", "b", "c", "a", "b", "c"},
+			stats:   mfp.RenderedCacheStats{Entries: 2, MaxEntries: 2, Misses: 6, Evictions: 4},
+		},
+		{
+			name:    "disabled",
+			size:    0,
+			aliases: []string{"a", "a"},
+			stats:   mfp.RenderedCacheStats{Misses: 2},
+		},
+		{
+			name:    "negative size",
+			size:    -1,
+			aliases: []string{"a", "a"},
+			stats:   mfp.RenderedCacheStats{Misses: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithRenderedCacheSize(test.size))
+
+			for _, alias := range test.aliases {
+				want := alias + ".id, " + alias + ".city"
+				if got := m.Columns(UserMeta{}, alias).String(); got != want {
+					t.Fatalf("Columns() = %q, want %q", got, want)
+				}
+			}
+
+			if stats := m.RenderedCacheStats(); stats != test.stats {
+				t.Errorf("RenderedCacheStats() = %+v, want %+v", stats, test.stats)
+			}
+		})
+	}
+}
+
+func TestSetRenderedCacheSize(t *testing.T) {
+	m := mfp.New()
+
+	for _, alias := range []string{"a", "b", "c"} {
+		m.Columns(UserMeta{}, alias)
+	}
+
+	// shrinking the cache evicts the least recently used entries at once
+	m.SetRenderedCacheSize(1)
+
+	want := mfp.RenderedCacheStats{Entries: 1, MaxEntries: 1, Misses: 3, Evictions: 2}
+	if stats := m.RenderedCacheStats(); stats != want {
+		t.Fatalf("RenderedCacheStats() = %+v, want %+v", stats, want)
+	}
+
+	m.Columns(UserMeta{}, "c")
+
+	if stats := m.RenderedCacheStats(); stats.Hits != 1 {
+		t.Errorf("RenderedCacheStats() = %+v, want the most recently used entry kept", stats)
+	}
+}
@@ -0,0 +1,101 @@
+package model_fields_prefixer
+
+import (
+	"maps"
+)
+
+// Result is an immutable snapshot of the built columns. Unlike the prefixer it can be kept,
+// shared between goroutines and used any number of times
+type Result struct {
+	columns string
+	slice   []string
+	// values are the values of the placeholders InQuery of the prefixer renders the query with
+	values    queryValues
+	templates *queryTemplates
+	aliases   []string
+	args      []any
+	err       error
+}
+
+// Build works as Columns but returns the built columns as Result instead of keeping them in the prefixer.
+// It doesn't change the state of mp, so it is safe to call concurrently on the shared instance:
+//
+//	query := mp.Build(User{}, "u", mfp.M{N: "UserMeta", A: "um"}).InQuery("SELECT {columns} FROM users u ...")
+func (mp *ModelFieldsPrefixer) Build(args ...any) Result {
+	p := mp.Acquire()
+	defer p.Release()
+
+	return p.Columns(args...).Result()
+}
+
+// Result returns the snapshot of the columns, joins and ORDER BY clause built by the prefixer
+func (mp *ModelFieldsPrefixer) Result() Result {
+	values := mp.queryValues(true)
+	values.hints = maps.Clone(values.hints)
+	values.ctes = append([]cte(nil), values.ctes...)
+
+	return Result{
+		columns:   mp.String(),
+		slice:     mp.Slice(),
+		values:    values,
+		templates: mp.cache.templates,
+		aliases:   mp.Aliases(),
+		args:      mp.Args(),
+		err:       mp.Err(),
+	}
+}
+
+// Err returns failures of building the columns in strict mode, see Strict
+func (r Result) Err() error {
+	return r.err
+}
+
+// String returns the columns list, e.g. 'u.id, um.city AS "um.city"'
+func (r Result) String() string {
+	return r.columns
+}
+
+// InQuery renders the query as InQuery of the prefixer the result is taken from: it replaces {columns}, {joins},
+// {orderby}, {groupby}, {locking}, {pagination}, {keyset}, {where}, {table} and {table:Name} placeholders, table hints
+// and CTEs and appends the sqlcommenter comment
+func (r Result) InQuery(query string) string {
+	if r.templates == nil {
+		return renderQuery(compileQuery(query), r.values)
+	}
+
+	return renderQuery(r.templates.get(query), r.values)
+}
+
+// Slice returns the columns as separate expressions
+func (r Result) Slice() []string {
+	slice := make([]string, len(r.slice))
+	copy(slice, r.slice)
+
+	return slice
+}
+
+// Joins returns lateral joins of the join models which are joined from function calls
+func (r Result) Joins() string {
+	return r.values.joins
+}
+
+// Aliases returns db aliases of the tables involved in the columns list in order of their appearance
+func (r Result) Aliases() []string {
+	aliases := make([]string, len(r.aliases))
+	copy(aliases, r.aliases)
+
+	return aliases
+}
+
+// Args returns bind parameters of the columns followed by queryArgs
+func (r Result) Args(queryArgs ...any) []any {
+	args := make([]any, 0, len(r.args)+len(queryArgs))
+	args = append(args, r.args...)
+
+	return append(args, queryArgs...)
+}
+
+// ColumnCount returns the number of columns including custom ones
+func (r Result) ColumnCount() int {
+	return len(r.slice)
+}
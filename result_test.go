@@ -0,0 +1,82 @@
+package model_fields_prefixer_test
+
+import (
+	"context"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestResultInQuery(t *testing.T) {
+	comment := func(ctx context.Context) map[string]string {
+		return map[string]string{"route": "/users"}
+	}
+
+	tests := []struct {
+		name  string
+		opts  []mfp.Option
+		build func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer
+		query string
+		want  string
+	}{
+		{
+			name: "clauses",
+			build: func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return mp.Columns(User{}, "u").Where(UserFilter{City: "Paris"}).Paginate(2, 10, mfp.Sort{Column: "name"})
+			},
+			query: "SELECT {columns} FROM users u JOIN user_meta um ON um.id = u.id {where} {pagination}",
+			want:  `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u JOIN user_meta um ON um.id = u.id WHERE um.city = $1 ORDER BY u.name LIMIT 10 OFFSET 10`,
+		},
+		{
+			name: "tables",
+			opts: []mfp.Option{mfp.WithTableQualifier("app")},
+			build: func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return mp.Columns(User{}, "u")
+			},
+			query: "SELECT {columns} FROM {table} u JOIN {table:UserMeta} um ON um.id = u.id",
+			want:  `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM app.users u JOIN app.user_meta um ON um.id = u.id`,
+		},
+		{
+			name: "hints",
+			opts: []mfp.Option{mfp.WithDialect(mfp.DialectMSSQL)},
+			build: func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return mp.Columns(User{}, "u", mfp.M{N: "Meta", A: "um", H: "WITH (NOLOCK)"})
+			},
+			query: "SELECT {columns} FROM users u JOIN user_meta um {hint:Meta} ON um.id = u.id",
+			want:  "SELECT u.id, u.name, um.id AS [meta.id], um.city AS [meta.city] FROM users u JOIN user_meta um WITH (NOLOCK) ON um.id = u.id",
+		},
+		{
+			name: "comment",
+			opts: []mfp.Option{mfp.WithSQLCommenter(comment)},
+			build: func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return mp.Columns(User{}, "u")
+			},
+			query: "SELECT {columns} FROM users u;",
+			want:  `SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u /*route='%2Fusers'*/;`,
+		},
+		{
+			name: "pretty",
+			build: func(mp *mfp.ModelFieldsPrefixer) *mfp.ModelFieldsPrefixer {
+				return mp.SetPrettyQuery(true).Columns(User{}, "u", mfp.M{N: "Meta", A: "um"})
+			},
+			query: "SELECT {columns} FROM users u",
+			want:  "SELECT \n    u.id,\n    u.name,\n    um.id AS \"meta.id\",\n    um.city AS \"meta.city\"\n FROM users u",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := tt.build(mfp.New(tt.opts...))
+
+			result := mp.Result()
+
+			if got := mp.InQuery(tt.query); got != tt.want {
+				t.Errorf("InQuery() = %q, want %q", got, tt.want)
+			}
+
+			if got := result.InQuery(tt.query); got != tt.want {
+				t.Errorf("Result().InQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+)
+
+// ScanTargets returns pointers to the fields of dest in the exact order of the columns built by the last Columns call,
+// so the result can be passed to rows.Scan directly. dest must be a pointer to the struct passed to Columns.
+// Nil pointers to nested structs are allocated. Custom columns and columns of slice relations are scanned into
+// placeholders and discarded
+func (mp *ModelFieldsPrefixer) ScanTargets(dest any) []any {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	v = v.Elem()
+
+	targets := make([]any, 0, len(mp.columns))
+
+	for _, column := range mp.columns {
+		targets = append(targets, mp.scanTarget(v, column))
+	}
+
+	return targets
+}
+
+// scanTarget returns pointer to the column's field or a placeholder if the field can't be addressed.
+// Fields with codecs are scanned through the codec
+func (mp *ModelFieldsPrefixer) scanTarget(v reflect.Value, column columnInfo) any {
+	if len(column.path) == 0 {
+		return new(any)
+	}
+
+	field, ok := allocFieldByPath(v, column.path)
+	if !ok {
+		return new(any)
+	}
+
+	if column.field.Codec != "" {
+		return &codecScanner{mp: mp, field: column.field, dest: field}
+	}
+
+	return field.Addr().Interface()
+}
@@ -0,0 +1,75 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// duplicateAlias is the scan alias used by several columns and the unique alias the later column is renamed to
+type duplicateAlias struct {
+	alias  string
+	unique string
+}
+
+// uniqueScanAlias returns the alias if no column of the result set has it yet, otherwise the alias suffixed with
+// the first free number starting from 2 - 'id_2', 'id_3'. Columns written earlier keep their aliases, so the first
+// column with the alias in the order of rendering keeps it
+func (mp *ModelFieldsPrefixer) uniqueScanAlias(alias string) string {
+	if !mp.scanAliasesReady {
+		mp.collectScanAliases()
+	}
+
+	if _, ok := mp.scanAliases[alias]; !ok {
+		mp.scanAliases[alias] = struct{}{}
+
+		return alias
+	}
+
+	unique := alias
+	for n := 2; ; n++ {
+		unique = alias + "_" + strconv.Itoa(n)
+		if _, ok := mp.scanAliases[unique]; !ok {
+			break
+		}
+	}
+
+	mp.scanAliases[unique] = struct{}{}
+
+	duplicate := duplicateAlias{alias: alias, unique: unique}
+	mp.duplicateAliases = append(mp.duplicateAliases, duplicate)
+	mp.failDuplicateAlias(duplicate)
+
+	return unique
+}
+
+// collectScanAliases puts result set names of the written columns to the set, it is collected lazily since columns
+// restored from the rendered cache need it only if more columns are written after them
+func (mp *ModelFieldsPrefixer) collectScanAliases() {
+	if mp.scanAliases == nil {
+		mp.scanAliases = make(map[string]struct{}, len(mp.columns))
+	}
+
+	clear(mp.scanAliases)
+
+	for _, column := range mp.columns {
+		if column.field == nil {
+			continue
+		}
+
+		name := column.scanAlias
+		if name == "" {
+			name = column.field.DBTag
+		}
+
+		mp.scanAliases[name] = struct{}{}
+	}
+
+	mp.scanAliasesReady = true
+}
+
+func (mp *ModelFieldsPrefixer) failDuplicateAlias(duplicate duplicateAlias) {
+	mp.fail(fmt.Errorf("%w: %s is renamed to %s", prefixererr.ErrDuplicateScanAlias, duplicate.alias, duplicate.unique),
+		"model", mp.rootModelName())
+}
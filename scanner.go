@@ -0,0 +1,226 @@
+package model_fields_prefixer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errScanDest = errors.New("dest must be a non-nil pointer to a struct or to a slice of structs")
+
+// Scan hydrates dest from rows using the column names produced by Columns, e.g. 'id' goes to the root model and
+// 'um.user_id' goes to the nested model with 'um' db tag. If dest is a pointer to a struct then the current row is scanned,
+// if dest is a pointer to a slice of structs (or pointers to structs) then all the rows are scanned and appended to it.
+// Nested models referenced by pointers stay nil if all of their columns are NULL, so LEFT JOINs map naturally
+func (mp *ModelFieldsPrefixer) Scan(rows *sql.Rows, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errScanDest
+	}
+
+	v = v.Elem()
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return mp.scanRow(rows, v)
+
+	case reflect.Slice:
+		elemType := v.Type().Elem()
+
+		isPtr := elemType.Kind() == reflect.Ptr
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+
+		if elemType.Kind() != reflect.Struct {
+			return errScanDest
+		}
+
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+
+		// the columns are resolved once for all the rows
+		targets, err := mp.resolveColumns(elemType, columns)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			elem := reflect.New(elemType)
+
+			if err = mp.scanValue(targets, rows.Scan, elem.Elem()); err != nil {
+				return err
+			}
+
+			if isPtr {
+				v.Set(reflect.Append(v, elem))
+			} else {
+				v.Set(reflect.Append(v, elem.Elem()))
+			}
+		}
+
+		return rows.Err()
+
+	default:
+		return errScanDest
+	}
+}
+
+func (mp *ModelFieldsPrefixer) scanRow(rows *sql.Rows, v reflect.Value) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	targets, err := mp.resolveColumns(v.Type(), columns)
+	if err != nil {
+		return err
+	}
+
+	return mp.scanValue(targets, rows.Scan, v)
+}
+
+// ScanRow hydrates dest (a pointer to a struct) from a row the same way as Scan does, but works with any driver:
+// columns are names of the row's columns and scan is the function scanning the row, e.g. Scan method of pgx.Rows
+func (mp *ModelFieldsPrefixer) ScanRow(columns []string, scan func(dest ...any) error, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("dest must be a non-nil pointer to a struct")
+	}
+
+	targets, err := mp.resolveColumns(v.Elem().Type(), columns)
+	if err != nil {
+		return err
+	}
+
+	return mp.scanValue(targets, scan, v.Elem())
+}
+
+// resolveColumns returns the columns of the model rendered by Columns in the order of the columns of the result set,
+// columns of the result set which Columns doesn't render have no fields
+func (mp *ModelFieldsPrefixer) resolveColumns(t reflect.Type, columns []string) ([]columnInfo, error) {
+	modelInfo := mp.getModelInfo(t)
+	if modelInfo == nil {
+		return nil, fmt.Errorf("model %s has no db tags", t.Name())
+	}
+
+	columnsByName := mp.scanColumns(modelInfo)
+
+	targets := make([]columnInfo, len(columns))
+	for i, column := range columns {
+		targets[i] = columnsByName[column]
+	}
+
+	return targets, nil
+}
+
+func (mp *ModelFieldsPrefixer) scanValue(targets []columnInfo, scan func(dest ...any) error, v reflect.Value) error {
+	holders := make([]any, len(targets))
+
+	for i, target := range targets {
+		if target.field == nil || target.field.Codec != "" {
+			// values of fields with codecs are decoded after scanning, so the relation is allocated only if needed
+			holders[i] = new(any)
+
+			continue
+		}
+
+		// scan into pointer to pointer, so NULL values can be told apart from zero ones
+		holders[i] = reflect.New(reflect.PtrTo(target.field.Type)).Interface()
+	}
+
+	if err := scan(holders...); err != nil {
+		return err
+	}
+
+	for i, target := range targets {
+		if target.path == nil {
+			continue
+		}
+
+		value := reflect.ValueOf(holders[i]).Elem()
+		if value.IsNil() {
+			continue
+		}
+
+		field, ok := allocFieldByPath(v, target.path)
+		if !ok {
+			continue
+		}
+
+		if target.field.Codec != "" {
+			if err := mp.decodeValue(target.field, value.Interface(), field); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		field.Set(value.Elem())
+	}
+
+	return nil
+}
+
+// scanColumns maps names of the columns in the result set to the columns rendered by Columns, so the columns are
+// scanned to the fields they are rendered from, including the ones renamed to unique aliases. The columns of the last
+// Columns call are used if it was called with the model, otherwise the ones Columns renders for the model with
+// all its relations. Paths of columns of slice relations are nil as they can't be scanned to a single struct
+func (mp *ModelFieldsPrefixer) scanColumns(modelInfo *ModelInfo) map[string]columnInfo {
+	columns := mp.columns
+
+	if mp.rootModel != modelInfo {
+		p := mp.Acquire()
+		defer p.Release()
+
+		// the columns are rendered only to resolve their names, their warnings are reported when the query is built
+		p.logger = nil
+		p.debug = false
+
+		columns = p.ColumnsOf(modelInfo, "").columns
+	}
+
+	columnsByName := make(map[string]columnInfo, len(columns))
+
+	for _, column := range columns {
+		// custom columns aren't scanned
+		if column.field == nil {
+			continue
+		}
+
+		name := column.scanAlias
+		if name == "" {
+			name = column.field.DBTag
+		}
+
+		columnsByName[name] = column
+	}
+
+	return columnsByName
+}
+
+// allocFieldByPath returns the field the path leads to, allocating nil pointers to nested structs on the way
+func allocFieldByPath(v reflect.Value, path []int) (reflect.Value, bool) {
+	for _, index := range path[:len(path)-1] {
+		v = v.Field(index)
+
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+	}
+
+	field := v.Field(path[len(path)-1])
+
+	return field, field.CanSet()
+}
@@ -0,0 +1,111 @@
+package model_fields_prefixer_test
+
+import (
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+type Order struct {
+	ID     int64  `db:"id,pk"`
+	Buyer  *Party `db:"buyer"`
+	Seller *Party `db:"seller"`
+}
+
+type Party struct {
+	Name string `db:"name,as=party_name"`
+}
+
+// scanValues returns the scan function setting the values to the destinations as drivers do
+func scanValues(values ...any) func(dest ...any) error {
+	return func(dest ...any) error {
+		for i, d := range dest {
+			target := reflect.ValueOf(d).Elem()
+			value := reflect.ValueOf(values[i])
+
+			if target.Kind() == reflect.Ptr {
+				ptr := reflect.New(target.Type().Elem())
+				ptr.Elem().Set(value)
+				value = ptr
+			}
+
+			target.Set(value)
+		}
+
+		return nil
+	}
+}
+
+func TestScanRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns func(mp *mfp.ModelFieldsPrefixer)
+		result  []string
+		values  []any
+		want    Order
+	}{
+		{
+			name:    "all relations",
+			columns: func(mp *mfp.ModelFieldsPrefixer) { mp.Columns(Order{}, "o") },
+			result:  []string{"id", "party_name", "party_name_2"},
+			values:  []any{int64(1), "alice", "bob"},
+			want:    Order{ID: 1, Buyer: &Party{Name: "alice"}, Seller: &Party{Name: "bob"}},
+		},
+		{
+			name:    "one relation",
+			columns: func(mp *mfp.ModelFieldsPrefixer) { mp.Columns(Order{}, "o", mfp.M{N: "Seller", A: "s"}) },
+			result:  []string{"id", "party_name"},
+			values:  []any{int64(1), "bob"},
+			want:    Order{ID: 1, Seller: &Party{Name: "bob"}},
+		},
+		{
+			name:    "no columns built",
+			columns: func(mp *mfp.ModelFieldsPrefixer) {},
+			result:  []string{"id", "party_name", "party_name_2"},
+			values:  []any{int64(1), "alice", "bob"},
+			want:    Order{ID: 1, Buyer: &Party{Name: "alice"}, Seller: &Party{Name: "bob"}},
+		},
+		{
+			name:    "other model built",
+			columns: func(mp *mfp.ModelFieldsPrefixer) { mp.Columns(User{}, "u") },
+			result:  []string{"id", "party_name", "custom"},
+			values:  []any{int64(1), "alice", "ignored"},
+			want:    Order{ID: 1, Buyer: &Party{Name: "alice"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mp := mfp.New()
+			test.columns(mp)
+
+			var order Order
+			if err := mp.ScanRow(test.result, scanValues(test.values...), &order); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(order, test.want) {
+				t.Errorf("ScanRow() = %+v, want %+v", order, test.want)
+			}
+		})
+	}
+}
+
+func TestScanTargetsMulti(t *testing.T) {
+	mp := mfp.New().ColumnsMulti(mfp.Root{Model: Order{}, Alias: "o"}, mfp.Root{Model: User{}, Alias: "u"})
+
+	var order Order
+
+	targets := mp.ScanTargets(&order)
+	if len(targets) != mp.ColumnCount() {
+		t.Fatalf("ScanTargets() returned %d targets for %d columns", len(targets), mp.ColumnCount())
+	}
+
+	// the columns of the second root are scanned to placeholders instead of the fields of the first root
+	for _, target := range targets[3:] {
+		if _, ok := target.(*any); !ok {
+			t.Errorf("target of the second root's column is %T, want *any", target)
+		}
+	}
+}
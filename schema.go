@@ -0,0 +1,345 @@
+package model_fields_prefixer
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// TypeScript generates TypeScript interfaces describing rows of the model. Properties are named by db tags,
+// nested models get their own interfaces, so the result mirrors the structure the columns are mapped into
+func (mp *ModelFieldsPrefixer) TypeScript(model any) string {
+	t, ok := modelType(model)
+	if !ok {
+		return ""
+	}
+
+	modelInfo := mp.getModelInfo(t)
+	if modelInfo == nil {
+		return ""
+	}
+
+	w := &typeScriptWriter{
+		buf:     &bytes.Buffer{},
+		names:   make(map[reflect.Type]string),
+		used:    make(map[string]struct{}),
+		written: make(map[reflect.Type]struct{}),
+	}
+
+	w.writeInterface(t, modelInfo)
+
+	return w.buf.String()
+}
+
+// typeScriptWriter writes interfaces of the model and its nested models. Interfaces are keyed by the models' types,
+// so models of different packages with the same name (e.g. billing.Address and shipping.Address) get their own ones
+// with distinct names
+type typeScriptWriter struct {
+	buf *bytes.Buffer
+	// names are the names of the interfaces of the types and used is the set of them
+	names map[reflect.Type]string
+	used  map[string]struct{}
+	// written are the types whose interfaces are already written
+	written map[reflect.Type]struct{}
+}
+
+// name returns the name of the interface of the type: the name of the model, or the name suffixed with the first
+// free number starting from 2 if another type has it, e.g. 'Address2'
+func (w *typeScriptWriter) name(t reflect.Type, model *ModelInfo) string {
+	if name, ok := w.names[t]; ok {
+		return name
+	}
+
+	unique := model.Name
+	for n := 2; ; n++ {
+		if _, ok := w.used[unique]; !ok {
+			break
+		}
+
+		unique = model.Name + strconv.Itoa(n)
+	}
+
+	w.names[t] = unique
+	w.used[unique] = struct{}{}
+
+	return unique
+}
+
+func (w *typeScriptWriter) writeInterface(t reflect.Type, model *ModelInfo) {
+	if _, ok := w.written[t]; ok {
+		return
+	}
+
+	w.written[t] = struct{}{}
+
+	w.buf.WriteString("export interface ")
+	w.buf.WriteString(w.name(t, model))
+	w.buf.WriteString(" {\n")
+
+	for _, field := range model.Fields {
+		// write only columns are never selected, so they are not a part of rows
+		if field.IsWriteOnly {
+			continue
+		}
+
+		w.buf.WriteString("  ")
+		w.buf.WriteString(field.DBTag)
+		w.buf.WriteString(": ")
+
+		if field.IsStruct && field.ModelInfo != nil {
+			elemType, depth := relationElemType(field.Type)
+
+			tsType := w.name(elemType, field.ModelInfo) + strings.Repeat("[]", depth)
+
+			if field.Type.Kind() == reflect.Ptr {
+				tsType += " | null"
+			}
+
+			w.buf.WriteString(tsType)
+		} else {
+			tsType, nullable := typeScriptType(field.Type)
+			if nullable {
+				tsType += " | null"
+			}
+
+			w.buf.WriteString(tsType)
+		}
+
+		w.buf.WriteString(";\n")
+	}
+
+	w.buf.WriteString("}\n")
+
+	for _, field := range model.Fields {
+		if field.IsStruct && field.ModelInfo != nil {
+			elemType, _ := relationElemType(field.Type)
+			if _, ok := w.written[elemType]; ok {
+				continue
+			}
+
+			w.buf.WriteString("\n")
+			w.writeInterface(elemType, field.ModelInfo)
+		}
+	}
+}
+
+// typeScriptType maps Go type of a column to TypeScript type. The second value reports whether the column is nullable
+func typeScriptType(t reflect.Type) (string, bool) {
+	nullable := false
+
+	if t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	if valueType, ok := nullType(t); ok {
+		tsType, _ := typeScriptType(valueType)
+
+		return tsType, true
+	}
+
+	switch schemaType(t) {
+	case "integer", "number":
+		return "number", nullable
+	case "boolean":
+		return "boolean", nullable
+	case "string":
+		return "string", nullable
+	case "array":
+		elemType, _ := typeScriptType(t.Elem())
+
+		return elemType + "[]", nullable
+	default:
+		return "unknown", nullable
+	}
+}
+
+// OpenAPISchema generates OpenAPI schema object describing rows of the model, ready to be marshaled to JSON or YAML
+func (mp *ModelFieldsPrefixer) OpenAPISchema(model any) map[string]any {
+	t, ok := modelType(model)
+	if !ok {
+		return nil
+	}
+
+	modelInfo := mp.getModelInfo(t)
+	if modelInfo == nil {
+		return nil
+	}
+
+	return openAPIObject(modelInfo)
+}
+
+func openAPIObject(model *ModelInfo) map[string]any {
+	properties := make(map[string]any, len(model.Fields))
+	required := make([]string, 0, len(model.Fields))
+
+	for _, field := range model.Fields {
+		var property map[string]any
+
+		nullable := field.Type.Kind() == reflect.Ptr
+
+		if field.IsStruct && field.ModelInfo != nil {
+			property = openAPIObject(field.ModelInfo)
+
+			// every slice around the model, e.g. [][]Tag, is an array of arrays
+			_, depth := relationElemType(field.Type)
+
+			for i := 0; i < depth; i++ {
+				property = map[string]any{
+					"type":  "array",
+					"items": property,
+				}
+			}
+		} else {
+			property, nullable = openAPIProperty(field.Type)
+		}
+
+		if field.IsReadOnly {
+			property["readOnly"] = true
+		}
+
+		if field.IsWriteOnly {
+			property["writeOnly"] = true
+		}
+
+		if nullable {
+			property["nullable"] = true
+		} else {
+			required = append(required, field.DBTag)
+		}
+
+		properties[field.DBTag] = property
+	}
+
+	object := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if model.Name != "" {
+		object["title"] = model.Name
+	}
+
+	if len(required) > 0 {
+		object["required"] = required
+	}
+
+	return object
+}
+
+// openAPIProperty maps Go type of a column to OpenAPI property. The second value reports whether the column is nullable
+func openAPIProperty(t reflect.Type) (map[string]any, bool) {
+	nullable := false
+
+	if t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	if valueType, ok := nullType(t); ok {
+		property, _ := openAPIProperty(valueType)
+
+		return property, true
+	}
+
+	property := make(map[string]any)
+
+	switch schemaType(t) {
+	case "integer":
+		property["type"] = "integer"
+	case "number":
+		property["type"] = "number"
+	case "boolean":
+		property["type"] = "boolean"
+	case "string":
+		property["type"] = "string"
+
+		if t == timeType {
+			property["format"] = "date-time"
+		} else if isBytesType(t) {
+			property["format"] = "byte"
+		}
+	case "array":
+		items, _ := openAPIProperty(t.Elem())
+
+		property["type"] = "array"
+		property["items"] = items
+	default:
+	}
+
+	return property, nullable
+}
+
+// schemaType returns JSON schema type name of the Go type or empty string if it can't be determined
+func schemaType(t reflect.Type) string {
+	if t == timeType || isBytesType(t) {
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		return "array"
+	default:
+		return ""
+	}
+}
+
+// nullType returns the type of value wrapped by sql.NullXXX-like structs, e.g. string for sql.NullString
+func nullType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 || !strings.HasPrefix(t.Name(), "Null") {
+		return nil, false
+	}
+
+	if valid, ok := t.FieldByName("Valid"); !ok || valid.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	return t.Field(0).Type, true
+}
+
+// isBytesType reports whether the type is a byte slice or a byte array, e.g. uuid.UUID
+func isBytesType(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// isSliceType reports whether the relation holds many models, i.e. there is a slice or an array around the model
+func isSliceType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	_, depth := relationElemType(t)
+
+	return depth > 0
+}
+
+// relationElemType unwraps pointers, slices and arrays around the type, e.g. *[]T or [][]*T give T. The second value
+// is the number of slices and arrays among them, e.g. 2 for [][]*T
+func relationElemType(t reflect.Type) (reflect.Type, int) {
+	depth := 0
+
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			t = t.Elem()
+			depth++
+		default:
+			return t, depth
+		}
+	}
+}
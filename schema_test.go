@@ -0,0 +1,56 @@
+package model_fields_prefixer_test
+
+import (
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+// Address is declared again in TestTypeScript, like same-named models of different packages
+type Address struct {
+	City string `db:"city"`
+}
+
+// billingAddress is the package-level Address, the local one shadows it in TestTypeScript
+type billingAddress = Address
+
+func TestTypeScript(t *testing.T) {
+	type Address struct {
+		Street string `db:"street"`
+	}
+
+	type Customer struct {
+		ID       int64          `db:"id,pk"`
+		Home     *Address       `db:"home"`
+		Billing  billingAddress `db:"billing"`
+		Shipping []Address      `db:"shipping"`
+	}
+
+	tests := []struct {
+		name  string
+		model any
+		want  string
+	}{
+		{
+			name:  "nested models",
+			model: User{},
+			want: "export interface User {\n  id: number;\n  name: string;\n  meta: UserMeta | null;\n}\n\n" +
+				"export interface UserMeta {\n  id: number;\n  city: string;\n}\n",
+		},
+		{
+			name:  "same-named models",
+			model: Customer{},
+			want: "export interface Customer {\n  id: number;\n  home: Address | null;\n  billing: Address2;\n  shipping: Address[];\n}\n\n" +
+				"export interface Address {\n  street: string;\n}\n\n" +
+				"export interface Address2 {\n  city: string;\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := mfp.New().TypeScript(test.model); got != test.want {
+				t.Errorf("TypeScript() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
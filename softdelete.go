@@ -0,0 +1,60 @@
+package model_fields_prefixer
+
+// WithTrashed disables soft delete conditions of the current query, so rows marked as deleted are selected as well.
+// It is reset by the next Columns call
+func (mp *ModelFieldsPrefixer) WithTrashed() *ModelFieldsPrefixer {
+	mp.withTrashed = true
+
+	return mp
+}
+
+// softDeleteConditions returns 'alias.deleted_at IS NULL' conditions of all the tables of the query which models
+// have a column marked with 'softdelete' tag option
+func (mp *ModelFieldsPrefixer) softDeleteConditions() []string {
+	if mp.withTrashed {
+		return nil
+	}
+
+	columns := mp.tableColumns(func(field *FieldInfo) bool { return field.IsSoftDelete })
+
+	conditions := make([]string, 0, len(columns))
+	for _, column := range columns {
+		conditions = append(conditions, column+" IS NULL")
+	}
+
+	return conditions
+}
+
+// tableColumns returns rendered columns matching the predicate of the root model and the built join models,
+// one per table. The root model's columns are returned even if they aren't selected, e.g. if they are writeonly
+func (mp *ModelFieldsPrefixer) tableColumns(match func(field *FieldInfo) bool) []string {
+	var columns []string
+
+	added := make(map[string]struct{})
+
+	add := func(dbAlias string, field *FieldInfo) {
+		column := mp.sortableColumn(dbAlias, field)
+		if _, ok := added[column]; ok {
+			return
+		}
+
+		added[column] = struct{}{}
+		columns = append(columns, column)
+	}
+
+	if mp.rootModel != nil {
+		for _, field := range mp.rootModel.Fields {
+			if !field.IsStruct && match(field) {
+				add(mp.rootAlias, field)
+			}
+		}
+	}
+
+	for _, column := range mp.columns {
+		if column.field != nil && !column.field.IsJSON && match(column.field) {
+			add(column.dbAlias, column.field)
+		}
+	}
+
+	return columns
+}
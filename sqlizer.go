@@ -0,0 +1,58 @@
+package model_fields_prefixer
+
+// Sqlizer is the columns list implementing squirrel.Sqlizer interface
+type Sqlizer struct {
+	sql  string
+	args []any
+}
+
+// ToSql returns the columns list and bind parameters captured by CustomColumnsf
+func (s Sqlizer) ToSql() (string, []any, error) {
+	return s.sql, s.args, nil
+}
+
+// Sqlizer returns the built columns list as squirrel.Sqlizer, e.g. to be passed to squirrel.SelectBuilder.Column.
+// Use Slice to pass the columns one by one to squirrel.Select
+func (mp *ModelFieldsPrefixer) Sqlizer() Sqlizer {
+	return Sqlizer{sql: string(mp.bytesBuffer.Bytes()[:mp.ByteLen()]), args: mp.Args()}
+}
+
+// Column describes a built column - 'Table.Name AS "Alias"', Alias is empty if the column is not aliased (e.g. columns of the root model).
+// Custom columns and expressions (e.g. masked columns or keys of JSON columns) have only Expr set, for other columns Expr is the whole rendered expression
+type Column struct {
+	Table string
+	Name  string
+	Alias string
+	Expr  string
+}
+
+// IsCustom reports whether the column was added with CustomColumns or is an expression
+func (c Column) IsCustom() bool {
+	return c.Name == ""
+}
+
+// BuiltColumns returns descriptions of the built columns, so they can be converted to expressions of other query builders
+func (mp *ModelFieldsPrefixer) BuiltColumns() []Column {
+	columns := make([]Column, 0, len(mp.columns))
+
+	for _, column := range mp.columns {
+		c := Column{
+			Expr: string(mp.bytesBuffer.Bytes()[column.start:column.end]),
+		}
+
+		// expressions (e.g. keys of JSON columns or masked columns) are described like custom columns
+		if column.field != nil && !column.isExpr {
+			c.Table = column.dbAlias
+			c.Name = column.field.DBTag
+			if column.column != "" {
+				c.Name = column.column
+			}
+
+			c.Alias = column.scanAlias
+		}
+
+		columns = append(columns, c)
+	}
+
+	return columns
+}
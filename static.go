@@ -0,0 +1,70 @@
+package model_fields_prefixer
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// staticBackend serves prebuilt model infos from a map which is never written, so lookups take no locks.
+// Models stored or deleted later (e.g. by SetModelInfo or Invalidate) are kept in the overlay, deleted prebuilt
+// models are stored there as nil
+type staticBackend struct {
+	models   map[reflect.Type]*ModelInfo
+	overlay  syncMapBackend
+	overlaid atomic.Bool
+}
+
+// NewStaticCacheBackend returns the CacheBackend serving the prebuilt model infos, e.g. the ones generated
+// by prefixer-gen (see PrefixerCacheBackend of the generated file). The map must not be modified after the call
+func NewStaticCacheBackend(models map[reflect.Type]*ModelInfo) CacheBackend {
+	return &staticBackend{models: models}
+}
+
+func (b *staticBackend) Load(t reflect.Type) (*ModelInfo, bool) {
+	if b.overlaid.Load() {
+		if modelInfo, ok := b.overlay.Load(t); ok {
+			return modelInfo, modelInfo != nil
+		}
+	}
+
+	modelInfo, ok := b.models[t]
+
+	return modelInfo, ok
+}
+
+func (b *staticBackend) Store(t reflect.Type, modelInfo *ModelInfo) {
+	b.overlay.Store(t, modelInfo)
+	b.overlaid.Store(true)
+}
+
+func (b *staticBackend) Delete(t reflect.Type) {
+	if _, ok := b.models[t]; ok {
+		b.Store(t, nil)
+
+		return
+	}
+
+	b.overlay.Delete(t)
+}
+
+func (b *staticBackend) Range(f func(t reflect.Type, modelInfo *ModelInfo) bool) {
+	next := true
+
+	b.overlay.Range(func(t reflect.Type, modelInfo *ModelInfo) bool {
+		if modelInfo != nil {
+			next = f(t, modelInfo)
+		}
+
+		return next
+	})
+
+	for t, modelInfo := range b.models {
+		if !next {
+			return
+		}
+
+		if _, ok := b.overlay.Load(t); !ok {
+			next = f(t, modelInfo)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !prefixer_static
+
+package model_fields_prefixer
+
+// staticModels is false by default: models missing in the cache are scanned with reflection on the first use
+const staticModels = false
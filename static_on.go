@@ -0,0 +1,7 @@
+//go:build prefixer_static
+
+package model_fields_prefixer
+
+// staticModels is true if the binary is built with prefixer_static tag: models are never scanned with reflection,
+// they must be prebuilt, e.g. by prefixer-gen, and put to the cache with WithCacheBackend or SetModelInfo
+const staticModels = true
@@ -0,0 +1,150 @@
+package model_fields_prefixer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// Strict makes failures which are only reported to the logger otherwise (e.g. a model without db tags selecting no
+// columns) returned by Err, so they fail loudly instead of degrading silently. Errors wrap prefixererr errors
+func Strict() Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.strict = true
+	}
+}
+
+// Err returns failures of the last Columns call and the calls following it in strict mode (see Strict), nil otherwise
+func (mp *ModelFieldsPrefixer) Err() error {
+	return errors.Join(mp.errs...)
+}
+
+// checkColumns reports the root model of the last Columns call which has no columns and join models which don't
+// match any of its relations, so typos in join models don't make relations vanish silently
+func (mp *ModelFieldsPrefixer) checkColumns() {
+	if mp.rootModel == nil {
+		return
+	}
+
+	if len(mp.rootModel.Fields) == 0 {
+		mp.fail(fmt.Errorf("%w: %s", prefixererr.ErrNoDBTags, mp.rootModel.Name))
+	}
+
+	for _, name := range mp.unknownJoins {
+		mp.fail(fmt.Errorf("%w: %s of %s", prefixererr.ErrUnknownJoinModel, name, mp.rootModel.Name))
+	}
+}
+
+// fail reports the failure to the logger and keeps it to be returned by Err in strict mode
+func (mp *ModelFieldsPrefixer) fail(err error, args ...any) {
+	mp.warn(err.Error(), args...)
+
+	if mp.strict {
+		mp.errs = append(mp.errs, err)
+	}
+}
+
+// unknownJoinModels returns names of the join models which don't match any relation of the model
+func unknownJoinModels(model *ModelInfo, joinModels []M) []string {
+	var unknown []string
+
+	for _, joinModel := range joinModels {
+		if joinModel.N != "" && !hasRelation(model, joinModel.N, "") {
+			unknown = append(unknown, joinModel.N)
+		}
+	}
+
+	return unknown
+}
+
+// hasRelation reports whether the join model name matches a relation of the model by the dotted path of fields,
+// the name of the field or the name of the model like matchJoinModel does
+func hasRelation(model *ModelInfo, name string, namePath string) bool {
+	for _, field := range model.Fields {
+		if !field.IsStruct || field.ModelInfo == nil {
+			continue
+		}
+
+		fieldNamePath := field.Name
+		if namePath != "" {
+			fieldNamePath = namePath + "." + field.Name
+		}
+
+		if name == fieldNamePath || name == field.Name || name == field.ModelInfo.Name {
+			return true
+		}
+
+		if hasRelation(field.ModelInfo, name, fieldNamePath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InQueryStrict works as InQuery but returns an error if no columns are built, the query has no {columns} placeholder
+// or it has placeholders which are never replaced, e.g. '{colums}', '{table}' without a table resolver, '{hint:Name}'
+// of the name which isn't a join model or a relation of the model or '{cte:name}' of undeclared CTE, so broken
+// templates fail loudly in tests instead of producing 'SELECT {columns}' at runtime. Errors wrap prefixererr errors
+func (mp *ModelFieldsPrefixer) InQueryStrict(query string) (string, error) {
+	var errs []error
+
+	if mp.ByteLen() == 0 {
+		errs = append(errs, prefixererr.ErrNoColumns)
+	} else if !strings.Contains(query, prefixedColumnsPlaceholder) {
+		errs = append(errs, fmt.Errorf("%w: %s", prefixererr.ErrPlaceholderMissing, prefixedColumnsPlaceholder))
+	}
+
+	for _, placeholder := range mp.unboundPlaceholders(query) {
+		errs = append(errs, fmt.Errorf("%w: %s", prefixererr.ErrPlaceholderUnbound, placeholder))
+	}
+
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	return mp.InQuery(query), nil
+}
+
+// unboundPlaceholders returns placeholders of the query template which InQuery leaves as is. The template is checked
+// instead of the rendered query, so braces of the built columns (e.g. JSON paths) are never reported
+func (mp *ModelFieldsPrefixer) unboundPlaceholders(query string) []string {
+	var unbound []string
+
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(query, -1) {
+		if !isQueryPlaceholder(match) || !mp.boundPlaceholder(match[0], match[1], match[2]) {
+			unbound = append(unbound, match[0])
+		}
+	}
+
+	return unbound
+}
+
+// boundPlaceholder returns true if InQuery replaces the known placeholder, namespace and name are empty unless it's
+// the namespaced one
+func (mp *ModelFieldsPrefixer) boundPlaceholder(placeholder, namespace, name string) bool {
+	switch {
+	case placeholder == tablePlaceholder:
+		return mp.rootTableName() != ""
+	case name == "":
+		return true
+	case namespace == "table":
+		return mp.joinTableName(name) != ""
+	case namespace == "hint":
+		// placeholders of join models without hints are removed, only names which match neither the root model (its
+		// table is hinted by Locking in MSSQL) nor its relations are mistakes
+		_, ok := mp.hints[name]
+
+		return ok || mp.rootModel != nil && (mp.rootModel.Name == name || hasRelation(mp.rootModel, name, ""))
+	}
+
+	for _, cte := range mp.ctes {
+		if cte.name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,199 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func TestInQueryStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		joins   []any
+		ctes    []string
+		query   string
+		want    string
+		err     error
+	}{
+		{
+			name:  "bound placeholders",
+			query: "SELECT {columns} FROM users u {where}",
+			want:  "SELECT u.id, u.name, um.id AS \"meta.id\", um.city AS \"meta.city\" FROM users u ",
+		},
+		{
+			name:  "capitalized placeholder",
+			query: "SELECT {Columns} FROM users u",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+		{
+			name:  "placeholder with digits",
+			query: "SELECT {columns}, {columns2} FROM users u",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+		{
+			name:  "hint of the relation without hint",
+			query: "SELECT {columns} FROM users u JOIN user_meta um {hint:Meta} ON um.id = u.id",
+			want:  "SELECT u.id, u.name, um.id AS \"meta.id\", um.city AS \"meta.city\" FROM users u JOIN user_meta um  ON um.id = u.id",
+		},
+		{
+			name:    "hint of the join model",
+			dialect: mfp.DialectMSSQL,
+			joins:   []any{mfp.M{N: "UserMeta", A: "um", H: "WITH (NOLOCK)"}},
+			query:   "SELECT {columns} FROM users u JOIN user_meta um {hint:UserMeta} ON um.id = u.id",
+			want:    "SELECT u.id, u.name, um.id AS [meta.id], um.city AS [meta.city] FROM users u JOIN user_meta um WITH (NOLOCK) ON um.id = u.id",
+		},
+		{
+			name:  "hint of unknown name",
+			query: "SELECT {columns} FROM users u JOIN user_meta um {hint:UserMta} ON um.id = u.id",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+		{
+			name:  "table of the relation",
+			query: "SELECT {columns} FROM users u JOIN {table:UserMeta} um ON um.id = u.id",
+			want:  "SELECT u.id, u.name, um.id AS \"meta.id\", um.city AS \"meta.city\" FROM users u JOIN user_meta um ON um.id = u.id",
+		},
+		{
+			name:  "table of unknown name",
+			query: "SELECT {columns} FROM users u JOIN {table:UserMta} um ON um.id = u.id",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+		{
+			name:  "declared cte",
+			ctes:  []string{"cities"},
+			query: "SELECT {columns}, {cte:cities} FROM users u JOIN cities ON cities.id = um.id",
+			want:  "WITH cities AS (SELECT um.id, um.city FROM user_meta um) SELECT u.id, u.name, um.id AS \"meta.id\", um.city AS \"meta.city\", cities.id, cities.city FROM users u JOIN cities ON cities.id = um.id",
+		},
+		{
+			name:  "undeclared cte",
+			ctes:  []string{"cities"},
+			query: "SELECT {columns}, {cte:towns} FROM users u JOIN towns ON towns.id = um.id",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+		{
+			name:  "unknown namespace",
+			query: "SELECT {columns} FROM users u JOIN {tabel:UserMeta} um ON um.id = u.id",
+			err:   prefixererr.ErrPlaceholderUnbound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			args := append([]any{User{}, "u"}, test.joins...)
+
+			m := mfp.New(mfp.WithDialect(dialect)).Columns(args...)
+
+			for _, name := range test.ctes {
+				m.WithCTE(name, "SELECT {columns} FROM user_meta um", mfp.Root{Model: UserMeta{}, Alias: "um"})
+			}
+
+			query, err := m.InQueryStrict(test.query)
+			if !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("InQueryStrict() error = %v, want %v", err, test.err)
+			}
+
+			if query != test.want {
+				t.Errorf("InQueryStrict() = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
+
+type Member struct {
+	ID     int64     `db:"id"`
+	MetaID int64     `db:"meta_id"`
+	Meta   *UserMeta `db:"meta" dbalias:"um"`
+}
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(m *mfp.ModelFieldsPrefixer) string
+		want  string
+		err   error
+	}{
+		{
+			name: "no failures",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(User{}, "u").InQuery("SELECT {columns} FROM users u")
+			},
+			want: selectUsers[:len(selectUsers)-1],
+		},
+		{
+			name: "model without db tags",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(struct{ ID int64 }{}, "x").String()
+			},
+			err: prefixererr.ErrNoDBTags,
+		},
+		{
+			name: "unknown join model",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(User{}, "u", mfp.M{N: "Profile", A: "p"}).String()
+			},
+			want: "u.id, u.name",
+			err:  prefixererr.ErrUnknownJoinModel,
+		},
+		{
+			name: "alias collision",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Note{}, "n", mfp.M{N: "Tag", A: "t"}, mfp.M{N: "Tag2", A: "t"}).String()
+			},
+			want: `n.id, t.id AS "tag.id", t.id AS "tag2.id"`,
+			err:  prefixererr.ErrAliasCollision,
+		},
+		{
+			name: "duplicate scan alias",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(Member{}, "m", mfp.WithAliasSeparator("_")).String()
+			},
+			want: `m.id, m.meta_id, um.id AS "meta_id_2", um.city AS "meta_city"`,
+			err:  prefixererr.ErrDuplicateScanAlias,
+		},
+		{
+			name: "missing columns placeholder",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				return m.Columns(User{}, "u").InQuery("SELECT * FROM users u")
+			},
+			want: "SELECT * FROM users u",
+			err:  prefixererr.ErrPlaceholderMissing,
+		},
+		{
+			name: "no columns of InQueryStrict",
+			build: func(m *mfp.ModelFieldsPrefixer) string {
+				query, err := m.InQueryStrict("SELECT {columns} FROM users u")
+				if !errors.Is(err, prefixererr.ErrNoColumns) {
+					t.Errorf("InQueryStrict() error = %v, want %v", err, prefixererr.ErrNoColumns)
+				}
+
+				return query
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.Strict())
+
+			if got := test.build(m); got != test.want {
+				t.Errorf("query = %q, want %q", got, test.want)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	m := mfp.New().Columns(struct{ ID int64 }{}, "x")
+	if err := m.Err(); err != nil {
+		t.Errorf("Err() without strict mode = %v, want nil", err)
+	}
+}
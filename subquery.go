@@ -0,0 +1,182 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+const subqueryPlaceholder = "{subquery}"
+
+// Subquery is the snapshot of the built columns embedded into an outer query as a derived table, see AsSubquery.
+// Like Result it can be kept and shared between goroutines
+type Subquery struct {
+	alias string
+	// names are the names of the columns in the result set of the inner query
+	names []subqueryColumn
+	// inner renders the template of the inner query without the comment, the derived table is rendered by From
+	inner func(query string) string
+	// comment is the sqlcommenter comment appended to the outer query by InQuery
+	comment string
+	table   string
+	args    []any
+	err     error
+	// quoter is the prefixer without the cache quoting identifiers like the prefixer the subquery is built by
+	quoter *ModelFieldsPrefixer
+}
+
+// subqueryColumn is the name of the column in the result set of the inner query, quoted is true if the inner query
+// selects it with the quoted alias, e.g. '"um.city"'
+type subqueryColumn struct {
+	name   string
+	quoted bool
+}
+
+// AsSubquery snapshots the built columns to embed the query selecting them as a derived table of an outer query.
+// The outer columns are the columns of the result set of the inner query prefixed with the alias of the subquery,
+// so they are scanned into the model as the inner ones:
+//
+//	sub := mp.Columns(User{}, "u").AsSubquery("sub").From("SELECT {columns} FROM users u {where}")
+//
+//	sub.InQuery("SELECT {columns} FROM {subquery}") // SELECT sub.id, sub."um.city" FROM (SELECT u.id, ...) AS sub
+//
+// Columns without names in the result set, e.g. wildcard ones or custom ones without aliases, can't be referenced
+// by the outer query, so they are skipped with a warning
+func (mp *ModelFieldsPrefixer) AsSubquery(alias string) Subquery {
+	result := mp.Result()
+
+	// the comment is appended to the outer query only
+	comment := result.values.comment
+	result.values.comment = ""
+
+	names := make([]subqueryColumn, 0, len(mp.columns))
+
+	for _, column := range mp.columns {
+		name, ok := mp.subqueryColumn(column)
+		if !ok {
+			mp.warn("subquery column has no name", "column", string(mp.bytesBuffer.Bytes()[column.start:column.end]))
+
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return Subquery{
+		alias:   alias,
+		names:   names,
+		inner:   result.InQuery,
+		comment: comment,
+		args:    result.args,
+		err:     result.err,
+		quoter:  &ModelFieldsPrefixer{config: config{dialect: mp.dialect, quoteAll: mp.quoteAll}},
+	}
+}
+
+// subqueryColumn returns the name of the column in the result set of the query selecting it
+func (mp *ModelFieldsPrefixer) subqueryColumn(column columnInfo) (subqueryColumn, bool) {
+	if column.scanAlias != "" {
+		return subqueryColumn{name: column.scanAlias, quoted: true}, true
+	}
+
+	if column.field == nil {
+		match := customAliasRegexp.FindStringSubmatch(string(mp.bytesBuffer.Bytes()[column.start:column.end]))
+		if match == nil {
+			return subqueryColumn{}, false
+		}
+
+		name := strings.Trim(match[1], "\"`[]")
+
+		return subqueryColumn{name: name, quoted: name != match[1]}, true
+	}
+
+	if column.isExpr {
+		return subqueryColumn{}, false
+	}
+
+	if column.column != "" {
+		return subqueryColumn{name: column.column}, true
+	}
+
+	return subqueryColumn{name: column.field.DBTag}, true
+}
+
+// From renders the inner query template with the built columns as the derived table, e.g.
+// '(SELECT u.id FROM users u) AS sub'. The template's placeholders are replaced as in Result.InQuery
+func (s Subquery) From(query string) Subquery {
+	s.table = "(" + s.inner(query) + ")"
+
+	if keyword := s.quoter.aliasKeyword(); keyword != "" {
+		s.table += " " + keyword
+	}
+
+	s.table += " " + s.quoter.quoteTableAlias(s.alias)
+
+	return s
+}
+
+// AsSubquery wraps the subquery into another layer: the outer columns of s become the inner columns of the new
+// subquery, and From of the new one renders its template with {subquery} replaced by the derived table of s
+func (s Subquery) AsSubquery(alias string) Subquery {
+	return Subquery{
+		alias:   alias,
+		names:   s.names,
+		inner:   s.render,
+		comment: s.comment,
+		args:    s.args,
+		err:     s.err,
+		quoter:  s.quoter,
+	}
+}
+
+// Table returns the derived table rendered by From
+func (s Subquery) Table() string {
+	return s.table
+}
+
+// Slice returns the outer columns, e.g. ['sub.id', 'sub."um.city"']
+func (s Subquery) Slice() []string {
+	columns := make([]string, 0, len(s.names))
+	tableAlias := s.quoter.quoteTableAlias(s.alias)
+
+	for _, column := range s.names {
+		if column.quoted {
+			columns = append(columns, tableAlias+"."+s.quoter.dialect.QuoteIdent(column.name))
+
+			continue
+		}
+
+		columns = append(columns, tableAlias+"."+s.quoter.quoteColumn(column.name))
+	}
+
+	return columns
+}
+
+// String returns the outer columns list, e.g. 'sub.id, sub."um.city"'
+func (s Subquery) String() string {
+	return strings.Join(s.Slice(), ", ")
+}
+
+// InQuery replaces {columns} placeholder of the outer query with the outer columns and {subquery} with the derived
+// table rendered by From, and appends the sqlcommenter comment (see WithSQLCommenter)
+func (s Subquery) InQuery(query string) string {
+	return commentQuery(s.render(query), s.comment)
+}
+
+// render renders the outer query as InQuery without the comment, e.g. as the inner query of another layer
+func (s Subquery) render(query string) string {
+	query = strings.ReplaceAll(query, prefixedColumnsPlaceholder, s.String())
+
+	return strings.ReplaceAll(query, subqueryPlaceholder, s.table)
+}
+
+// Args returns bind parameters of the inner columns followed by queryArgs
+func (s Subquery) Args(queryArgs ...any) []any {
+	args := make([]any, 0, len(s.args)+len(queryArgs))
+	args = append(args, s.args...)
+
+	return append(args, queryArgs...)
+}
+
+// Err returns failures of building the inner columns in strict mode, see Strict
+func (s Subquery) Err() error {
+	return s.err
+}
@@ -0,0 +1,103 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func TestAsSubquery(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		build   func(m *mfp.ModelFieldsPrefixer) mfp.Subquery
+		want    string
+		args    []any
+		err     error
+	}{
+		{
+			name: "derived table",
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u").AsSubquery("sub").From("SELECT {columns} FROM users u")
+			},
+			want: `SELECT sub.id, sub.name, sub."meta.id", sub."meta.city" FROM (SELECT u.id, u.name, um.id AS "meta.id", um.city AS "meta.city" FROM users u) AS sub`,
+		},
+		{
+			name: "custom columns",
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u", mfp.WithDepth(0)).CustomColumns(`LOWER(u.name) AS "lower name"`).
+					CustomColumns("COUNT(*) OVER () as total").CustomColumns("1").AsSubquery("sub").From("SELECT {columns} FROM users u")
+			},
+			want: `SELECT sub.id, sub.name, sub."lower name", sub.total FROM (SELECT u.id, u.name, LOWER(u.name) AS "lower name", COUNT(*) OVER () as total, 1 FROM users u) AS sub`,
+		},
+		{
+			name: "bind parameters",
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u", mfp.WithDepth(0)).Where(UserFilter{Name: "Ann"}).AsSubquery("sub").
+					From("SELECT {columns} FROM users u {where}")
+			},
+			want: `SELECT sub.id, sub.name FROM (SELECT u.id, u.name FROM users u WHERE u.name = $1) AS sub`,
+			args: []any{"Ann"},
+		},
+		{
+			name: "nested subqueries",
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				sub := m.Columns(User{}, "u", mfp.WithDepth(0)).AsSubquery("sub").From("SELECT {columns} FROM users u")
+
+				return sub.AsSubquery("top").From("SELECT {columns} FROM {subquery} LIMIT 10")
+			},
+			want: `SELECT top.id, top.name FROM (SELECT sub.id, sub.name FROM (SELECT u.id, u.name FROM users u) AS sub LIMIT 10) AS top`,
+		},
+		{
+			name:    "mssql",
+			dialect: mfp.DialectMSSQL,
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u").AsSubquery("sub").From("SELECT {columns} FROM users u")
+			},
+			want: "SELECT sub.id, sub.name, sub.[meta.id], sub.[meta.city] FROM (SELECT u.id, u.name, um.id AS [meta.id], um.city AS [meta.city] FROM users u) AS sub",
+		},
+		{
+			name:    "oracle",
+			dialect: mfp.DialectOracle,
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u").AsSubquery("sub").From("SELECT {columns} FROM users u")
+			},
+			want: `SELECT sub.id, sub.name, sub."meta.id", sub."meta.city" FROM (SELECT u.id, u.name, um.id "meta.id", um.city "meta.city" FROM users u) sub`,
+		},
+		{
+			name: "failures of inner columns",
+			build: func(m *mfp.ModelFieldsPrefixer) mfp.Subquery {
+				return m.Columns(User{}, "u", mfp.WithDepth(0)).OrderBy("email").AsSubquery("sub").
+					From("SELECT {columns} FROM users u {orderby}")
+			},
+			want: `SELECT sub.id, sub.name FROM (SELECT u.id, u.name FROM users u ) AS sub`,
+			err:  prefixererr.ErrUnknownColumn,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			sub := test.build(mfp.New(mfp.WithDialect(dialect), mfp.Strict()))
+
+			if got := sub.InQuery("SELECT {columns} FROM {subquery}"); got != test.want {
+				t.Errorf("InQuery() = %q, want %q", got, test.want)
+			}
+
+			if err := sub.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+
+			if args := sub.Args(); !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Args() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
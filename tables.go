@@ -0,0 +1,208 @@
+package model_fields_prefixer
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+)
+
+const tablePlaceholder = "{table}"
+
+// joinTablePlaceholderRegexp matches placeholders of join models' tables, e.g. '{table:UserMeta}'
+var joinTablePlaceholderRegexp = regexp.MustCompile(`\{table:([A-Za-z0-9_.]+)\}`)
+
+// TableResolver returns the physical table of the model and its alias for the request, e.g. 'users_2024_05'
+// for sharded or partitioned tables. Empty table means the model has no table to substitute and empty alias
+// keeps the alias passed to Columns
+type TableResolver func(modelName string, ctx context.Context) (table, alias string)
+
+// WithTableResolver makes Columns resolve tables and aliases of the root model and the join models with the resolver.
+// Resolved aliases replace the passed ones and tables replace '{table}' (the root model's table) and '{table:Name}'
+// (the join model's table) placeholders of InQuery, while cached model infos are reused
+func WithTableResolver(resolver TableResolver) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.tableResolver = resolver
+	}
+}
+
+// WithRequestContext passes the context of the request to the table resolver of WithTableResolver,
+// context.Background() is passed without it
+func WithRequestContext(ctx context.Context) ColumnsOption {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.requestCtx = ctx
+	}
+}
+
+// resolveTables resolves tables and aliases of the root model and the join models, join models are modified in place
+func (mp *ModelFieldsPrefixer) resolveTables(modelName string, dbTableAlias string, joinModels []M) string {
+	if mp.tableResolver == nil {
+		return dbTableAlias
+	}
+
+	ctx := mp.requestCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	table, alias := mp.tableResolver(modelName, ctx)
+
+	mp.rootTable = table
+	if alias != "" {
+		dbTableAlias = alias
+	}
+
+	for i, joinModel := range joinModels {
+		if joinModel.N == "" {
+			continue
+		}
+
+		table, alias := mp.tableResolver(joinModel.N, ctx)
+		if table != "" {
+			if mp.tables == nil {
+				mp.tables = make(map[string]string)
+			}
+
+			mp.tables[joinModel.N] = table
+		}
+
+		if alias != "" {
+			joinModels[i].A = alias
+		}
+	}
+
+	return dbTableAlias
+}
+
+// tableNamer is implemented by models declaring their tables, e.g. GORM models
+type tableNamer interface {
+	TableName() string
+}
+
+// tableName returns the table declared by TableName method of the model with value or pointer receiver,
+// empty if the model has no such method
+func tableName(t reflect.Type) string {
+	if !reflect.PointerTo(t).Implements(reflect.TypeOf((*tableNamer)(nil)).Elem()) {
+		return ""
+	}
+
+	return reflect.New(t).Interface().(tableNamer).TableName()
+}
+
+// modelTable returns the table of the model declared by its TableName method, cached model infos are used
+// to not call the method via reflection on every Columns call
+func (mp *ModelFieldsPrefixer) modelTable(t reflect.Type) string {
+	if modelInfo := mp.cache.getModelCacheValue(t); modelInfo != nil {
+		return modelInfo.Table
+	}
+
+	return tableName(t)
+}
+
+// rootTableName returns the table of the root model which '{table}' placeholder is replaced with: the one resolved
+// by the table resolver or the one declared by TableName method of the model
+func (mp *ModelFieldsPrefixer) rootTableName() string {
+	if mp.rootTable != "" {
+		return mp.rootTable
+	}
+
+	if mp.rootModel != nil {
+		return mp.rootModel.Table
+	}
+
+	return ""
+}
+
+// joinTableName returns the table of the join model which '{table:Name}' placeholder is replaced with: the one
+// resolved by the table resolver or the one declared by TableName method of the model related to the root one
+func (mp *ModelFieldsPrefixer) joinTableName(modelName string) string {
+	if modelName == "" {
+		return ""
+	}
+
+	if table, ok := mp.tables[modelName]; ok {
+		return table
+	}
+
+	if modelInfo := findRelatedModel(mp.rootModel, modelName, make(map[*ModelInfo]bool)); modelInfo != nil {
+		return modelInfo.Table
+	}
+
+	return ""
+}
+
+// findRelatedModel returns info of the model of the name related to the model directly or through other relations
+func findRelatedModel(model *ModelInfo, modelName string, visited map[*ModelInfo]bool) *ModelInfo {
+	if model == nil || visited[model] {
+		return nil
+	}
+
+	visited[model] = true
+
+	for _, field := range model.Fields {
+		if !field.IsStruct || field.ModelInfo == nil {
+			continue
+		}
+
+		if field.ModelInfo.Name == modelName {
+			return field.ModelInfo
+		}
+
+		if related := findRelatedModel(field.ModelInfo, modelName, visited); related != nil {
+			return related
+		}
+	}
+
+	return nil
+}
+
+// joinTables returns the rendered tables of the join models by their names which '{table:Name}' placeholders are
+// replaced with, nil if none of them has a table
+func (mp *ModelFieldsPrefixer) joinTables() map[string]string {
+	var tables map[string]string
+
+	add := func(modelName string) {
+		if _, ok := tables[modelName]; ok {
+			return
+		}
+
+		if table := mp.joinTableName(modelName); table != "" {
+			if tables == nil {
+				tables = make(map[string]string)
+			}
+
+			tables[modelName] = mp.renderTable(table)
+		}
+	}
+
+	for modelName := range mp.tables {
+		add(modelName)
+	}
+
+	if mp.rootModel != nil {
+		walkRelatedModels(mp.rootModel, make(map[*ModelInfo]bool), func(model *ModelInfo) {
+			if model.Table != "" {
+				add(model.Name)
+			}
+		})
+	}
+
+	return tables
+}
+
+// walkRelatedModels calls fn for every model related to the model directly or through other relations
+func walkRelatedModels(model *ModelInfo, visited map[*ModelInfo]bool, fn func(model *ModelInfo)) {
+	if visited[model] {
+		return
+	}
+
+	visited[model] = true
+
+	for _, field := range model.Fields {
+		if !field.IsStruct || field.ModelInfo == nil {
+			continue
+		}
+
+		fn(field.ModelInfo)
+		walkRelatedModels(field.ModelInfo, visited, fn)
+	}
+}
@@ -0,0 +1,64 @@
+package model_fields_prefixer
+
+import (
+	"strconv"
+)
+
+// Tag is the parsed db tag of a field: the column name and its options, e.g. `db:"email,pk,groups=admin,internal"`.
+// Models are scanned with it, so tools reading models from sources (e.g. cmd/prefixer-gen) build the same field infos
+type Tag struct {
+	Name    string
+	Options []string
+}
+
+// ParseTag splits db tag into the column name and its options, e.g. 'id,pk' gives 'id' and ['pk']
+func ParseTag(tag string) Tag {
+	name, options := parseDBTag(tag)
+
+	return Tag{Name: name, Options: options}
+}
+
+// Has reports whether the tag has the flag option, e.g. 'pk'
+func (t Tag) Has(option string) bool {
+	return hasTagOption(t.Options, option)
+}
+
+// Value returns the value of 'name=value' option or empty string if there is no such option
+func (t Tag) Value(name string) string {
+	return tagOptionValue(t.Options, name)
+}
+
+// FieldInfo returns the info of the field declared by the tag's options. The name, the index and the type of the
+// field, the model of the relation and the counted relation (see ResolveCounts) are left to the caller.
+// 'order' options which aren't positive numbers are ignored
+func (t Tag) FieldInfo() FieldInfo {
+	fieldInfo := FieldInfo{
+		DBTag:          t.Name,
+		IsPK:           t.Has("pk"),
+		Codec:          t.Value("codec"),
+		Expr:           t.Value("expr"),
+		IsReadOnly:     t.Has("readonly"),
+		IsAudit:        t.Has("audit"),
+		IsMasked:       t.Has("masked"),
+		IsEncrypted:    t.Has("encrypted"),
+		IsWriteOnly:    t.Has("writeonly"),
+		ScanAlias:      t.Value("as"),
+		IsSoftDelete:   t.Has("softdelete"),
+		IsStructColumn: t.Has("struct"),
+		IsJSON:         t.Has("json"),
+		ForeignKey:     t.Value("fk"),
+		Groups:         tagOptionGroups(t.Options),
+		Locales:        tagOptionLocales(t.Options),
+	}
+
+	if position, err := strconv.Atoi(t.Value("order")); err == nil && position > 0 {
+		fieldInfo.Order = position
+	}
+
+	// JSON columns are written as JSON unless they have another codec
+	if fieldInfo.IsJSON && fieldInfo.Codec == "" {
+		fieldInfo.Codec = "json"
+	}
+
+	return fieldInfo
+}
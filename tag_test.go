@@ -0,0 +1,40 @@
+package model_fields_prefixer_test
+
+import (
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want mfp.FieldInfo
+	}{
+		{"id,pk", mfp.FieldInfo{DBTag: "id", IsPK: true}},
+		{"email,masked,as=mail", mfp.FieldInfo{DBTag: "email", IsMasked: true, ScanAlias: "mail"}},
+		{"meta,json", mfp.FieldInfo{DBTag: "meta", IsJSON: true, Codec: "json"}},
+		{"meta,json,codec=msgpack", mfp.FieldInfo{DBTag: "meta", IsJSON: true, Codec: "msgpack"}},
+		{"name,order=2", mfp.FieldInfo{DBTag: "name", Order: 2}},
+		{"name,order=-1", mfp.FieldInfo{DBTag: "name"}},
+		{"name,order=first", mfp.FieldInfo{DBTag: "name"}},
+		{"user_id,fk=users.id", mfp.FieldInfo{DBTag: "user_id", ForeignKey: "users.id"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			if got := mfp.ParseTag(test.tag).FieldInfo(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseTag(%q).FieldInfo() = %+v, want %+v", test.tag, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTagValue(t *testing.T) {
+	tag := mfp.ParseTag("posts,count=Post,readonly")
+
+	if tag.Name != "posts" || tag.Value("count") != "Post" || !tag.Has("readonly") || tag.Has("count") || tag.Value("as") != "" {
+		t.Errorf("ParseTag() = %+v", tag)
+	}
+}
@@ -0,0 +1,202 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxCompiledQueries limits the number of compiled query templates, templates beyond it are rendered without caching,
+// so queries built dynamically don't grow the cache
+const maxCompiledQueries = 1024
+
+// compiledQuery is the query template split around its placeholders, so it's rendered by concatenation without
+// scanning the query for every placeholder
+type compiledQuery struct {
+	parts []queryPart
+	// size is the length of the template's text without placeholders
+	size       int
+	hasColumns bool
+	// hasTables is true if the template has '{table:Name}' placeholders
+	hasTables bool
+}
+
+// queryPart is the text of the template preceding the placeholder, the placeholder is empty for the trailing text
+type queryPart struct {
+	text        string
+	placeholder string
+	// table is the name of the join model of '{table:Name}' placeholder
+	table string
+}
+
+// queryTemplates keeps compiled query templates keyed by the query text, it is shared by the prefixers sharing the cache
+type queryTemplates struct {
+	mu      sync.RWMutex
+	queries map[string]*compiledQuery
+}
+
+func newQueryTemplates() *queryTemplates {
+	return &queryTemplates{queries: make(map[string]*compiledQuery)}
+}
+
+func (c *queryTemplates) get(query string) *compiledQuery {
+	c.mu.RLock()
+	compiled, ok := c.queries[query]
+	c.mu.RUnlock()
+
+	if ok {
+		return compiled
+	}
+
+	compiled = compileQuery(query)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.queries) < maxCompiledQueries {
+		c.queries[query] = compiled
+	}
+
+	return compiled
+}
+
+// compileQuery splits the query around placeholders replaced by InQuery, other braces are kept as text
+func compileQuery(query string) *compiledQuery {
+	compiled := &compiledQuery{}
+
+	text := 0
+
+	for _, loc := range placeholderRegexp.FindAllStringIndex(query, -1) {
+		placeholder := query[loc[0]:loc[1]]
+		if _, ok := queryPlaceholders[placeholder]; !ok {
+			continue
+		}
+
+		compiled.parts = append(compiled.parts, queryPart{text: query[text:loc[0]], placeholder: placeholder})
+		compiled.hasColumns = compiled.hasColumns || placeholder == prefixedColumnsPlaceholder
+		text = loc[1]
+	}
+
+	compiled.parts = append(compiled.parts, queryPart{text: query[text:]})
+
+	// '{table:Name}' placeholders are split out of the text parts
+	parts := make([]queryPart, 0, len(compiled.parts))
+
+	for _, part := range compiled.parts {
+		text := 0
+
+		for _, match := range joinTablePlaceholderRegexp.FindAllStringSubmatchIndex(part.text, -1) {
+			parts = append(parts, queryPart{
+				text:        part.text[text:match[0]],
+				placeholder: part.text[match[0]:match[1]],
+				table:       part.text[match[2]:match[3]],
+			})
+
+			compiled.hasTables = true
+
+			text = match[1]
+		}
+
+		part.text = part.text[text:]
+		parts = append(parts, part)
+	}
+
+	compiled.parts = parts
+
+	for _, part := range compiled.parts {
+		compiled.size += len(part.text)
+	}
+
+	return compiled
+}
+
+// queryValues are the values of placeholders InQuery renders the template with. Result keeps them, so the
+// snapshot renders the same query as the prefixer it's taken from
+type queryValues struct {
+	columns, orderBy, groupBy, locking, pagination, keyset, where, joins string
+	// table is the rendered table of the root model, tables are the rendered tables of the join models by their names
+	table  string
+	tables map[string]string
+	// hints are the table hints of the join models rendered in '{hint:Name}' placeholders, empty unless in MSSQL
+	hints   map[string]string
+	ctes    []cte
+	comment string
+}
+
+// queryValues returns the values of the placeholders of the built columns, the tables of the join models are resolved
+// only if joinTables is true, e.g. if the template has their placeholders
+func (mp *ModelFieldsPrefixer) queryValues(joinTables bool) queryValues {
+	values := queryValues{
+		columns:    mp.queryColumns(),
+		orderBy:    mp.orderBy,
+		groupBy:    mp.groupByClause(),
+		locking:    mp.locking,
+		pagination: mp.pagination,
+		keyset:     mp.keyset,
+		where:      mp.whereClause(),
+		joins:      mp.Joins(),
+		ctes:       mp.ctes,
+		comment:    mp.queryComment(),
+	}
+
+	if table := mp.rootTableName(); table != "" {
+		values.table = mp.renderTable(table)
+	}
+
+	if joinTables {
+		values.tables = mp.joinTables()
+	}
+
+	if mp.dialect.Name() == "mssql" {
+		values.hints = mp.hints
+	}
+
+	return values
+}
+
+// renderQuery renders the compiled template with the values: placeholders are replaced, then '{hint:Name}' and
+// '{cte:name}' ones, WITH clause is prepended and the sqlcommenter comment is appended
+func renderQuery(compiled *compiledQuery, values queryValues) string {
+	sb := strings.Builder{}
+	sb.Grow(compiled.size + len(values.columns) + len(values.where) + len(values.joins) + len(values.orderBy) + len(values.groupBy))
+
+	for _, part := range compiled.parts {
+		sb.WriteString(part.text)
+		sb.WriteString(placeholderValue(part, values))
+	}
+
+	return commentQuery(withCTEs(values.ctes, renderHints(sb.String(), values.hints)), values.comment)
+}
+
+// placeholderValue returns the value of the part's placeholder, placeholders without values are kept as is
+func placeholderValue(part queryPart, values queryValues) string {
+	switch part.placeholder {
+	case "":
+		return ""
+	case prefixedColumnsPlaceholder:
+		return values.columns
+	case orderByPlaceholder:
+		return values.orderBy
+	case groupByPlaceholder:
+		return values.groupBy
+	case lockingPlaceholder:
+		return values.locking
+	case paginationPlaceholder:
+		return values.pagination
+	case keysetPlaceholder:
+		return values.keyset
+	case wherePlaceholder:
+		return values.where
+	case joinsPlaceholder:
+		return values.joins
+	case tablePlaceholder:
+		if values.table != "" {
+			return values.table
+		}
+	default:
+		if table := values.tables[part.table]; table != "" {
+			return table
+		}
+	}
+
+	return part.placeholder
+}
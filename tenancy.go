@@ -0,0 +1,100 @@
+package model_fields_prefixer
+
+import (
+	"context"
+)
+
+// TenantFunc returns the tenant of the request, e.g. taken from the context by a middleware
+type TenantFunc func(ctx context.Context) any
+
+// WithTenantColumn makes every {where} clause gain the condition comparing the column (e.g. 'tenant_id') of every
+// table of the query which model has such a column with the tenant returned by tenant for the context passed to
+// WithContext. If WithContext isn't called or the tenant is nil, nothing is selected, so the filter can't be forgotten
+func WithTenantColumn(column string, tenant TenantFunc) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.tenantColumn = column
+		mp.tenantFunc = tenant
+	}
+}
+
+// WithContext binds the tenant of ctx to the tenant conditions of the query (see WithTenantColumn) and passes ctx to
+// the query comment (see WithSQLCommenter), it must be called after Columns. The tenant is bound as a parameter
+// (see Args) once for every table having the tenant column, calling it again rebinds the tenant in the same parameters
+func (mp *ModelFieldsPrefixer) WithContext(ctx context.Context) *ModelFieldsPrefixer {
+	mp.queryCtx = ctx
+
+	if mp.tenantFunc == nil {
+		return mp
+	}
+
+	mp.tenant = mp.tenant[:0]
+	mp.tenantBound = true
+
+	columns := mp.tenantColumns()
+	if len(columns) == 0 {
+		return mp
+	}
+
+	tenant := mp.tenantFunc(ctx)
+	if tenant == nil {
+		mp.warn("tenant of the context is nil, nothing is selected", "column", mp.tenantColumn)
+	}
+
+	if len(mp.tenantArgs) == len(columns) && !mp.tenantArgsLast() {
+		// parameters bound after the tenant's ones keep their numbers, so the tenant's ones are reused,
+		// NULL tenant selects nothing as well
+		for i, column := range columns {
+			mp.args[mp.tenantArgs[i]-1] = tenant
+			mp.tenant = append(mp.tenant, column+" = "+mp.dialect.Placeholder(mp.tenantArgs[i]))
+		}
+
+		return mp
+	}
+
+	if mp.tenantArgsLast() {
+		mp.args = mp.args[:len(mp.args)-len(mp.tenantArgs)]
+	}
+
+	mp.tenantArgs = mp.tenantArgs[:0]
+
+	if tenant == nil {
+		mp.tenant = append(mp.tenant, "1 = 0")
+
+		return mp
+	}
+
+	for _, column := range columns {
+		mp.args = append(mp.args, tenant)
+		mp.tenantArgs = append(mp.tenantArgs, len(mp.args))
+		mp.tenant = append(mp.tenant, column+" = "+mp.dialect.Placeholder(len(mp.args)))
+	}
+
+	return mp
+}
+
+// tenantArgsLast returns true if the tenant's bind parameters are the last ones, so they can be dropped
+func (mp *ModelFieldsPrefixer) tenantArgsLast() bool {
+	return len(mp.tenantArgs) > 0 && mp.tenantArgs[len(mp.tenantArgs)-1] == len(mp.args)
+}
+
+// tenantConditions returns conditions bound by WithContext, or the condition selecting nothing if the query has
+// tables with the tenant column but the tenant wasn't bound
+func (mp *ModelFieldsPrefixer) tenantConditions() []string {
+	if mp.tenantFunc == nil {
+		return nil
+	}
+
+	if mp.tenantBound {
+		return mp.tenant
+	}
+
+	if len(mp.tenantColumns()) == 0 {
+		return nil
+	}
+
+	return []string{"1 = 0"}
+}
+
+func (mp *ModelFieldsPrefixer) tenantColumns() []string {
+	return mp.tableColumns(func(field *FieldInfo) bool { return field.DBTag == mp.tenantColumn && field.Expr == "" })
+}
@@ -0,0 +1,105 @@
+package model_fields_prefixer_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+)
+
+type Project struct {
+	ID       int64          `db:"id,pk"`
+	TenantID int64          `db:"tenant_id"`
+	Name     string         `db:"name"`
+	Settings *ProjectConfig `db:"settings" dbalias:"pc"`
+}
+
+type ProjectConfig struct {
+	ID       int64 `db:"id"`
+	TenantID int64 `db:"tenant_id"`
+}
+
+type ProjectFilter struct {
+	Name string `db:"name,omitempty"`
+}
+
+type tenantKey struct{}
+
+func TestWithContextTenant(t *testing.T) {
+	acme := context.WithValue(context.Background(), tenantKey{}, int64(1))
+	globex := context.WithValue(context.Background(), tenantKey{}, int64(2))
+
+	tests := []struct {
+		name  string
+		build func(m *mfp.ModelFieldsPrefixer)
+		where string
+		args  []any
+	}{
+		{
+			name:  "without context",
+			build: func(m *mfp.ModelFieldsPrefixer) {},
+			where: "WHERE 1 = 0",
+		},
+		{
+			name:  "tenant",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.WithContext(acme) },
+			where: "WHERE p.tenant_id = $1 AND pc.tenant_id = $2",
+			args:  []any{int64(1), int64(1)},
+		},
+		{
+			name:  "nil tenant",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.WithContext(context.Background()) },
+			where: "WHERE 1 = 0",
+		},
+		{
+			name:  "tenant after filter",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.Where(ProjectFilter{Name: "apollo"}).WithContext(acme) },
+			where: "WHERE p.name = $1 AND p.tenant_id = $2 AND pc.tenant_id = $3",
+			args:  []any{"apollo", int64(1), int64(1)},
+		},
+		{
+			name:  "rebound tenant",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.WithContext(acme).WithContext(globex) },
+			where: "WHERE p.tenant_id = $1 AND pc.tenant_id = $2",
+			args:  []any{int64(2), int64(2)},
+		},
+		{
+			name: "rebound tenant before filter",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.WithContext(acme).Where(ProjectFilter{Name: "apollo"}).WithContext(globex)
+			},
+			where: "WHERE p.name = $3 AND p.tenant_id = $1 AND pc.tenant_id = $2",
+			args:  []any{int64(2), int64(2), "apollo"},
+		},
+		{
+			name:  "rebound nil tenant",
+			build: func(m *mfp.ModelFieldsPrefixer) { m.WithContext(acme).WithContext(context.Background()) },
+			where: "WHERE 1 = 0",
+		},
+		{
+			name: "rebound nil tenant before filter",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.WithContext(acme).Where(ProjectFilter{Name: "apollo"}).WithContext(context.Background())
+			},
+			where: "WHERE p.name = $3 AND p.tenant_id = $1 AND pc.tenant_id = $2",
+			args:  []any{nil, nil, "apollo"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mfp.New(mfp.WithTenantColumn("tenant_id", func(ctx context.Context) any { return ctx.Value(tenantKey{}) }))
+			m.Columns(Project{}, "p")
+			test.build(m)
+
+			if query, want := m.InQuery("SELECT {columns} FROM projects p {where}"), "SELECT "+m.String()+" FROM projects p "+test.where; query != want {
+				t.Errorf("InQuery() = %q, want %q", query, want)
+			}
+
+			if args := m.Args(); !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Args() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package model_fields_prefixer
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// Names of the spans started by Tracer
+const (
+	// SpanCollect is the span of scanning the model with reflection into the models cache
+	SpanCollect = "prefixer.collect"
+	// SpanColumns is the span of Columns call
+	SpanColumns = "prefixer.columns"
+	// SpanRender is the span of rendering the query by InQuery
+	SpanRender = "prefixer.render"
+)
+
+// Tracer starts spans around scanning models into the cache and rendering columns and queries, so the prefixer's
+// share of the request latency is visible in traces, e.g. OpenTelemetry spans of prefixerotel package.
+// Set it with WithTracer option, methods are called synchronously and must be safe for concurrent use
+type Tracer interface {
+	// Start starts the span of the name as a child of the span of ctx, the returned context carries the new span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the span started by Tracer
+type Span interface {
+	// End ends the span with the attributes of the operation: 'prefixer.model', 'prefixer.columns' and
+	// 'prefixer.cache_hit' (Columns only)
+	End(attrs ...slog.Attr)
+}
+
+// WithTracer sets the tracer starting spans of the prefixer. Spans are children of the context passed to WithContext
+// (InQuery) or WithRequestContext (Columns), spans of models scanned by Columns are children of its span
+func WithTracer(tracer Tracer) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.tracer = tracer
+	}
+}
+
+// startSpan starts the span if the tracer is set, nil otherwise so attributes aren't built on hot paths
+func (mp *ModelFieldsPrefixer) startSpan(name string) Span {
+	if mp.tracer == nil {
+		return nil
+	}
+
+	ctx := mp.spanCtx
+	if ctx == nil {
+		ctx = mp.queryCtx
+	}
+
+	if ctx == nil {
+		ctx = mp.requestCtx
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := mp.tracer.Start(ctx, name)
+
+	// models scanned by Columns are traced as the children of its span
+	if name == SpanColumns {
+		mp.spanCtx = ctx
+	}
+
+	return span
+}
+
+func (mp *ModelFieldsPrefixer) endCollectSpan(span Span, t reflect.Type) {
+	if span != nil {
+		span.End(slog.String("prefixer.model", fullTypeName(t)))
+	}
+}
+
+func (mp *ModelFieldsPrefixer) endColumnsSpan(span Span, t reflect.Type, rendered bool) {
+	if span == nil {
+		return
+	}
+
+	mp.spanCtx = nil
+
+	span.End(slog.String("prefixer.model", fullTypeName(t)), slog.Int("prefixer.columns", len(mp.columns)), slog.Bool("prefixer.cache_hit", rendered))
+}
+
+func (mp *ModelFieldsPrefixer) endRenderSpan(span Span) {
+	if span == nil {
+		return
+	}
+
+	model := ""
+	if mp.rootModel != nil {
+		model = mp.rootModel.Name
+	}
+
+	span.End(slog.String("prefixer.model", model), slog.Int("prefixer.columns", len(mp.columns)))
+}
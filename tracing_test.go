@@ -0,0 +1,115 @@
+package model_fields_prefixer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type spanKey struct{}
+
+// recordingTracer records ended spans as 'name(parent) key=value ...'
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+	parent string
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, mfp.Span) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+
+	return context.WithValue(ctx, spanKey{}, name), recordingSpan{tracer: t, name: name, parent: parent}
+}
+
+func (s recordingSpan) End(attrs ...slog.Attr) {
+	span := s.name + "(" + s.parent + ")"
+	for _, attr := range attrs {
+		span += fmt.Sprintf(" %s=%v", attr.Key, attr.Value)
+	}
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.spans = append(s.tracer.spans, span)
+}
+
+func TestWithTracer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), spanKey{}, "request")
+
+	tests := []struct {
+		name  string
+		build func(m *mfp.ModelFieldsPrefixer)
+		spans []string
+		err   error
+	}{
+		{
+			name: "scanned model",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(User{}, "u")
+			},
+			spans: []string{
+				"prefixer.collect(prefixer.columns) prefixer.model=github.com/ivnku/model-fields-prefixer_test.User",
+				"prefixer.columns() prefixer.model=github.com/ivnku/model-fields-prefixer_test.User prefixer.columns=4 prefixer.cache_hit=false",
+			},
+		},
+		{
+			name: "rendered columns cache",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(UserMeta{}, "um")
+				m.Columns(UserMeta{}, "um", mfp.WithRequestContext(ctx))
+			},
+			spans: []string{
+				"prefixer.collect(prefixer.columns) prefixer.model=github.com/ivnku/model-fields-prefixer_test.UserMeta",
+				"prefixer.columns() prefixer.model=github.com/ivnku/model-fields-prefixer_test.UserMeta prefixer.columns=2 prefixer.cache_hit=false",
+				"prefixer.columns(request) prefixer.model=github.com/ivnku/model-fields-prefixer_test.UserMeta prefixer.columns=2 prefixer.cache_hit=true",
+			},
+		},
+		{
+			name: "rendered query",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.MustRegister(User{}).Columns(User{}, "u", mfp.WithDepth(0)).WithContext(ctx).InQuery("SELECT {columns} FROM users u")
+			},
+			spans: []string{
+				"prefixer.collect() prefixer.model=github.com/ivnku/model-fields-prefixer_test.User",
+				"prefixer.columns() prefixer.model=github.com/ivnku/model-fields-prefixer_test.User prefixer.columns=2 prefixer.cache_hit=false",
+				"prefixer.render(request) prefixer.model=User prefixer.columns=2",
+			},
+		},
+		{
+			name: "not struct",
+			build: func(m *mfp.ModelFieldsPrefixer) {
+				m.Columns(1, "x")
+			},
+			err: prefixererr.ErrNotStruct,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			m := mfp.New(mfp.WithTracer(tracer), mfp.Strict())
+
+			test.build(m)
+
+			if !reflect.DeepEqual(tracer.spans, test.spans) && (len(tracer.spans) > 0 || len(test.spans) > 0) {
+				t.Errorf("spans = %q, want %q", tracer.spans, test.spans)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unionColumn is the column of a UNION branch with the name of the column in its result set
+type unionColumn struct {
+	name subqueryColumn
+	expr string
+}
+
+// UnionColumns builds columns of two models for the branches of UNION query aligned by the names of the columns in
+// their result sets: the columns of modelB are written in order of the columns of modelA and the columns missing in
+// one of the models are padded with NULLs and reported with a warning, so the query doesn't break when a model changes.
+// Bind parameters of the second result follow the ones of the first result:
+//
+//	a, b := mp.UnionColumns(User{}, "u", ArchivedUser{}, "au")
+//
+//	query := a.InQuery("SELECT {columns} FROM users u") + " UNION ALL " + b.InQuery("SELECT {columns} FROM archived_users au")
+//	rows, err := db.Query(query, a.Args(b.Args()...)...)
+func (mp *ModelFieldsPrefixer) UnionColumns(modelA any, aliasA string, modelB any, aliasB string) (Result, Result) {
+	columnsA, resultA := mp.unionBranch(modelA, aliasA)
+	columnsB, resultB := mp.unionBranch(modelB, aliasB)
+
+	names := make([]subqueryColumn, 0, len(columnsA)+len(columnsB))
+	exprsA := make(map[string]string, len(columnsA))
+	exprsB := make(map[string]string, len(columnsB))
+
+	for _, column := range columnsA {
+		names = append(names, column.name)
+		exprsA[column.name.name] = column.expr
+	}
+
+	for _, column := range columnsB {
+		if _, ok := exprsA[column.name.name]; !ok {
+			names = append(names, column.name)
+		}
+
+		exprsB[column.name.name] = column.expr
+	}
+
+	resultA.slice = mp.alignUnion(names, exprsA, modelA, 0)
+	resultA.columns = strings.Join(resultA.slice, ", ")
+	resultA.values.columns = resultA.columns
+
+	resultB.slice = mp.alignUnion(names, exprsB, modelB, len(resultA.args))
+	resultB.columns = strings.Join(resultB.slice, ", ")
+	resultB.values.columns = resultB.columns
+
+	return resultA, resultB
+}
+
+// unionBranch builds columns of the model with the names of the columns in their result sets
+func (mp *ModelFieldsPrefixer) unionBranch(model any, alias string) ([]unionColumn, Result) {
+	p := mp.Acquire()
+	defer p.Release()
+
+	p.Columns(model, alias)
+
+	columns := make([]unionColumn, 0, len(p.columns))
+
+	for _, column := range p.columns {
+		expr := string(p.bytesBuffer.Bytes()[column.start:column.end])
+
+		name, ok := p.subqueryColumn(column)
+		if !ok {
+			mp.warn("union column has no name", "column", expr)
+
+			continue
+		}
+
+		columns = append(columns, unionColumn{name: name, expr: expr})
+	}
+
+	return columns, p.Result()
+}
+
+// alignUnion writes the columns of the branch in order of names, the missing ones are NULLs aliased with the names.
+// Numbered placeholders of the columns are shifted by offset
+func (mp *ModelFieldsPrefixer) alignUnion(names []subqueryColumn, exprs map[string]string, model any, offset int) []string {
+	columns := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if expr, ok := exprs[name.name]; ok {
+			columns = append(columns, mp.renumberPlaceholders(expr, offset))
+
+			continue
+		}
+
+		mp.warn("union column is missing in model", "column", name.name, "model", fmt.Sprintf("%T", model))
+
+		columns = append(columns, "NULL"+mp.nameSuffix(name))
+	}
+
+	return columns
+}
+
+// nameSuffix aliases the expression with the name of the column, names which aren't quoted by the query selecting
+// them are quoted only if they are reserved words
+func (mp *ModelFieldsPrefixer) nameSuffix(name subqueryColumn) string {
+	if name.quoted {
+		return mp.aliasSuffix(name.name)
+	}
+
+	if keyword := mp.aliasKeyword(); keyword != "" {
+		return " " + keyword + " " + mp.quoteColumn(name.name)
+	}
+
+	return " " + mp.quoteColumn(name.name)
+}
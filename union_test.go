@@ -0,0 +1,112 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type ArchivedUser struct {
+	Name   string `db:"name"`
+	ID     int64  `db:"id,pk"`
+	Reason string `db:"reason"`
+}
+
+func TestUnionColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  mfp.Dialect
+		modelA   any
+		aliasA   string
+		modelB   any
+		aliasB   string
+		a        string
+		b        string
+		warnings int
+		err      error
+	}{
+		{
+			name:   "same model",
+			modelA: User{},
+			aliasA: "u",
+			modelB: User{},
+			aliasB: "u2",
+			a:      `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"`,
+			b:      `u2.id, u2.name, um.id AS "meta.id", um.city AS "meta.city"`,
+		},
+		{
+			name:     "reordered and missing columns",
+			modelA:   User{},
+			aliasA:   "u",
+			modelB:   ArchivedUser{},
+			aliasB:   "au",
+			a:        `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", NULL AS reason`,
+			b:        `au.id, au.name, NULL AS "meta.id", NULL AS "meta.city", au.reason`,
+			warnings: 3,
+		},
+		{
+			name:     "mysql",
+			dialect:  mfp.DialectMySQL,
+			modelA:   ArchivedUser{},
+			aliasA:   "au",
+			modelB:   UserMeta{},
+			aliasB:   "um",
+			a:        "au.name, au.id, au.reason, NULL AS city",
+			b:        "NULL AS name, um.id, NULL AS reason, um.city",
+			warnings: 3,
+		},
+		{
+			name:     "oracle",
+			dialect:  mfp.DialectOracle,
+			modelA:   UserMeta{},
+			aliasA:   "um",
+			modelB:   User{},
+			aliasB:   "u",
+			a:        `um.id, um.city, NULL name, NULL "meta.id", NULL "meta.city"`,
+			b:        `u.id, NULL city, u.name, um.id "meta.id", um.city "meta.city"`,
+			warnings: 4,
+		},
+		{
+			name:     "not struct",
+			modelA:   User{},
+			aliasA:   "u",
+			modelB:   1,
+			aliasB:   "x",
+			a:        `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"`,
+			b:        "NULL AS id, NULL AS name, NULL AS \"meta.id\", NULL AS \"meta.city\"",
+			warnings: 4,
+			err:      prefixererr.ErrNotStruct,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect), mfp.Strict())
+
+			a, b := m.UnionColumns(test.modelA, test.aliasA, test.modelB, test.aliasB)
+
+			if got := a.String(); got != test.a {
+				t.Errorf("first String() = %q, want %q", got, test.a)
+			}
+
+			if got := b.String(); got != test.b {
+				t.Errorf("second String() = %q, want %q", got, test.b)
+			}
+
+			if err := errors.Join(a.Err(), b.Err()); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+
+			if warnings := len(m.Warnings()); warnings != test.warnings {
+				t.Errorf("len(Warnings()) = %d, want %d", warnings, test.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package model_fields_prefixer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// queryErrorContext is the number of bytes of the query around the error offset shown in QueryError
+const queryErrorContext = 20
+
+// QueryValidator checks the query rendered by InQuery, e.g. parses it with a SQL parser. To point to the offending
+// fragment of the query it returns the error made with NewQueryError
+type QueryValidator func(query string) error
+
+// WithQueryValidator makes ValidateQuery check rendered queries with the validator, e.g. prefixersqlparser.Validate.
+// It is meant for tests and development: a broken custom column is reported with the fragment of the query around it
+func WithQueryValidator(validator QueryValidator) Option {
+	return func(mp *ModelFieldsPrefixer) {
+		mp.validator = validator
+	}
+}
+
+// QueryError is the error of the query validation, Fragment is the part of the query around Offset
+// or empty string if the offset is unknown (-1)
+type QueryError struct {
+	Query    string
+	Offset   int
+	Fragment string
+	Err      error
+}
+
+// NewQueryError makes QueryError of the error at the offset of the query, offset -1 means it is unknown
+func NewQueryError(query string, offset int, err error) *QueryError {
+	queryErr := &QueryError{Query: query, Offset: offset, Err: err}
+
+	if offset >= 0 && offset <= len(query) {
+		start := max(offset-queryErrorContext, 0)
+		end := min(offset+queryErrorContext, len(query))
+
+		queryErr.Fragment = query[start:end]
+	}
+
+	return queryErr
+}
+
+func (e *QueryError) Error() string {
+	if e.Fragment == "" {
+		return fmt.Sprintf("invalid query: %v", e.Err)
+	}
+
+	return fmt.Sprintf("invalid query near '%s': %v", e.Fragment, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateQuery renders the query with InQuery and checks it: placeholders which are left unresolved are reported,
+// then the query is checked by the validator set with WithQueryValidator. Errors are *QueryError:
+//
+//	query, err := m.Columns(User{}, "u").CustomColumns("CASE WHEN u.role = 'admin' THEN 1 ELS 0 END").
+//		ValidateQuery("SELECT {columns} FROM users u")
+func (mp *ModelFieldsPrefixer) ValidateQuery(query string) (string, error) {
+	query = mp.InQuery(query)
+
+	if loc := placeholderRegexp.FindStringIndex(query); loc != nil {
+		return query, NewQueryError(query, loc[0], fmt.Errorf("unresolved placeholder %s", query[loc[0]:loc[1]]))
+	}
+
+	if mp.validator == nil {
+		return query, nil
+	}
+
+	err := mp.validator(query)
+	if err == nil {
+		return query, nil
+	}
+
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return query, queryErr
+	}
+
+	return query, NewQueryError(query, -1, err)
+}
@@ -0,0 +1,143 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+// Values returns values of the model's own (not nested) columns in the order they are declared in the struct,
+// so they can be passed straight to db.Exec:
+//
+//	_, err := db.Exec(query, mp.Values(user)...)
+//
+// Read only (`db:"created_at,readonly"`), audit, expression and unexported columns are skipped, so values match
+// WritableColumns. If cols are specified, only those columns are returned in the order of cols. Failures (see
+// ValuesStrict) are reported to the logger and returned by Err in strict mode, the values are nil then, so the query
+// fails on the number of bind parameters instead of running with shifted ones
+func (mp *ModelFieldsPrefixer) Values(model any, cols ...string) []any {
+	values, err := mp.ValuesStrict(model, cols...)
+	if err != nil {
+		mp.fail(err, "model", fmt.Sprintf("%T", model))
+
+		return nil
+	}
+
+	return values
+}
+
+// ValuesStrict works as Values but returns its failures regardless of the strict mode: columns of cols which don't
+// match exported fields of the model, so bind parameters never shift, and failures to encode values of fields
+// with codecs. Errors wrap prefixererr errors
+func (mp *ModelFieldsPrefixer) ValuesStrict(model any, cols ...string) ([]any, error) {
+	t, ok := modelType(model)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", prefixererr.ErrNotStruct, model)
+	}
+
+	v := reflect.ValueOf(model)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("%w: nil %T", prefixererr.ErrNotStruct, model)
+		}
+
+		v = v.Elem()
+	}
+
+	modelInfo := mp.getModelInfo(t)
+	if modelInfo == nil {
+		return nil, fmt.Errorf("%w: %s", prefixererr.ErrNoDBTags, t.String())
+	}
+
+	if len(cols) == 0 {
+		values := make([]any, 0, len(modelInfo.Fields))
+
+		for _, field := range modelInfo.Fields {
+			if !mp.isWritable(field) {
+				continue
+			}
+
+			value, err := mp.fieldValue(field, v)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, value)
+		}
+
+		return values, nil
+	}
+
+	fieldsByTag := make(map[string]*FieldInfo, len(modelInfo.Fields))
+	for _, field := range modelInfo.Fields {
+		if !field.IsStruct {
+			fieldsByTag[field.DBTag] = field
+		}
+	}
+
+	values := make([]any, 0, len(cols))
+
+	for _, col := range cols {
+		field, ok := fieldsByTag[col]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s of model %s", prefixererr.ErrUnknownColumn, col, modelInfo.Name)
+		}
+
+		if !token.IsExported(field.Name) {
+			return nil, fmt.Errorf("%w: %s of model %s", prefixererr.ErrUnexportedField, field.Name, modelInfo.Name)
+		}
+
+		value, err := mp.fieldValue(field, v)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// WritableColumns returns names of the model's own (not nested) columns which can be written by INSERT and UPDATE
+// statements, i.e. all the columns except read only, audit, expression and unexported ones, in the same order as Values returns their values
+func (mp *ModelFieldsPrefixer) WritableColumns(model any) []string {
+	t, ok := modelType(model)
+	if !ok {
+		return nil
+	}
+
+	modelInfo := mp.getModelInfo(t)
+	if modelInfo == nil {
+		return nil
+	}
+
+	columns := make([]string, 0, len(modelInfo.Fields))
+
+	for _, field := range modelInfo.Fields {
+		if !mp.isWritable(field) {
+			continue
+		}
+
+		columns = append(columns, field.DBTag)
+	}
+
+	return columns
+}
+
+// isWritable returns true if the field is the model's own column which is written by INSERT and UPDATE statements,
+// values of unexported fields can't be read by reflection, so they aren't written
+func (mp *ModelFieldsPrefixer) isWritable(field *FieldInfo) bool {
+	return !field.IsStruct && !field.IsReadOnly && field.Expr == "" && !mp.isAudit(field) && token.IsExported(field.Name)
+}
+
+// fieldValue returns value of the field of the model v, encoded with the field's codec if there is one
+func (mp *ModelFieldsPrefixer) fieldValue(field *FieldInfo, v reflect.Value) (any, error) {
+	value, err := mp.encodeValue(field, v.Field(field.Index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode column (%s): %w", field.DBTag, err)
+	}
+
+	return value, nil
+}
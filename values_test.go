@@ -0,0 +1,99 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type Account struct {
+	ID       int64  `db:"id,pk"`
+	Email    string `db:"email"`
+	password string `db:"password"`
+	Created  string `db:"created_at,readonly"`
+}
+
+func TestValues(t *testing.T) {
+	account := Account{ID: 1, Email: "a@b.c", password: "secret", Created: "today"}
+
+	tests := []struct {
+		name    string
+		model   any
+		cols    []string
+		want    []any
+		wantErr error
+	}{
+		{
+			name:  "writable columns",
+			model: account,
+			want:  []any{int64(1), "a@b.c"},
+		},
+		{
+			name:  "pointer",
+			model: &account,
+			want:  []any{int64(1), "a@b.c"},
+		},
+		{
+			name:  "columns",
+			model: account,
+			cols:  []string{"email", "created_at", "id"},
+			want:  []any{"a@b.c", "today", int64(1)},
+		},
+		{
+			name:    "unknown column",
+			model:   account,
+			cols:    []string{"id", "emial", "created_at"},
+			wantErr: prefixererr.ErrUnknownColumn,
+		},
+		{
+			name:    "unexported column",
+			model:   account,
+			cols:    []string{"id", "password"},
+			wantErr: prefixererr.ErrUnexportedField,
+		},
+		{
+			name:    "not struct",
+			model:   1,
+			wantErr: prefixererr.ErrNotStruct,
+		},
+		{
+			name:    "nil pointer",
+			model:   (*Account)(nil),
+			wantErr: prefixererr.ErrNotStruct,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mfp.New().ValuesStrict(tt.model, tt.cols...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValuesStrict() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValuesStrict() = %v, want %v", got, tt.want)
+			}
+
+			mp := mfp.New(mfp.Strict())
+
+			if got = mp.Values(tt.model, tt.cols...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Values() = %v, want %v", got, tt.want)
+			}
+
+			if err = mp.Err(); !errors.Is(err, tt.wantErr) || err != nil && tt.wantErr == nil {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWritableColumns(t *testing.T) {
+	want := []string{"id", "email"}
+
+	if got := mfp.New().WritableColumns(Account{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("WritableColumns() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WarningKind classifies warnings, see Warnings
+type WarningKind string
+
+const (
+	// WarningSkippedField is reported for exported fields which are not columns because they have no db tags
+	WarningSkippedField WarningKind = "skipped_field"
+	// WarningExcludedType is reported for struct fields which are columns instead of relations because their types
+	// are excluded from scanning (see ExcludeScanning) or have no db tags
+	WarningExcludedType WarningKind = "excluded_type"
+	// WarningShortenedAlias is reported for scan aliases exceeding the identifier limit of the dialect which are
+	// shortened, see ShortAliases
+	WarningShortenedAlias WarningKind = "shortened_alias"
+	// WarningAliasCollision is reported for tables of different relations which have the same alias, it is returned
+	// by Err as prefixererr.ErrAliasCollision in strict mode
+	WarningAliasCollision WarningKind = "alias_collision"
+	// WarningGeneral is reported for other failures, e.g. unknown columns passed to OrderBy which are skipped
+	WarningGeneral WarningKind = "general"
+)
+
+// Warning is something the prefixer skipped or changed while collecting models or rendering columns
+type Warning struct {
+	Kind    WarningKind
+	Message string
+	// Attrs are key-value pairs describing the warning, e.g. "model", "User", "field", "Email"
+	Attrs []any
+}
+
+// String returns the message followed by the attributes, e.g. 'field has no db tag model=User field=Email'
+func (w Warning) String() string {
+	var sb strings.Builder
+	sb.WriteString(w.Message)
+
+	for i := 0; i+1 < len(w.Attrs); i += 2 {
+		fmt.Fprintf(&sb, " %v=%v", w.Attrs[i], w.Attrs[i+1])
+	}
+
+	return sb.String()
+}
+
+// Warnings returns the warnings of the last Columns call and the calls following it, including the ones reported
+// when the model was collected to the cache, so tests can assert the prefixer produced exactly what was intended:
+//
+//	m.Columns(User{}, "u", mfp.M{N: "UserMeta", A: "um"})
+//	if warnings := m.Warnings(); len(warnings) > 0 { t.Error(warnings) }
+func (mp *ModelFieldsPrefixer) Warnings() []Warning {
+	warnings := make([]Warning, len(mp.warnings))
+	copy(warnings, mp.warnings)
+
+	return warnings
+}
+
+// warnKind reports the warning to the logger and keeps it to be returned by Warnings
+func (mp *ModelFieldsPrefixer) warnKind(kind WarningKind, msg string, args ...any) {
+	if logger := mp.log(); logger != nil {
+		logger.Warn(msg, args...)
+	}
+
+	mp.warnings = append(mp.warnings, Warning{Kind: kind, Message: msg, Attrs: args})
+}
+
+// addModelWarnings adds the warnings reported when the model was collected to the cache
+func (mp *ModelFieldsPrefixer) addModelWarnings(t reflect.Type) {
+	mp.warnings = append(mp.warnings, mp.cache.getModelWarnings(t)...)
+}
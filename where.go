@@ -0,0 +1,118 @@
+package model_fields_prefixer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+const wherePlaceholder = "{where}"
+
+// Where builds WHERE clause of the filter struct which replaces {where} placeholder. Every tagged field of the filter
+// is compared with the column named in its tag: columns without alias ('status') belong to the root model, others
+// ('um.city') are the built columns of join models. Nil pointers and zero values of fields with 'omitempty' option
+// are skipped, slices are compared with IN. The values are bound as parameters (see Args):
+//
+//	type UserFilter struct {
+//		Status *string `db:"status"`
+//		City   string  `db:"um.city,omitempty"`
+//		IDs    []int64 `db:"id,omitempty"`
+//	}
+//
+//	m.Columns(User{}, "u", mfp.M{N: "UserMeta", A: "um"}).Where(UserFilter{City: "Paris"}).
+//		InQuery("SELECT {columns} FROM users u JOIN user_meta um ON ... {where}") // ... WHERE um.city = $1
+//
+// Like in OrderBy only the columns of the models are accepted, unknown ones are skipped and reported as failures
+// (see Strict), so a mistyped tag never widens the filter silently. Conditions of subsequent calls are joined with AND
+func (mp *ModelFieldsPrefixer) Where(filter any) *ModelFieldsPrefixer {
+	v := reflect.Indirect(reflect.ValueOf(filter))
+	if !v.IsValid() {
+		return mp
+	}
+
+	if v.Kind() != reflect.Struct {
+		mp.fail(fmt.Errorf("%w: filter %s", prefixererr.ErrNotStruct, v.Type().String()))
+
+		return mp
+	}
+
+	// filters accept the same columns as ORDER BY
+	columns := mp.sortableColumns()
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		dbTag, dbTagOptions := parseDBTag(field.Tag.Get(mp.tagName))
+		if dbTag == "" || dbTag == "-" || !field.IsExported() {
+			continue
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+
+			value = value.Elem()
+		}
+
+		if hasTagOption(dbTagOptions, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		column := dbTag
+		if !strings.Contains(column, ".") && mp.rootAlias != "" {
+			column = mp.rootAlias + "." + column
+		}
+
+		rendered, ok := columns[column]
+		if !ok {
+			mp.fail(fmt.Errorf("%w: %s of filter %s", prefixererr.ErrUnknownColumn, column, v.Type().Name()), "column", column,
+				"field", field.Name)
+
+			continue
+		}
+
+		mp.where = append(mp.where, mp.condition(rendered, value))
+	}
+
+	return mp
+}
+
+// condition renders comparison of the column with the value binding the value as a parameter
+func (mp *ModelFieldsPrefixer) condition(column string, value reflect.Value) string {
+	isSlice := value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8
+	if !isSlice {
+		mp.args = append(mp.args, value.Interface())
+
+		return column + " = " + mp.dialect.Placeholder(len(mp.args))
+	}
+
+	if value.Len() == 0 {
+		// nothing is equal to a value of the empty list
+		return "1 = 0"
+	}
+
+	placeholders := make([]string, 0, value.Len())
+
+	for i := 0; i < value.Len(); i++ {
+		mp.args = append(mp.args, value.Index(i).Interface())
+		placeholders = append(placeholders, mp.dialect.Placeholder(len(mp.args)))
+	}
+
+	return column + " IN (" + strings.Join(placeholders, ", ") + ")"
+}
+
+// whereClause renders the conditions collected by Where followed by tenant and soft delete conditions, empty string
+// if there are no conditions
+func (mp *ModelFieldsPrefixer) whereClause() string {
+	conditions := append(mp.where[:len(mp.where):len(mp.where)], mp.tenantConditions()...)
+	conditions = append(conditions, mp.softDeleteConditions()...)
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
@@ -0,0 +1,107 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+type UserSearch struct {
+	IDs     []int64 `db:"id,omitempty"`
+	Name    *string `db:"name"`
+	City    string  `db:"um.city,omitempty"`
+	Email   string  `db:"email,omitempty"`
+	Skipped string  `db:"-"`
+	hidden  string  `db:"name"`
+}
+
+func TestWhere(t *testing.T) {
+	name := "Ann"
+
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		filters []any
+		where   string
+		args    []any
+		err     error
+	}{
+		{
+			name:    "empty filter",
+			filters: []any{UserSearch{}},
+			where:   "",
+		},
+		{
+			name:    "nil filter",
+			filters: []any{nil, (*UserSearch)(nil)},
+			where:   "",
+		},
+		{
+			name:    "pointer and join model columns",
+			filters: []any{&UserSearch{Name: &name, City: "Paris"}},
+			where:   "WHERE u.name = $1 AND um.city = $2",
+			args:    []any{"Ann", "Paris"},
+		},
+		{
+			name:    "slice",
+			filters: []any{UserSearch{IDs: []int64{1, 2}}},
+			where:   "WHERE u.id IN ($1, $2)",
+			args:    []any{int64(1), int64(2)},
+		},
+		{
+			name:    "subsequent calls",
+			dialect: mfp.DialectMySQL,
+			filters: []any{UserSearch{City: "Paris"}, UserFilter{Name: "Ann"}},
+			where:   "WHERE um.city = ? AND u.name = ?",
+			args:    []any{"Paris", "Ann"},
+		},
+		{
+			name:    "unexported and skipped fields",
+			filters: []any{UserSearch{Skipped: "x", hidden: "x"}},
+			where:   "",
+		},
+		{
+			name:    "unknown column",
+			filters: []any{UserSearch{City: "Paris", Email: "ann@example.com"}},
+			where:   "WHERE um.city = $1",
+			args:    []any{"Paris"},
+			err:     prefixererr.ErrUnknownColumn,
+		},
+		{
+			name:    "not struct",
+			filters: []any{map[string]any{"name": "Ann"}},
+			where:   "",
+			err:     prefixererr.ErrNotStruct,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect), mfp.Strict()).Columns(User{}, "u")
+
+			for _, filter := range test.filters {
+				m.Where(filter)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Fatalf("Err() = %v, want %v", err, test.err)
+			}
+
+			if query, want := m.InQuery("SELECT {columns} FROM users u {where}"), "SELECT "+m.String()+" FROM users u "+test.where; query != want {
+				t.Errorf("InQuery() = %q, want %q", query, want)
+			}
+
+			if args := m.Args(); !reflect.DeepEqual(args, test.args) && (len(args) > 0 || len(test.args) > 0) {
+				t.Errorf("Args() = %v, want %v", args, test.args)
+			}
+		})
+	}
+}
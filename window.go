@@ -0,0 +1,79 @@
+package model_fields_prefixer
+
+import (
+	"strings"
+)
+
+// Window is the window function column appended by WindowColumn, e.g. the number of the order of its user for
+// top-N-per-group queries
+type Window struct {
+	// Func is the window function, e.g. 'ROW_NUMBER()' or 'RANK()'
+	Func string
+	// PartitionBy is a comma separated list of columns ('u.id', 'id'), primary key columns of the root model if empty
+	PartitionBy string
+	// OrderBy is a comma separated list of columns with optional directions as OrderBy argument, e.g. 'o.created_at DESC'
+	OrderBy string
+	// Alias is the name of the column in the result set, e.g. 'o.rn'
+	Alias string
+}
+
+// WindowColumn appends the window function over the columns of the model passed to the last Columns call, e.g.
+// 'ROW_NUMBER() OVER (PARTITION BY u.id ORDER BY o.created_at DESC) AS "o.rn"'. Columns of PartitionBy and OrderBy are
+// validated as in OrderBy, unknown ones are skipped and reported as failures (see Strict)
+func (mp *ModelFieldsPrefixer) WindowColumn(window Window) *ModelFieldsPrefixer {
+	if window.Func == "" {
+		mp.warn("window function is empty", "alias", window.Alias)
+
+		return mp
+	}
+
+	sortableColumns := mp.sortableColumns()
+
+	partition := mp.rootKeys()
+	if window.PartitionBy != "" {
+		partition, _ = mp.orderItems("partition by", window.PartitionBy, sortableColumns, false)
+	}
+
+	order, _ := mp.orderItems("order by", window.OrderBy, sortableColumns, true)
+
+	clauses := make([]string, 0, 2)
+
+	if len(partition) > 0 {
+		clauses = append(clauses, "PARTITION BY "+strings.Join(partition, ", "))
+	}
+
+	if len(order) > 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(order, ", "))
+	}
+
+	start := mp.bytesBuffer.Len()
+	mp.rendered = nil
+
+	mp.bytesBuffer.WriteString(window.Func + " OVER (" + strings.Join(clauses, " ") + ")")
+
+	if window.Alias != "" {
+		mp.writeAlias(window.Alias)
+	}
+
+	mp.columns = append(mp.columns, columnInfo{start: start, end: mp.bytesBuffer.Len(), scanAlias: window.Alias, aggregate: true})
+	mp.bytesBuffer.WriteString(", ")
+
+	return mp
+}
+
+// rootKeys returns primary key columns of the root model prefixed with its alias
+func (mp *ModelFieldsPrefixer) rootKeys() []string {
+	keys := make([]string, 0)
+
+	if mp.rootModel == nil {
+		return keys
+	}
+
+	for _, field := range mp.rootModel.Fields {
+		if field.IsPK {
+			keys = append(keys, mp.quoteTableAlias(mp.rootAlias)+"."+mp.quoteColumn(field.DBTag))
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,83 @@
+package model_fields_prefixer_test
+
+import (
+	"errors"
+	"testing"
+
+	mfp "github.com/ivnku/model-fields-prefixer"
+	"github.com/ivnku/model-fields-prefixer/prefixererr"
+)
+
+func TestWindowColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect mfp.Dialect
+		window  mfp.Window
+		want    string
+		err     error
+	}{
+		{
+			name:   "partition by pk",
+			window: mfp.Window{Func: "ROW_NUMBER()", OrderBy: "um.city DESC", Alias: "u.rn"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", ROW_NUMBER() OVER (PARTITION BY u.id ORDER BY um.city DESC) AS "u.rn"`,
+		},
+		{
+			name:   "partition by columns",
+			window: mfp.Window{Func: "RANK()", PartitionBy: "um.city, name", OrderBy: "-id", Alias: "rank"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", RANK() OVER (PARTITION BY um.city, u.name ORDER BY u.id DESC) AS "rank"`,
+		},
+		{
+			name:   "without alias and order",
+			window: mfp.Window{Func: "COUNT(*)"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", COUNT(*) OVER (PARTITION BY u.id)`,
+		},
+		{
+			name:    "mssql",
+			dialect: mfp.DialectMSSQL,
+			window:  mfp.Window{Func: "ROW_NUMBER()", OrderBy: "name", Alias: "u.rn"},
+			want:    "u.id, u.name, um.id AS [meta.id], um.city AS [meta.city], ROW_NUMBER() OVER (PARTITION BY u.id ORDER BY u.name) AS [u.rn]",
+		},
+		{
+			name:   "empty function",
+			window: mfp.Window{OrderBy: "name", Alias: "u.rn"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city"`,
+		},
+		{
+			name:   "unknown partition column",
+			window: mfp.Window{Func: "ROW_NUMBER()", PartitionBy: "email", OrderBy: "name", Alias: "u.rn"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", ROW_NUMBER() OVER (ORDER BY u.name) AS "u.rn"`,
+			err:    prefixererr.ErrUnknownColumn,
+		},
+		{
+			name:   "direction of partition column",
+			window: mfp.Window{Func: "ROW_NUMBER()", PartitionBy: "name DESC", Alias: "u.rn"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", ROW_NUMBER() OVER () AS "u.rn"`,
+			err:    prefixererr.ErrInvalidDirection,
+		},
+		{
+			name:   "injection in order",
+			window: mfp.Window{Func: "ROW_NUMBER()", OrderBy: "name) AS x FROM users --", Alias: "u.rn"},
+			want:   `u.id, u.name, um.id AS "meta.id", um.city AS "meta.city", ROW_NUMBER() OVER (PARTITION BY u.id) AS "u.rn"`,
+			err:    prefixererr.ErrUnknownColumn,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = mfp.DialectPostgres
+			}
+
+			m := mfp.New(mfp.WithDialect(dialect), mfp.Strict())
+
+			if got := m.Columns(User{}, "u").WindowColumn(test.window).String(); got != test.want {
+				t.Errorf("WindowColumn() = %q, want %q", got, test.want)
+			}
+
+			if err := m.Err(); !errors.Is(err, test.err) || err != nil && test.err == nil {
+				t.Errorf("Err() = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package model_fields_prefixer
+
+import (
+	"io"
+)
+
+var _ io.WriterTo = (*ModelFieldsPrefixer)(nil)
+
+// WriteTo writes the built columns list to w without copying it to an intermediate string
+func (mp *ModelFieldsPrefixer) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(mp.Bytes())
+
+	return int64(n), err
+}
+
+// Bytes returns the built columns list without copying it. The slice must not be modified and is valid only until
+// the next call changing the prefixer
+func (mp *ModelFieldsPrefixer) Bytes() []byte {
+	if mp.bytesBuffer == nil {
+		return nil
+	}
+
+	return mp.bytesBuffer.Bytes()[:mp.ByteLen()]
+}
+
+// AppendTo appends the built columns list to dst and returns the extended slice, so the columns are copied once
+// straight into the caller's buffer
+func (mp *ModelFieldsPrefixer) AppendTo(dst []byte) []byte {
+	return append(dst, mp.Bytes()...)
+}
+
+// ColumnsTo builds columns of the model like Columns does and writes them to w, e.g. straight to the query buffer
+func (mp *ModelFieldsPrefixer) ColumnsTo(w io.Writer, model any, dbTableAlias string, joinModels ...M) (int64, error) {
+	args := make([]any, 0, len(joinModels)+2)
+	args = append(args, model, dbTableAlias)
+
+	for _, joinModel := range joinModels {
+		args = append(args, joinModel)
+	}
+
+	return mp.Columns(args...).WriteTo(w)
+}